@@ -0,0 +1,323 @@
+// Code generated by openapigen from openapi.yaml. DO NOT EDIT.
+
+package openapi
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// Operations lists the operations described by the spec, in document order
+var Operations = []Operation{
+	{ID: "listSandboxes", Method: "GET", Path: "/v1/sandboxes", QueryParams: []string{"project_id", "status", "owner_user_id", "search", "label_selector", "created_after", "created_before", "timeout_before", "template_id", "template_name", "region", "min_cpu_count", "min_memory_mb", "include_terminated", "sort_by", "sort_order", "limit", "offset"}},
+	{ID: "createSandbox", Method: "POST", Path: "/v1/sandboxes"},
+	{ID: "getSandbox", Method: "GET", Path: "/v1/sandboxes/{sandbox_id}"},
+	{ID: "deleteSandbox", Method: "DELETE", Path: "/v1/sandboxes/{sandbox_id}", QueryParams: []string{"force"}},
+	{ID: "updateSandbox", Method: "PATCH", Path: "/v1/sandboxes/{sandbox_id}"},
+	{ID: "getSandboxStatus", Method: "GET", Path: "/v1/sandboxes/{sandbox_id}/status"},
+	{ID: "terminateSandbox", Method: "POST", Path: "/v1/sandboxes/{sandbox_id}/terminate", QueryParams: []string{"force"}},
+	{ID: "pauseSandbox", Method: "POST", Path: "/v1/sandboxes/{sandbox_id}/pause"},
+	{ID: "resumeSandbox", Method: "POST", Path: "/v1/sandboxes/{sandbox_id}/resume"},
+	{ID: "connectSandbox", Method: "POST", Path: "/v1/sandboxes/{sandbox_id}/connect"},
+	{ID: "setSandboxTimeout", Method: "POST", Path: "/v1/sandboxes/{sandbox_id}/timeout"},
+	{ID: "addSandboxLabels", Method: "POST", Path: "/v1/sandboxes/{sandbox_id}/labels"},
+	{ID: "removeSandboxLabels", Method: "DELETE", Path: "/v1/sandboxes/{sandbox_id}/labels", QueryParams: []string{"keys"}},
+	{ID: "getSandboxMetrics", Method: "GET", Path: "/v1/sandboxes/{sandbox_id}/metrics", QueryParams: []string{"start", "end", "step"}},
+}
+
+// ListSandboxesParams holds the query and header parameters of ListSandboxes
+type ListSandboxesParams struct {
+	ProjectID         *string
+	Status            []string // Repeat to match any of several statuses
+	OwnerUserID       *string
+	Search            *string
+	LabelSelector     *string // Label selector on metadata, e.g. team=ml,env in (ci,dev),!ephemeral
+	CreatedAfter      *time.Time
+	CreatedBefore     *time.Time
+	TimeoutBefore     *time.Time // Sandboxes whose timeout_at is earlier
+	TemplateID        *string
+	TemplateName      *string
+	Region            *string
+	MinCPUCount       *int
+	MinMemoryMB       *int
+	IncludeTerminated *bool // Also return terminated sandboxes
+	SortBy            *string
+	SortOrder         *string
+	Limit             *int
+	Offset            *int
+}
+
+// ListSandboxes calls GET /v1/sandboxes: list sandboxes
+func (c *Client) ListSandboxes(ctx context.Context, params *ListSandboxesParams) (*SandboxListResponse, error) {
+	path := "/v1/sandboxes"
+	query := make(url.Values)
+	if params != nil {
+		if params.ProjectID != nil {
+			query.Set("project_id", *params.ProjectID)
+		}
+		for _, v := range params.Status {
+			query.Add("status", v)
+		}
+		if params.OwnerUserID != nil {
+			query.Set("owner_user_id", *params.OwnerUserID)
+		}
+		if params.Search != nil {
+			query.Set("search", *params.Search)
+		}
+		if params.LabelSelector != nil {
+			query.Set("label_selector", *params.LabelSelector)
+		}
+		if params.CreatedAfter != nil {
+			query.Set("created_after", params.CreatedAfter.Format(time.RFC3339))
+		}
+		if params.CreatedBefore != nil {
+			query.Set("created_before", params.CreatedBefore.Format(time.RFC3339))
+		}
+		if params.TimeoutBefore != nil {
+			query.Set("timeout_before", params.TimeoutBefore.Format(time.RFC3339))
+		}
+		if params.TemplateID != nil {
+			query.Set("template_id", *params.TemplateID)
+		}
+		if params.TemplateName != nil {
+			query.Set("template_name", *params.TemplateName)
+		}
+		if params.Region != nil {
+			query.Set("region", *params.Region)
+		}
+		if params.MinCPUCount != nil {
+			query.Set("min_cpu_count", strconv.Itoa(*params.MinCPUCount))
+		}
+		if params.MinMemoryMB != nil {
+			query.Set("min_memory_mb", strconv.Itoa(*params.MinMemoryMB))
+		}
+		if params.IncludeTerminated != nil {
+			query.Set("include_terminated", strconv.FormatBool(*params.IncludeTerminated))
+		}
+		if params.SortBy != nil {
+			query.Set("sort_by", *params.SortBy)
+		}
+		if params.SortOrder != nil {
+			query.Set("sort_order", *params.SortOrder)
+		}
+		if params.Limit != nil {
+			query.Set("limit", strconv.Itoa(*params.Limit))
+		}
+		if params.Offset != nil {
+			query.Set("offset", strconv.Itoa(*params.Offset))
+		}
+	}
+	var result SandboxListResponse
+	if err := c.do(ctx, "GET", path, nil, query, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// CreateSandbox calls POST /v1/sandboxes: create a sandbox
+func (c *Client) CreateSandbox(ctx context.Context, body CreateSandboxRequest) (*Sandbox, error) {
+	path := "/v1/sandboxes"
+	var result Sandbox
+	if err := c.do(ctx, "POST", path, body, nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetSandbox calls GET /v1/sandboxes/{sandbox_id}: get sandbox details
+func (c *Client) GetSandbox(ctx context.Context, sandboxID string) (*Sandbox, error) {
+	path := fmt.Sprintf("/v1/sandboxes/%s", sandboxID)
+	var result Sandbox
+	if err := c.do(ctx, "GET", path, nil, nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// DeleteSandboxParams holds the query and header parameters of DeleteSandbox
+type DeleteSandboxParams struct {
+	Force *bool
+}
+
+// DeleteSandbox calls DELETE /v1/sandboxes/{sandbox_id}: delete a sandbox
+func (c *Client) DeleteSandbox(ctx context.Context, sandboxID string, params *DeleteSandboxParams) (*DeletionResponse, error) {
+	path := fmt.Sprintf("/v1/sandboxes/%s", sandboxID)
+	query := make(url.Values)
+	if params != nil {
+		if params.Force != nil {
+			query.Set("force", strconv.FormatBool(*params.Force))
+		}
+	}
+	var result DeletionResponse
+	if err := c.do(ctx, "DELETE", path, nil, query, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// UpdateSandboxParams holds the query and header parameters of UpdateSandbox
+type UpdateSandboxParams struct {
+	IfMatch *string // ETag precondition
+}
+
+// UpdateSandbox calls PATCH /v1/sandboxes/{sandbox_id}: partially update a sandbox
+func (c *Client) UpdateSandbox(ctx context.Context, sandboxID string, body UpdateSandboxRequest, params *UpdateSandboxParams) (*Sandbox, error) {
+	path := fmt.Sprintf("/v1/sandboxes/%s", sandboxID)
+	headers := make(map[string]string)
+	if params != nil {
+		if params.IfMatch != nil {
+			headers["If-Match"] = *params.IfMatch
+		}
+	}
+	var result Sandbox
+	if err := c.do(ctx, "PATCH", path, body, nil, headers, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetSandboxStatus calls GET /v1/sandboxes/{sandbox_id}/status: get lightweight sandbox status
+func (c *Client) GetSandboxStatus(ctx context.Context, sandboxID string) (*SandboxStatus, error) {
+	path := fmt.Sprintf("/v1/sandboxes/%s/status", sandboxID)
+	var result SandboxStatus
+	if err := c.do(ctx, "GET", path, nil, nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// TerminateSandboxParams holds the query and header parameters of TerminateSandbox
+type TerminateSandboxParams struct {
+	Force *bool
+}
+
+// TerminateSandbox calls POST /v1/sandboxes/{sandbox_id}/terminate: terminate a sandbox
+func (c *Client) TerminateSandbox(ctx context.Context, sandboxID string, params *TerminateSandboxParams) (*TerminationResponse, error) {
+	path := fmt.Sprintf("/v1/sandboxes/%s/terminate", sandboxID)
+	query := make(url.Values)
+	if params != nil {
+		if params.Force != nil {
+			query.Set("force", strconv.FormatBool(*params.Force))
+		}
+	}
+	var result TerminationResponse
+	if err := c.do(ctx, "POST", path, nil, query, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// PauseSandbox calls POST /v1/sandboxes/{sandbox_id}/pause: pause a sandbox
+func (c *Client) PauseSandbox(ctx context.Context, sandboxID string, body *PauseSandboxRequest) (*Sandbox, error) {
+	path := fmt.Sprintf("/v1/sandboxes/%s/pause", sandboxID)
+	var reqBody interface{}
+	if body != nil {
+		reqBody = body
+	}
+	var result Sandbox
+	if err := c.do(ctx, "POST", path, reqBody, nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ResumeSandbox calls POST /v1/sandboxes/{sandbox_id}/resume: resume a sandbox
+func (c *Client) ResumeSandbox(ctx context.Context, sandboxID string, body *ResumeSandboxRequest) (*Sandbox, error) {
+	path := fmt.Sprintf("/v1/sandboxes/%s/resume", sandboxID)
+	var reqBody interface{}
+	if body != nil {
+		reqBody = body
+	}
+	var result Sandbox
+	if err := c.do(ctx, "POST", path, reqBody, nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ConnectSandbox calls POST /v1/sandboxes/{sandbox_id}/connect: connect to a sandbox, resuming it if paused
+func (c *Client) ConnectSandbox(ctx context.Context, sandboxID string, body *ConnectSandboxRequest) (*Sandbox, error) {
+	path := fmt.Sprintf("/v1/sandboxes/%s/connect", sandboxID)
+	var reqBody interface{}
+	if body != nil {
+		reqBody = body
+	}
+	var result Sandbox
+	if err := c.do(ctx, "POST", path, reqBody, nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// SetSandboxTimeout calls POST /v1/sandboxes/{sandbox_id}/timeout: set the sandbox timeout
+func (c *Client) SetSandboxTimeout(ctx context.Context, sandboxID string, body SandboxTimeoutRequest) (*Sandbox, error) {
+	path := fmt.Sprintf("/v1/sandboxes/%s/timeout", sandboxID)
+	var result Sandbox
+	if err := c.do(ctx, "POST", path, body, nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// AddSandboxLabels calls POST /v1/sandboxes/{sandbox_id}/labels: add or overwrite metadata labels
+func (c *Client) AddSandboxLabels(ctx context.Context, sandboxID string, body AddLabelsRequest) (*Sandbox, error) {
+	path := fmt.Sprintf("/v1/sandboxes/%s/labels", sandboxID)
+	var result Sandbox
+	if err := c.do(ctx, "POST", path, body, nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// RemoveSandboxLabelsParams holds the query and header parameters of RemoveSandboxLabels
+type RemoveSandboxLabelsParams struct {
+	Keys *string // Comma-separated label keys
+}
+
+// RemoveSandboxLabels calls DELETE /v1/sandboxes/{sandbox_id}/labels: remove metadata labels
+func (c *Client) RemoveSandboxLabels(ctx context.Context, sandboxID string, params *RemoveSandboxLabelsParams) (*Sandbox, error) {
+	path := fmt.Sprintf("/v1/sandboxes/%s/labels", sandboxID)
+	query := make(url.Values)
+	if params != nil {
+		if params.Keys != nil {
+			query.Set("keys", *params.Keys)
+		}
+	}
+	var result Sandbox
+	if err := c.do(ctx, "DELETE", path, nil, query, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetSandboxMetricsParams holds the query and header parameters of GetSandboxMetrics
+type GetSandboxMetricsParams struct {
+	Start *time.Time // RFC3339 or Unix timestamp in seconds
+	End   *time.Time // RFC3339 or Unix timestamp in seconds
+	Step  *int       // Step in seconds
+}
+
+// GetSandboxMetrics calls GET /v1/sandboxes/{sandbox_id}/metrics: get sandbox metrics
+func (c *Client) GetSandboxMetrics(ctx context.Context, sandboxID string, params *GetSandboxMetricsParams) (*SandboxMetricsResponse, error) {
+	path := fmt.Sprintf("/v1/sandboxes/%s/metrics", sandboxID)
+	query := make(url.Values)
+	if params != nil {
+		if params.Start != nil {
+			query.Set("start", params.Start.Format(time.RFC3339))
+		}
+		if params.End != nil {
+			query.Set("end", params.End.Format(time.RFC3339))
+		}
+		if params.Step != nil {
+			query.Set("step", strconv.Itoa(*params.Step))
+		}
+	}
+	var result SandboxMetricsResponse
+	if err := c.do(ctx, "GET", path, nil, query, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
@@ -0,0 +1,225 @@
+// Code generated by openapigen from openapi.yaml. DO NOT EDIT.
+
+package openapi
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// StandardResponse is generated from #/components/schemas/StandardResponse
+type StandardResponse struct {
+	Success   bool            `json:"success"`
+	Data      json.RawMessage `json:"data,omitempty"` // Response payload
+	Message   *string         `json:"message,omitempty"`
+	Error     *string         `json:"error,omitempty"`
+	Timestamp *string         `json:"timestamp,omitempty"`
+}
+
+// CreateSandboxRequest is generated from #/components/schemas/CreateSandboxRequest
+type CreateSandboxRequest struct {
+	Name                string               `json:"name"`
+	Description         string               `json:"description"`
+	Template            string               `json:"template"` // Template name or ID
+	ProjectID           *string              `json:"project_id,omitempty"`
+	CPUCount            int                  `json:"cpu_count"`
+	MemoryMB            int                  `json:"memory_mb"`
+	StorageGB           int                  `json:"storage_gb"`
+	Metadata            map[string]string    `json:"metadata,omitempty"`
+	Timeout             *int                 `json:"timeout,omitempty"` // Timeout in seconds
+	AutoPause           *bool                `json:"auto_pause,omitempty"`
+	EnvVars             map[string]string    `json:"env_vars,omitempty"`
+	Secure              *bool                `json:"secure,omitempty"`
+	AllowInternetAccess *bool                `json:"allow_internet_access,omitempty"`
+	ObjectStorage       *ObjectStorageConfig `json:"object_storage,omitempty"`
+	CustomPorts         []PortConfig         `json:"custom_ports,omitempty"`
+	NetProxyCountry     *string              `json:"net_proxy_country,omitempty"` // ISO 3166-1 alpha-2 country code
+	Locality            *LocalityRequest     `json:"locality,omitempty"`
+}
+
+// ObjectStorageConfig is generated from #/components/schemas/ObjectStorageConfig
+type ObjectStorageConfig struct {
+	URI        string  `json:"uri"` // s3://bucket/object-path
+	MountPoint string  `json:"mount_point"`
+	AccessKey  string  `json:"access_key"`
+	SecretKey  string  `json:"secret_key"`
+	Endpoint   *string `json:"endpoint,omitempty"`
+	Region     *string `json:"region,omitempty"`
+}
+
+// LocalityRequest is generated from #/components/schemas/LocalityRequest
+type LocalityRequest struct {
+	AutoDetect bool   `json:"auto_detect"`
+	Region     string `json:"region"`
+	Force      bool   `json:"force"`
+}
+
+// UpdateSandboxRequest is generated from #/components/schemas/UpdateSandboxRequest
+type UpdateSandboxRequest struct {
+	Name                *string           `json:"name,omitempty"`
+	Description         *string           `json:"description,omitempty"`
+	Timeout             *int              `json:"timeout,omitempty"` // New timeout in seconds
+	Metadata            map[string]string `json:"metadata,omitempty"`
+	EnvVars             map[string]string `json:"env_vars,omitempty"`
+	AutoPause           *bool             `json:"auto_pause,omitempty"`
+	AllowInternetAccess *bool             `json:"allow_internet_access,omitempty"`
+	ExpectedUpdatedAt   *time.Time        `json:"expected_updated_at,omitempty"` // Fail with 409 if the sandbox's updated_at differs
+	UpdateMask          []string          `json:"update_mask,omitempty"`         // Fields to change
+}
+
+// AddLabelsRequest is generated from #/components/schemas/AddLabelsRequest
+type AddLabelsRequest struct {
+	Labels map[string]string `json:"labels"`
+}
+
+// SandboxTimeoutRequest is generated from #/components/schemas/SandboxTimeoutRequest
+type SandboxTimeoutRequest struct {
+	Timeout int `json:"timeout"` // New timeout in seconds
+}
+
+// ConnectSandboxRequest is generated from #/components/schemas/ConnectSandboxRequest
+type ConnectSandboxRequest struct {
+	Timeout *int `json:"timeout,omitempty"` // Optional new timeout in seconds
+}
+
+// PauseSandboxRequest is generated from #/components/schemas/PauseSandboxRequest
+type PauseSandboxRequest struct {
+}
+
+// ResumeSandboxRequest is generated from #/components/schemas/ResumeSandboxRequest
+type ResumeSandboxRequest struct {
+}
+
+// Sandbox is generated from #/components/schemas/Sandbox
+type Sandbox struct {
+	SandboxID                 string                 `json:"sandbox_id"`
+	Name                      string                 `json:"name"`
+	Description               *string                `json:"description,omitempty"`
+	TemplateID                string                 `json:"template_id"`
+	TemplateName              *string                `json:"template_name,omitempty"`
+	TemplateExists            *bool                  `json:"template_exists,omitempty"`
+	OwnerUserID               string                 `json:"owner_user_id"`
+	ProjectID                 string                 `json:"project_id"`
+	ProjectName               *string                `json:"project_name,omitempty"`
+	CPUCount                  int                    `json:"cpu_count"`
+	MemoryMB                  int                    `json:"memory_mb"`
+	StorageGB                 int                    `json:"storage_gb"`
+	Timeout                   int                    `json:"timeout"`
+	AutoPause                 bool                   `json:"auto_pause"`
+	Secure                    bool                   `json:"secure"`
+	AllowInternetAccess       bool                   `json:"allow_internet_access"`
+	Metadata                  map[string]string      `json:"metadata,omitempty"`
+	EnvVars                   map[string]string      `json:"env_vars,omitempty"`
+	ObjectStorage             map[string]string      `json:"object_storage,omitempty"`
+	Ports                     []PortConfig           `json:"ports,omitempty"`
+	TemplatePorts             []PortConfig           `json:"template_ports,omitempty"`
+	CustomPorts               []PortConfig           `json:"custom_ports,omitempty"`
+	Status                    string                 `json:"status"`
+	Substatus                 *string                `json:"substatus,omitempty"`
+	Reason                    *string                `json:"reason,omitempty"`
+	SandboxDomain             *string                `json:"sandbox_domain,omitempty"`
+	SandboxDomainInternal     *string                `json:"sandbox_domain_internal,omitempty"`
+	WebTerminalAvailable      bool                   `json:"web_terminal_available"`
+	WebFilesAvailable         bool                   `json:"web_files_available"`
+	EnvdAccessToken           *string                `json:"envd_access_token,omitempty"`
+	NetworkProxy              map[string]interface{} `json:"network_proxy,omitempty"`
+	CreatedAt                 time.Time              `json:"created_at"`
+	UpdatedAt                 time.Time              `json:"updated_at"`
+	StartedAt                 *time.Time             `json:"started_at,omitempty"`
+	StoppedAt                 *time.Time             `json:"stopped_at,omitempty"`
+	EndedAt                   *time.Time             `json:"ended_at,omitempty"`
+	TimeoutAt                 *time.Time             `json:"timeout_at,omitempty"`
+	PausedAt                  *time.Time             `json:"paused_at,omitempty"`
+	PausingAt                 *time.Time             `json:"pausing_at,omitempty"`
+	ResumedAt                 *time.Time             `json:"resumed_at,omitempty"`
+	TotalPausedSeconds        int                    `json:"total_paused_seconds"`
+	TotalRunningSeconds       int                    `json:"total_running_seconds"`
+	ActualTotalPausedSeconds  *int                   `json:"actual_total_paused_seconds,omitempty"`
+	ActualTotalRunningSeconds *int64                 `json:"actual_total_running_seconds,omitempty"`
+	Uptime                    *int64                 `json:"uptime,omitempty"`
+	PersistenceDays           *int                   `json:"persistence_days,omitempty"`
+	PersistenceExpiresAt      *time.Time             `json:"persistence_expires_at,omitempty"`
+	PersistenceDaysRemaining  *int                   `json:"persistence_days_remaining,omitempty"`
+	Owner                     *Owner                 `json:"owner,omitempty"`
+	AccountOwner              *AccountOwner          `json:"account_owner,omitempty"`
+	Resources                 *Resources             `json:"resources,omitempty"`
+}
+
+// Owner is generated from #/components/schemas/Owner
+type Owner struct {
+	UserID      string  `json:"user_id"`
+	Username    string  `json:"username"`
+	DisplayName *string `json:"display_name,omitempty"`
+	Email       string  `json:"email"`
+}
+
+// AccountOwner is generated from #/components/schemas/AccountOwner
+type AccountOwner struct {
+	AccountID          string  `json:"account_id"`
+	AccountDisplayName *string `json:"account_display_name,omitempty"`
+	AccountEmail       *string `json:"account_email,omitempty"`
+}
+
+// Resources is generated from #/components/schemas/Resources
+type Resources struct {
+	CPU       int `json:"cpu"`
+	Memory    int `json:"memory"`
+	Storage   int `json:"storage"`
+	Bandwidth int `json:"bandwidth"`
+}
+
+// PortConfig is generated from #/components/schemas/PortConfig
+type PortConfig struct {
+	Port        int32   `json:"port"`
+	ServicePort *int32  `json:"service_port,omitempty"`
+	Protocol    *string `json:"protocol,omitempty"`
+	Name        *string `json:"name,omitempty"`
+	IsProtected bool    `json:"is_protected"`
+}
+
+// SandboxStatus is generated from #/components/schemas/SandboxStatus
+type SandboxStatus struct {
+	SandboxID string    `json:"sandbox_id"`
+	Status    string    `json:"status"`
+	Substatus *string   `json:"substatus,omitempty"`
+	Reason    *string   `json:"reason,omitempty"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// SandboxListResponse is generated from #/components/schemas/SandboxListResponse
+type SandboxListResponse struct {
+	Sandboxes []Sandbox `json:"sandboxes"`
+}
+
+// DeletionResponse is generated from #/components/schemas/DeletionResponse
+type DeletionResponse struct {
+	SandboxID string `json:"sandbox_id"`
+	Status    string `json:"status"`
+	Note      string `json:"note"`
+}
+
+// TerminationResponse is generated from #/components/schemas/TerminationResponse
+type TerminationResponse struct {
+	SandboxID string `json:"sandbox_id"`
+	Status    string `json:"status"`
+}
+
+// SandboxMetricsResponse is generated from #/components/schemas/SandboxMetricsResponse
+type SandboxMetricsResponse struct {
+	SandboxID     string             `json:"sandbox_id"`
+	Timestamp     time.Time          `json:"timestamp"`
+	Status        string             `json:"status"`
+	UptimeSeconds int64              `json:"uptime_seconds"`
+	Metrics       []MetricsDataPoint `json:"metrics"`
+}
+
+// MetricsDataPoint is generated from #/components/schemas/MetricsDataPoint
+type MetricsDataPoint struct {
+	Timestamp  time.Time `json:"timestamp"`
+	CPUCount   int       `json:"cpu_count"`
+	CPUUsedPct float64   `json:"cpu_used_pct"` // CPU usage percentage (0-100)
+	DiskTotal  int64     `json:"disk_total"`
+	DiskUsed   int64     `json:"disk_used"`
+	MemTotal   int64     `json:"mem_total"`
+	MemUsed    int64     `json:"mem_used"`
+}
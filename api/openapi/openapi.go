@@ -0,0 +1,54 @@
+// Package openapi holds the OpenAPI 3 description of the Sandboxes API together
+// with the models and low-level client generated from it.
+//
+// The hand-written models and api/sandboxes packages remain the primary SDK
+// surface; the tests in this package fail when they drift from openapi.yaml.
+// After editing openapi.yaml, run go generate ./api/openapi.
+package openapi
+
+import (
+	"context"
+	_ "embed"
+	"net/url"
+
+	"github.com/scalebox/scalebox-sdk-golang/client"
+)
+
+//go:generate go run ../../internal/openapigen -spec openapi.yaml -package openapi -out .
+
+//go:embed openapi.yaml
+var spec []byte
+
+// Spec returns the OpenAPI document
+func Spec() []byte {
+	return append([]byte(nil), spec...)
+}
+
+// Operation describes an API operation of the spec
+type Operation struct {
+	ID          string // operationId
+	Method      string
+	Path        string // Path template, e.g. /v1/sandboxes/{sandbox_id}
+	QueryParams []string
+}
+
+// Client is the low-level client generated from the spec.
+// It performs no validation and returns responses exactly as modelled in openapi.yaml.
+type Client struct {
+	baseClient *client.Client
+}
+
+// NewClient creates a new low-level client
+func NewClient(baseClient *client.Client) *Client {
+	return &Client{
+		baseClient: baseClient,
+	}
+}
+
+func (c *Client) do(ctx context.Context, method, path string, body interface{}, query url.Values, headers map[string]string, target interface{}) error {
+	resp, err := c.baseClient.DoRequestValues(ctx, method, path, body, query, headers)
+	if err != nil {
+		return err
+	}
+	return c.baseClient.ParseResponse(resp, target)
+}
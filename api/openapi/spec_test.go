@@ -0,0 +1,271 @@
+package openapi_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/scalebox/scalebox-sdk-golang/api/openapi"
+	"github.com/scalebox/scalebox-sdk-golang/api/sandboxes"
+	"github.com/scalebox/scalebox-sdk-golang/client"
+	"github.com/scalebox/scalebox-sdk-golang/models"
+)
+
+type recordedRequest struct {
+	method string
+	path   string
+	query  []string
+}
+
+// recordRequests runs calls against a server that records every request and answers with an empty object
+func recordRequests(t *testing.T, calls func(ctx context.Context, c *sandboxes.Client)) []recordedRequest {
+	t.Helper()
+	var mu sync.Mutex
+	var recorded []recordedRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var keys []string
+		for k := range r.URL.Query() {
+			keys = append(keys, k)
+		}
+		mu.Lock()
+		recorded = append(recorded, recordedRequest{method: r.Method, path: r.URL.Path, query: keys})
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	calls(context.Background(), sandboxes.NewClient(client.NewClient(server.URL, "test-api-key")))
+	return recorded
+}
+
+func pathPattern(template string) *regexp.Regexp {
+	return regexp.MustCompile("^" + regexp.MustCompile(`\\\{[^}]+\\\}`).ReplaceAllString(regexp.QuoteMeta(template), "[^/]+") + "$")
+}
+
+// TestSandboxesClientMatchesSpec exercises every hand-written API method with all
+// options set and checks the requests against the operations in openapi.yaml
+func TestSandboxesClientMatchesSpec(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := start.Add(time.Hour)
+
+	recorded := recordRequests(t, func(ctx context.Context, c *sandboxes.Client) {
+		calls := []func() error{
+			func() error {
+				_, err := c.Create(ctx, models.CreateSandboxRequest{Name: "spec", Template: "base", CPUCount: 1, MemoryMB: 512, StorageGB: 1})
+				return err
+			},
+			func() error {
+				_, err := c.List(ctx, &models.ListSandboxesOptions{
+					ProjectID: "p", Status: "running", OwnerUserID: "u", Search: "s",
+					SortBy: "created_at", SortOrder: "desc", Limit: 10, Offset: 5,
+					LabelSelector: "team=ml",
+					Statuses:      []string{"paused"}, CreatedAfter: &start, CreatedBefore: &end, TimeoutBefore: &end,
+					TemplateID: "tpl-1", TemplateName: "base", Region: "us-east", MinCPUCount: 2, MinMemoryMB: 1024,
+					IncludeTerminated: true,
+				})
+				return err
+			},
+			func() error { _, err := c.Get(ctx, "sbx-1"); return err },
+			func() error { _, err := c.GetStatus(ctx, "sbx-1"); return err },
+			func() error {
+				_, err := c.Update(ctx, "sbx-1", models.UpdateSandboxRequest{Timeout: models.Ptr(600)})
+				return err
+			},
+			func() error { _, err := c.Delete(ctx, "sbx-1", models.Ptr(false)); return err },
+			func() error { _, err := c.Terminate(ctx, "sbx-1", models.Ptr(true)); return err },
+			func() error { _, err := c.Pause(ctx, "sbx-1"); return err },
+			func() error { _, err := c.Resume(ctx, "sbx-1"); return err },
+			func() error { _, err := c.Connect(ctx, "sbx-1", nil); return err },
+			func() error { _, err := c.AddLabels(ctx, "sbx-1", map[string]string{"team": "ml"}); return err },
+			func() error { _, err := c.RemoveLabels(ctx, "sbx-1", "team"); return err },
+			func() error {
+				_, err := c.SetTimeout(ctx, "sbx-1", models.SandboxTimeoutRequest{Timeout: 600})
+				return err
+			},
+			func() error {
+				_, err := c.GetMetrics(ctx, "sbx-1", &models.GetSandboxMetricsOptions{Start: &start, End: &end, Step: models.Ptr(5)})
+				return err
+			},
+			func() error {
+				// By default StreamMetrics polls getSandboxMetrics until the context ends
+				ctx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+				defer cancel()
+				stream := c.StreamMetrics(ctx, "sbx-1", 10*time.Millisecond)
+				for range stream.Points() {
+				}
+				return nil
+			},
+		}
+		for i, call := range calls {
+			if err := call(); err != nil {
+				t.Fatalf("call %d: %v", i, err)
+			}
+		}
+	})
+
+	sent := make(map[string]map[string]bool)
+	for _, req := range recorded {
+		var op *openapi.Operation
+		for i := range openapi.Operations {
+			candidate := &openapi.Operations[i]
+			if candidate.Method == req.method && pathPattern(candidate.Path).MatchString(req.path) {
+				op = candidate
+				break
+			}
+		}
+		if op == nil {
+			t.Errorf("%s %s is not described by openapi.yaml", req.method, req.path)
+			continue
+		}
+		if sent[op.ID] == nil {
+			sent[op.ID] = make(map[string]bool)
+		}
+		for _, k := range req.query {
+			sent[op.ID][k] = true
+		}
+	}
+
+	for _, op := range openapi.Operations {
+		keys, ok := sent[op.ID]
+		if !ok {
+			t.Errorf("operation %s (%s %s) is not implemented by api/sandboxes", op.ID, op.Method, op.Path)
+			continue
+		}
+		declared := make(map[string]bool)
+		for _, p := range op.QueryParams {
+			declared[p] = true
+			if !keys[p] {
+				t.Errorf("operation %s: query parameter %q is never sent by api/sandboxes", op.ID, p)
+			}
+		}
+		for k := range keys {
+			if !declared[k] {
+				t.Errorf("operation %s: api/sandboxes sends undeclared query parameter %q", op.ID, k)
+			}
+		}
+	}
+}
+
+// jsonFields returns a type's JSON field names mapped to whether they are omitempty
+func jsonFields(t reflect.Type) map[string]bool {
+	fields := make(map[string]bool)
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" || !f.IsExported() {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		if name == "" {
+			name = f.Name
+		}
+		fields[name] = strings.Contains(opts, "omitempty")
+	}
+	return fields
+}
+
+// TestModelsMatchSpec compares the JSON fields of the hand-written models with the generated ones
+func TestModelsMatchSpec(t *testing.T) {
+	pairs := map[string][2]interface{}{
+		"StandardResponse":       {client.StandardResponse{}, openapi.StandardResponse{}},
+		"CreateSandboxRequest":   {models.CreateSandboxRequest{}, openapi.CreateSandboxRequest{}},
+		"ObjectStorageConfig":    {models.ObjectStorageConfig{}, openapi.ObjectStorageConfig{}},
+		"LocalityRequest":        {models.LocalityRequest{}, openapi.LocalityRequest{}},
+		"UpdateSandboxRequest":   {models.UpdateSandboxRequest{}, openapi.UpdateSandboxRequest{}},
+		"AddLabelsRequest":       {models.AddLabelsRequest{}, openapi.AddLabelsRequest{}},
+		"SandboxTimeoutRequest":  {models.SandboxTimeoutRequest{}, openapi.SandboxTimeoutRequest{}},
+		"ConnectSandboxRequest":  {models.ConnectSandboxRequest{}, openapi.ConnectSandboxRequest{}},
+		"PauseSandboxRequest":    {models.PauseSandboxRequest{}, openapi.PauseSandboxRequest{}},
+		"ResumeSandboxRequest":   {models.ResumeSandboxRequest{}, openapi.ResumeSandboxRequest{}},
+		"Sandbox":                {models.Sandbox{}, openapi.Sandbox{}},
+		"Owner":                  {models.Owner{}, openapi.Owner{}},
+		"AccountOwner":           {models.AccountOwner{}, openapi.AccountOwner{}},
+		"Resources":              {models.Resources{}, openapi.Resources{}},
+		"PortConfig":             {models.PortConfig{}, openapi.PortConfig{}},
+		"SandboxStatus":          {models.SandboxStatus{}, openapi.SandboxStatus{}},
+		"SandboxListResponse":    {models.SandboxListResponse{}, openapi.SandboxListResponse{}},
+		"DeletionResponse":       {models.DeletionResponse{}, openapi.DeletionResponse{}},
+		"TerminationResponse":    {models.TerminationResponse{}, openapi.TerminationResponse{}},
+		"SandboxMetricsResponse": {models.SandboxMetricsResponse{}, openapi.SandboxMetricsResponse{}},
+		"MetricsDataPoint":       {models.MetricsDataPoint{}, openapi.MetricsDataPoint{}},
+	}
+
+	data, err := os.ReadFile("openapi.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc struct {
+		Components struct {
+			Schemas map[string]interface{} `yaml:"schemas"`
+		} `yaml:"components"`
+	}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		t.Fatal(err)
+	}
+	for name := range doc.Components.Schemas {
+		if _, ok := pairs[name]; !ok {
+			t.Errorf("schema %s has no hand-written counterpart in this test", name)
+		}
+	}
+
+	names := make([]string, 0, len(pairs))
+	for name := range pairs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		hand := jsonFields(reflect.TypeOf(pairs[name][0]))
+		gen := jsonFields(reflect.TypeOf(pairs[name][1]))
+		for field, omitempty := range gen {
+			handOmit, ok := hand[field]
+			switch {
+			case !ok:
+				t.Errorf("%s: spec field %q is missing from the hand-written model", name, field)
+			case handOmit != omitempty:
+				t.Errorf("%s: field %q is required in one of the spec and the hand-written model but not the other", name, field)
+			}
+		}
+		for field := range hand {
+			if _, ok := gen[field]; !ok {
+				t.Errorf("%s: hand-written field %q is not in the spec", name, field)
+			}
+		}
+	}
+}
+
+// TestGeneratedClient decodes a recorded response with the generated client and models
+func TestGeneratedClient(t *testing.T) {
+	fixture, err := os.ReadFile("../../models/testdata/responses/sandbox_get.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" || r.URL.Path != "/v1/sandboxes/sbx-1" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(fixture)
+	}))
+	defer server.Close()
+
+	base := client.NewClient(server.URL, "test-api-key")
+	base.SchemaMode = client.SchemaStrict
+	sandbox, err := openapi.NewClient(base).GetSandbox(context.Background(), "sbx-1")
+	if err != nil {
+		t.Fatalf("GetSandbox: %v", err)
+	}
+	if sandbox.SandboxID == "" || sandbox.CreatedAt.IsZero() {
+		t.Errorf("sandbox not decoded: %+v", sandbox)
+	}
+}
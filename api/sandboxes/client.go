@@ -2,17 +2,32 @@ package sandboxes
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log"
+	"net/url"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/scalebox/scalebox-sdk-golang/client"
+	"github.com/scalebox/scalebox-sdk-golang/labels"
 	"github.com/scalebox/scalebox-sdk-golang/models"
 )
 
 // Client provides methods for interacting with the Sandboxes API
 type Client struct {
 	baseClient *client.Client
+	opts       ClientOptions
+	guard      CreateGuard // See WithCreateGuard
+}
+
+// ClientOptions configures optional Sandboxes API client behaviour
+type ClientOptions struct {
+	SkipValidation bool // Send create requests without client-side validation
+	// MetricsEventStream makes StreamMetrics try the experimental server-sent events endpoint
+	// before polling. Servers without it cost one extra request per stream.
+	MetricsEventStream bool
 }
 
 // NewClient creates a new Sandboxes API client
@@ -22,8 +37,37 @@ func NewClient(baseClient *client.Client) *Client {
 	}
 }
 
-// Create creates a new sandbox
+// NewClientWithOptions creates a new Sandboxes API client with custom options
+func NewClientWithOptions(baseClient *client.Client, opts ClientOptions) *Client {
+	return &Client{
+		baseClient: baseClient,
+		opts:       opts,
+	}
+}
+
+// Create creates a new sandbox.
+// The request is validated client-side first unless ClientOptions.SkipValidation is set;
+// validation failures are returned as *models.ValidationError.
+// A guard attached with WithCreateGuard may reject the request before it is sent.
 func (c *Client) Create(ctx context.Context, req models.CreateSandboxRequest) (*models.Sandbox, error) {
+	if !c.opts.SkipValidation {
+		if err := req.Validate(); err != nil {
+			return nil, err
+		}
+	}
+	if c.guard == nil {
+		return c.create(ctx, req)
+	}
+	done, err := c.guard.Admit(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	sandbox, err := c.create(ctx, req)
+	done(sandbox, err)
+	return sandbox, err
+}
+
+func (c *Client) create(ctx context.Context, req models.CreateSandboxRequest) (*models.Sandbox, error) {
 	resp, err := c.baseClient.DoRequest(ctx, "POST", "/v1/sandboxes", req, nil)
 	if err != nil {
 		return nil, err
@@ -37,47 +81,103 @@ func (c *Client) Create(ctx context.Context, req models.CreateSandboxRequest) (*
 	return &sandbox, nil
 }
 
-// List lists sandboxes with optional filters
+// List lists sandboxes with optional filters.
+// The options are validated client-side first unless ClientOptions.SkipValidation is set.
+// A LabelSelector is also evaluated client-side, so servers that ignore it still return only matching sandboxes.
 func (c *Client) List(ctx context.Context, opts *models.ListSandboxesOptions) (*models.SandboxListResponse, error) {
-	queryParams := make(map[string]string)
+	result, _, err := c.list(ctx, opts)
+	return result, err
+}
+
+// list is List that also returns the page size before client-side label filtering, for paging
+func (c *Client) list(ctx context.Context, opts *models.ListSandboxesOptions) (*models.SandboxListResponse, int, error) {
+	var selector labels.Selector
+	query := make(url.Values)
 	if opts != nil {
+		if !c.opts.SkipValidation {
+			if err := opts.Validate(); err != nil {
+				return nil, 0, err
+			}
+		}
 		if opts.ProjectID != "" {
-			queryParams["project_id"] = opts.ProjectID
+			query.Set("project_id", opts.ProjectID)
 		}
-		if opts.Status != "" {
-			queryParams["status"] = opts.Status
+		for _, status := range opts.StatusFilter() {
+			query.Add("status", status)
 		}
 		if opts.OwnerUserID != "" {
-			queryParams["owner_user_id"] = opts.OwnerUserID
+			query.Set("owner_user_id", opts.OwnerUserID)
 		}
 		if opts.Search != "" {
-			queryParams["search"] = opts.Search
+			query.Set("search", opts.Search)
+		}
+		if opts.LabelSelector != "" {
+			var err error
+			if selector, err = labels.Parse(opts.LabelSelector); err != nil {
+				return nil, 0, err
+			}
+			query.Set("label_selector", selector.String())
+		}
+		if opts.CreatedAfter != nil {
+			query.Set("created_after", formatTime(*opts.CreatedAfter, models.TimeFormatRFC3339))
+		}
+		if opts.CreatedBefore != nil {
+			query.Set("created_before", formatTime(*opts.CreatedBefore, models.TimeFormatRFC3339))
+		}
+		if opts.TimeoutBefore != nil {
+			query.Set("timeout_before", formatTime(*opts.TimeoutBefore, models.TimeFormatRFC3339))
+		}
+		if opts.TemplateID != "" {
+			query.Set("template_id", opts.TemplateID)
+		}
+		if opts.TemplateName != "" {
+			query.Set("template_name", opts.TemplateName)
+		}
+		if opts.Region != "" {
+			query.Set("region", opts.Region)
+		}
+		if opts.MinCPUCount > 0 {
+			query.Set("min_cpu_count", strconv.Itoa(opts.MinCPUCount))
+		}
+		if opts.MinMemoryMB > 0 {
+			query.Set("min_memory_mb", strconv.Itoa(opts.MinMemoryMB))
+		}
+		if opts.IncludeTerminated {
+			query.Set("include_terminated", "true")
 		}
 		if opts.SortBy != "" {
-			queryParams["sort_by"] = opts.SortBy
+			if !models.IsKnownSortField(opts.SortBy) {
+				// Newer servers may sort by more fields, so pass it through and let the server decide
+				log.Printf("scalebox: unknown sort_by %q, known fields are %s", opts.SortBy, strings.Join(models.SortFields, ", "))
+			}
+			query.Set("sort_by", opts.SortBy)
 		}
 		if opts.SortOrder != "" {
-			queryParams["sort_order"] = opts.SortOrder
+			query.Set("sort_order", opts.SortOrder)
 		}
 		if opts.Limit > 0 {
-			queryParams["limit"] = strconv.Itoa(opts.Limit)
+			query.Set("limit", strconv.Itoa(opts.Limit))
 		}
 		if opts.Offset > 0 {
-			queryParams["offset"] = strconv.Itoa(opts.Offset)
+			query.Set("offset", strconv.Itoa(opts.Offset))
 		}
 	}
 
-	resp, err := c.baseClient.DoRequest(ctx, "GET", "/v1/sandboxes", nil, queryParams)
+	resp, err := c.baseClient.DoRequestValues(ctx, "GET", "/v1/sandboxes", nil, query, nil)
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 
 	var result models.SandboxListResponse
 	if err := c.baseClient.ParseResponse(resp, &result); err != nil {
-		return nil, err
+		return nil, 0, err
+	}
+	fetched := len(result.Sandboxes)
+	if !selector.Empty() {
+		result.Sandboxes = filterSandboxes(result.Sandboxes, selector)
 	}
 
-	return &result, nil
+	return &result, fetched, nil
 }
 
 // Get retrieves a sandbox by ID
@@ -89,9 +189,11 @@ func (c *Client) Get(ctx context.Context, sandboxID string) (*models.Sandbox, er
 	}
 
 	var sandbox models.Sandbox
+	etag := resp.Header.Get("ETag")
 	if err := c.baseClient.ParseResponse(resp, &sandbox); err != nil {
 		return nil, err
 	}
+	sandbox.ETag = etag
 
 	return &sandbox, nil
 }
@@ -112,18 +214,38 @@ func (c *Client) GetStatus(ctx context.Context, sandboxID string) (*models.Sandb
 	return &status, nil
 }
 
-// Update updates a sandbox
+// Update applies a partial update to a sandbox via PATCH.
+// The request is validated client-side first unless ClientOptions.SkipValidation is set.
+// When a precondition set through ExpectedUpdatedAt or IfMatch fails, a *ConflictError is returned.
 func (c *Client) Update(ctx context.Context, sandboxID string, req models.UpdateSandboxRequest) (*models.Sandbox, error) {
+	if !c.opts.SkipValidation {
+		if err := req.Validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	var headers map[string]string
+	if req.IfMatch != "" {
+		headers = map[string]string{"If-Match": req.IfMatch}
+	}
+
 	path := fmt.Sprintf("/v1/sandboxes/%s", sandboxID)
-	resp, err := c.baseClient.DoRequest(ctx, "PUT", path, req, nil)
+	resp, err := c.baseClient.DoRequestValues(ctx, "PATCH", path, req, nil, headers)
 	if err != nil {
 		return nil, err
 	}
 
 	var sandbox models.Sandbox
+	etag := resp.Header.Get("ETag")
 	if err := c.baseClient.ParseResponse(resp, &sandbox); err != nil {
+		// Without a precondition a 409 is an ordinary API error, e.g. an invalid state transition
+		var apiErr *client.APIError
+		if (req.IfMatch != "" || req.ExpectedUpdatedAt != nil) && client.IsConflict(err) && errors.As(err, &apiErr) {
+			return nil, &ConflictError{SandboxID: sandboxID, StatusCode: apiErr.StatusCode, Message: apiErr.Message, Err: err}
+		}
 		return nil, err
 	}
+	sandbox.ETag = etag
 
 	return &sandbox, nil
 }
@@ -240,17 +362,24 @@ func (c *Client) SetTimeout(ctx context.Context, sandboxID string, req models.Sa
 	return &sandbox, nil
 }
 
-// GetMetrics retrieves metrics for a sandbox
+// GetMetrics retrieves metrics for a sandbox.
+// The options are validated client-side first unless ClientOptions.SkipValidation is set.
+// See GetMetricsRange for long ranges.
 func (c *Client) GetMetrics(ctx context.Context, sandboxID string, opts *models.GetSandboxMetricsOptions) (*models.SandboxMetricsResponse, error) {
 	path := fmt.Sprintf("/v1/sandboxes/%s/metrics", sandboxID)
 	queryParams := make(map[string]string)
 
 	if opts != nil {
+		if !c.opts.SkipValidation {
+			if err := opts.Validate(); err != nil {
+				return nil, err
+			}
+		}
 		if opts.Start != nil {
-			queryParams["start"] = formatTime(*opts.Start)
+			queryParams["start"] = formatTime(*opts.Start, opts.TimeFormat)
 		}
 		if opts.End != nil {
-			queryParams["end"] = formatTime(*opts.End)
+			queryParams["end"] = formatTime(*opts.End, opts.TimeFormat)
 		}
 		if opts.Step != nil {
 			queryParams["step"] = strconv.Itoa(*opts.Step)
@@ -270,9 +399,10 @@ func (c *Client) GetMetrics(ctx context.Context, sandboxID string, opts *models.
 	return &result, nil
 }
 
-// formatTime formats time for API query parameters
-// Supports Unix timestamp, RFC3339, and simple datetime formats
-func formatTime(t time.Time) string {
-	// Try RFC3339 first (most common)
+// formatTime formats time for API query parameters, as RFC3339 unless format is TimeFormatUnix
+func formatTime(t time.Time, format models.TimeFormat) string {
+	if format == models.TimeFormatUnix {
+		return strconv.FormatInt(t.Unix(), 10)
+	}
 	return t.Format(time.RFC3339)
 }
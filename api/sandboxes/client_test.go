@@ -3,6 +3,7 @@ package sandboxes
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -74,6 +75,46 @@ func TestCreate(t *testing.T) {
 	}
 }
 
+func TestCreateValidation(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(models.Sandbox{SandboxID: "sbx-test123"})
+	}))
+	defer server.Close()
+
+	req := models.CreateSandboxRequest{
+		Name:            "Invalid Sandbox",
+		CPUCount:        -1,
+		NetProxyCountry: "usa",
+	}
+
+	// Invalid requests are rejected before reaching the server
+	sandboxClient := NewClient(client.NewClient(server.URL, "test-api-key"))
+	_, err := sandboxClient.Create(context.Background(), req)
+	verr, ok := err.(*models.ValidationError)
+	if !ok {
+		t.Fatalf("Expected *models.ValidationError, got %T (%v)", err, err)
+	}
+	if len(verr.Errors) != 2 {
+		t.Errorf("Expected 2 field errors, got %v", verr.Errors)
+	}
+	if requests != 0 {
+		t.Errorf("Expected no request to be sent, got %d", requests)
+	}
+
+	// Validation can be skipped
+	sandboxClient = NewClientWithOptions(client.NewClient(server.URL, "test-api-key"), ClientOptions{SkipValidation: true})
+	if _, err := sandboxClient.Create(context.Background(), req); err != nil {
+		t.Fatalf("Create without validation failed: %v", err)
+	}
+	if requests != 1 {
+		t.Errorf("Expected 1 request to be sent, got %d", requests)
+	}
+}
+
 func TestGet(t *testing.T) {
 	sandboxID := "sbx-test123"
 
@@ -110,6 +151,80 @@ func TestGet(t *testing.T) {
 	}
 }
 
+func TestUpdate(t *testing.T) {
+	updatedAt := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "PATCH" {
+			t.Errorf("Expected PATCH, got %s", r.Method)
+		}
+		var req models.UpdateSandboxRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("Failed to decode request: %v", err)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if req.Name != nil && *req.Name == "busy" {
+			w.WriteHeader(http.StatusConflict)
+			json.NewEncoder(w).Encode(map[string]string{"message": "sandbox is being paused"})
+			return
+		}
+		if r.Header.Get("If-Match") != "" && r.Header.Get("If-Match") != `"v2"` {
+			w.WriteHeader(http.StatusPreconditionFailed)
+			json.NewEncoder(w).Encode(map[string]string{"message": "etag mismatch"})
+			return
+		}
+		sandbox := models.Sandbox{SandboxID: "sbx-test123", Name: "old", Description: models.Ptr("desc")}
+		req.ApplyTo(&sandbox)
+		w.Header().Set("ETag", `"v3"`)
+		json.NewEncoder(w).Encode(sandbox)
+	}))
+	defer server.Close()
+
+	sandboxClient := NewClient(client.NewClient(server.URL, "test-api-key"))
+	req := models.UpdateSandboxRequest{
+		Name:  models.Ptr("renamed"),
+		Clear: []string{models.UpdateFieldDescription},
+	}
+	req.ExpectUnchanged(&models.Sandbox{UpdatedAt: updatedAt, ETag: `"v2"`})
+
+	sandbox, err := sandboxClient.Update(context.Background(), "sbx-test123", req)
+	if err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	if sandbox.Name != "renamed" || sandbox.Description != nil {
+		t.Errorf("Expected renamed sandbox without description, got %q %v", sandbox.Name, sandbox.Description)
+	}
+	if sandbox.ETag != `"v3"` {
+		t.Errorf("Expected ETag from response header, got %q", sandbox.ETag)
+	}
+
+	// A stale ETag is reported as a conflict
+	req.IfMatch = `"v1"`
+	_, err = sandboxClient.Update(context.Background(), "sbx-test123", req)
+	var conflict *ConflictError
+	if !errors.As(err, &conflict) || conflict.StatusCode != http.StatusPreconditionFailed {
+		t.Fatalf("Expected *ConflictError with status 412, got %T (%v)", err, err)
+	}
+	if !IsConflict(err) {
+		t.Error("Expected IsConflict to be true")
+	}
+	var apiErr *client.APIError
+	if !errors.As(err, &apiErr) || apiErr.Message != "etag mismatch" {
+		t.Errorf("Expected the original *client.APIError to be wrapped, got %v", apiErr)
+	}
+
+	// Without a precondition a 409 stays a plain API error
+	_, err = sandboxClient.Update(context.Background(), "sbx-test123", models.UpdateSandboxRequest{Name: models.Ptr("busy")})
+	if IsConflict(err) || !client.IsConflict(err) {
+		t.Errorf("Expected a plain 409 *client.APIError, got %T (%v)", err, err)
+	}
+
+	// Empty updates are rejected client-side
+	if _, err := sandboxClient.Update(context.Background(), "sbx-test123", models.UpdateSandboxRequest{}); err == nil {
+		t.Error("Expected validation error for empty update")
+	}
+}
+
 func TestList(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != "GET" {
@@ -161,6 +276,74 @@ func TestList(t *testing.T) {
 	}
 }
 
+func TestListFilters(t *testing.T) {
+	created := time.Date(2024, 3, 1, 8, 0, 0, 0, time.UTC)
+	var query map[string][]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query()
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(models.SandboxListResponse{})
+	}))
+	defer server.Close()
+
+	sandboxClient := NewClient(client.NewClient(server.URL, "test-api-key"))
+	_, err := sandboxClient.List(context.Background(), &models.ListSandboxesOptions{
+		Status:            "running",
+		Statuses:          []string{"paused", "running"},
+		CreatedAfter:      &created,
+		TemplateName:      "base",
+		MinCPUCount:       4,
+		IncludeTerminated: true,
+		SortBy:            models.SortByTimeoutAt,
+		SortOrder:         models.SortAsc,
+	})
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+
+	if got := query["status"]; len(got) != 2 || got[0] != "running" || got[1] != "paused" {
+		t.Errorf("Expected repeated status keys [running paused], got %v", got)
+	}
+	expected := map[string]string{
+		"created_after":      "2024-03-01T08:00:00Z",
+		"template_name":      "base",
+		"min_cpu_count":      "4",
+		"include_terminated": "true",
+		"sort_by":            "timeout_at",
+		"sort_order":         "asc",
+	}
+	for key, want := range expected {
+		if got := query[key]; len(got) != 1 || got[0] != want {
+			t.Errorf("Expected %s=%s, got %v", key, want, got)
+		}
+	}
+	for _, key := range []string{"created_before", "min_memory_mb", "region", "limit"} {
+		if _, ok := query[key]; ok {
+			t.Errorf("Expected unset option %s to be omitted", key)
+		}
+	}
+
+	// Unknown sort fields are passed through to the server
+	_, err = sandboxClient.List(context.Background(), &models.ListSandboxesOptions{SortBy: "popularity"})
+	if err != nil {
+		t.Fatalf("Expected unknown sort_by to be sent, got %v", err)
+	}
+	if got := query["sort_by"]; len(got) != 1 || got[0] != "popularity" {
+		t.Errorf("Expected sort_by=popularity, got %v", got)
+	}
+
+	// Invalid options are rejected before any request is made
+	query = nil
+	_, err = sandboxClient.List(context.Background(), &models.ListSandboxesOptions{SortOrder: "up"})
+	var verr *models.ValidationError
+	if !errors.As(err, &verr) || verr.Errors[0].Field != "sort_order" {
+		t.Fatalf("Expected sort_order validation error, got %v", err)
+	}
+	if query != nil {
+		t.Error("Expected no request for invalid options")
+	}
+}
+
 func TestDelete(t *testing.T) {
 	sandboxID := "sbx-test123"
 
@@ -0,0 +1,41 @@
+package sandboxes
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ConflictError is returned by Update when the sandbox changed since it was read,
+// i.e. the ExpectedUpdatedAt or If-Match precondition failed
+type ConflictError struct {
+	SandboxID  string
+	StatusCode int // 409 Conflict or 412 Precondition Failed
+	Message    string
+	Err        error // The underlying *client.APIError
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("sandbox %s was modified concurrently (status %d): %s", e.SandboxID, e.StatusCode, e.Message)
+}
+
+// Unwrap returns the underlying API error, so errors.As still finds a *client.APIError
+func (e *ConflictError) Unwrap() error {
+	return e.Err
+}
+
+// IsConflict checks if the error is a *ConflictError
+func IsConflict(err error) bool {
+	var conflict *ConflictError
+	return errors.As(err, &conflict)
+}
+
+// AmbiguousError is returned by GetByName, FindOne and Ensure when more than one sandbox matches
+type AmbiguousError struct {
+	Query      string // The name, selector or client key that was looked up
+	SandboxIDs []string
+}
+
+func (e *AmbiguousError) Error() string {
+	return fmt.Sprintf("%d sandboxes match %s: %s", len(e.SandboxIDs), e.Query, strings.Join(e.SandboxIDs, ", "))
+}
@@ -0,0 +1,32 @@
+package sandboxes
+
+import (
+	"context"
+
+	"github.com/scalebox/scalebox-sdk-golang/models"
+)
+
+// ExportSpec fetches a sandbox and describes it as a spec that ImportSpec can recreate.
+// Settings that cannot be carried over, such as object storage credentials, are listed in
+// SandboxSpec.Unreproducible.
+func (c *Client) ExportSpec(ctx context.Context, sandboxID string) (*models.SandboxSpec, error) {
+	sandbox, err := c.Get(ctx, sandboxID)
+	if err != nil {
+		return nil, err
+	}
+	return models.NewSandboxSpec(sandbox), nil
+}
+
+// ImportSpec creates a new sandbox from a spec.
+// Unreproducible fields that must be supplied by hand, see SandboxSpec.Unresolved, are reported
+// as *models.ValidationError before anything is created.
+func (c *Client) ImportSpec(ctx context.Context, spec *models.SandboxSpec) (*models.Sandbox, error) {
+	if missing := spec.Unresolved(); len(missing) > 0 {
+		errs := make([]models.FieldError, len(missing))
+		for i, f := range missing {
+			errs[i] = models.FieldError{Field: "spec." + f.Field, Message: "must be filled in before import: " + f.Reason}
+		}
+		return nil, &models.ValidationError{Errors: errs}
+	}
+	return c.Create(ctx, spec.Spec.Clone())
+}
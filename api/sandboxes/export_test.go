@@ -0,0 +1,80 @@
+package sandboxes
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/scalebox/scalebox-sdk-golang/client"
+	"github.com/scalebox/scalebox-sdk-golang/models"
+)
+
+func TestExportAndImportSpec(t *testing.T) {
+	source := models.Sandbox{
+		SandboxID:     "sbx-src",
+		Name:          "worker-a1b2",
+		TemplateID:    "tpl-base",
+		CPUCount:      2,
+		MemoryMB:      2048,
+		StorageGB:     10,
+		Timeout:       600,
+		AutoPause:     true,
+		Secure:        true,
+		Metadata:      map[string]string{"team": "ml"},
+		ObjectStorage: map[string]string{"uri": "s3://bucket/data", "mount_point": "/mnt/data"},
+		CustomPorts:   []models.PortConfig{{Port: 8080, Protocol: "http"}},
+		Status:        models.StatusRunning,
+	}
+	var created *models.CreateSandboxRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/v1/sandboxes/sbx-src":
+			json.NewEncoder(w).Encode(source)
+		case r.Method == "POST" && r.URL.Path == "/v1/sandboxes":
+			created = &models.CreateSandboxRequest{}
+			json.NewDecoder(r.Body).Decode(created)
+			json.NewEncoder(w).Encode(models.Sandbox{SandboxID: "sbx-copy", Name: created.Name + "-c3d4", Status: models.StatusStarting})
+		default:
+			t.Errorf("Unexpected %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer server.Close()
+	sandboxClient := NewClient(client.NewClient(server.URL, "test-api-key"))
+	ctx := context.Background()
+
+	spec, err := sandboxClient.ExportSpec(ctx, "sbx-src")
+	if err != nil {
+		t.Fatalf("ExportSpec failed: %v", err)
+	}
+	// The name loses its backend suffix, so a round trip does not stack suffixes
+	if spec.Source.SandboxID != "sbx-src" || spec.Spec.Name != "worker" || len(spec.Unreproducible) != 3 {
+		t.Errorf("Unexpected spec %+v", spec)
+	}
+
+	// Credentials must be supplied before a sandbox is created
+	if _, err := sandboxClient.ImportSpec(ctx, spec); err == nil {
+		t.Fatal("Expected unresolved credentials to be rejected")
+	} else if verr, ok := err.(*models.ValidationError); !ok || !verr.HasField("spec.object_storage.secret_key") {
+		t.Errorf("Expected validation error for the secret key, got %v", err)
+	}
+	if created != nil {
+		t.Fatal("Expected no create request for an unresolved spec")
+	}
+
+	spec.Spec.ObjectStorage.AccessKey = "ak"
+	spec.Spec.ObjectStorage.SecretKey = "sk"
+	sandbox, err := sandboxClient.ImportSpec(ctx, spec)
+	if err != nil {
+		t.Fatalf("ImportSpec failed: %v", err)
+	}
+	if sandbox.SandboxID != "sbx-copy" || created == nil {
+		t.Fatalf("Expected a new sandbox, got %+v", sandbox)
+	}
+	if !reflect.DeepEqual(*created, spec.Spec) {
+		t.Errorf("Create request differs from the spec:\n got %+v\nwant %+v", *created, spec.Spec)
+	}
+}
@@ -0,0 +1,23 @@
+package sandboxes
+
+import (
+	"context"
+
+	"github.com/scalebox/scalebox-sdk-golang/models"
+)
+
+// CreateGuard admits or rejects sandbox creations before they reach the API,
+// e.g. to enforce a budget (see package budget)
+type CreateGuard interface {
+	// Admit is called with a validated request before it is sent. A non-nil error rejects the
+	// request and is returned by Create as is; otherwise done is called once with the outcome.
+	Admit(ctx context.Context, req models.CreateSandboxRequest) (done func(*models.Sandbox, error), err error)
+}
+
+// WithCreateGuard returns a copy of the client whose Create, and so Ensure and ImportSpec,
+// consults g first. The receiver is unchanged, so the guard can keep using it.
+func (c *Client) WithCreateGuard(g CreateGuard) *Client {
+	guarded := *c
+	guarded.guard = g
+	return &guarded
+}
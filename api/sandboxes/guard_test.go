@@ -0,0 +1,73 @@
+package sandboxes
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/scalebox/scalebox-sdk-golang/client"
+	"github.com/scalebox/scalebox-sdk-golang/models"
+)
+
+// countingGuard admits up to max creations
+type countingGuard struct {
+	max, admitted int
+	outcomes      []error
+}
+
+var errTooMany = errors.New("too many sandboxes")
+
+func (g *countingGuard) Admit(ctx context.Context, req models.CreateSandboxRequest) (func(*models.Sandbox, error), error) {
+	if g.admitted == g.max {
+		return nil, errTooMany
+	}
+	g.admitted++
+	return func(sb *models.Sandbox, err error) { g.outcomes = append(g.outcomes, err) }, nil
+}
+
+func TestWithCreateGuard(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("Content-Type", "application/json")
+		if requests == 2 {
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(map[string]string{"error": "boom"})
+			return
+		}
+		json.NewEncoder(w).Encode(models.Sandbox{SandboxID: "sbx-1"})
+	}))
+	defer server.Close()
+
+	plain := NewClient(client.NewClient(server.URL, "test-api-key"))
+	guard := &countingGuard{max: 2}
+	guarded := plain.WithCreateGuard(guard)
+	ctx := context.Background()
+	req := models.CreateSandboxRequest{Template: "base"}
+
+	if _, err := guarded.Create(ctx, req); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if _, err := guarded.Create(ctx, req); err == nil {
+		t.Fatal("Expected the API error")
+	}
+	if _, err := guarded.Create(ctx, req); !errors.Is(err, errTooMany) {
+		t.Fatalf("Expected the guard to reject the request, got %v", err)
+	}
+	if requests != 2 || len(guard.outcomes) != 2 || guard.outcomes[0] != nil || guard.outcomes[1] == nil {
+		t.Errorf("Unexpected requests %d, outcomes %v", requests, guard.outcomes)
+	}
+	// Invalid requests never reach the guard
+	var verr *models.ValidationError
+	if _, err := guarded.Create(ctx, models.CreateSandboxRequest{CPUCount: -1}); !errors.As(err, &verr) {
+		t.Errorf("Expected a validation error, got %v", err)
+	}
+
+	// The client the guard was attached to is unguarded
+	if _, err := plain.Create(ctx, req); err != nil || requests != 3 {
+		t.Errorf("Unguarded Create = %v after %d requests", err, requests)
+	}
+}
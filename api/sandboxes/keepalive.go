@@ -0,0 +1,306 @@
+package sandboxes
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/scalebox/scalebox-sdk-golang/client"
+	"github.com/scalebox/scalebox-sdk-golang/models"
+)
+
+// Keepalive defaults
+const (
+	DefaultKeepAliveMargin    = time.Minute
+	DefaultKeepAliveExtension = 5 * time.Minute
+	DefaultKeepAliveInterval  = 15 * time.Second
+)
+
+// Keepalive stop reasons reported on the error channel
+var (
+	ErrMaxLifetimeReached = errors.New("sandboxes: keepalive stopped, maximum lifetime reached")
+	ErrSandboxNotRunning  = errors.New("sandboxes: keepalive stopped, sandbox is no longer running")
+)
+
+// ExtendMethod selects the API call used to extend a sandbox's lifetime
+type ExtendMethod int
+
+const (
+	// ExtendWithSetTimeout extends the lifetime via SetTimeout
+	ExtendWithSetTimeout ExtendMethod = iota
+	// ExtendWithConnect extends the lifetime via Connect, which also resumes paused sandboxes
+	ExtendWithConnect
+)
+
+// KeepAliveOptions configures how sandbox lifetimes are extended
+type KeepAliveOptions struct {
+	Margin      time.Duration // Extend when TimeoutAt is closer than this, defaults to 1 minute
+	Extension   time.Duration // Push TimeoutAt this far past now on each extension, defaults to 5 minutes
+	Interval    time.Duration // How often TimeoutAt is checked, defaults to 15 seconds
+	MaxLifetime time.Duration // Optional: never extend past CreatedAt + MaxLifetime
+	Method      ExtendMethod
+}
+
+func (o KeepAliveOptions) withDefaults() KeepAliveOptions {
+	if o.Margin <= 0 {
+		o.Margin = DefaultKeepAliveMargin
+	}
+	if o.Extension <= 0 {
+		o.Extension = DefaultKeepAliveExtension
+	}
+	if o.Interval <= 0 {
+		o.Interval = DefaultKeepAliveInterval
+	}
+	return o
+}
+
+// KeepAliveError reports a keepalive failure for a single sandbox
+type KeepAliveError struct {
+	SandboxID string
+	Err       error
+}
+
+func (e *KeepAliveError) Error() string {
+	return "keepalive " + e.SandboxID + ": " + e.Err.Error()
+}
+
+func (e *KeepAliveError) Unwrap() error {
+	return e.Err
+}
+
+type keepAliveEntry struct {
+	timeout   int // last known timeout in seconds
+	timeoutAt time.Time
+	createdAt time.Time
+	known     bool
+}
+
+// Keeper extends the lifetime of a set of sandboxes from a single background loop
+type Keeper struct {
+	client *Client
+	opts   KeepAliveOptions
+
+	mu      sync.Mutex
+	entries map[string]*keepAliveEntry
+	errs    chan *KeepAliveError
+	wake    chan struct{}
+}
+
+// NewKeeper creates a Keeper. Call Run to start extending sandboxes added with Add.
+func NewKeeper(c *Client, opts KeepAliveOptions) *Keeper {
+	return &Keeper{
+		client:  c,
+		opts:    opts.withDefaults(),
+		entries: make(map[string]*keepAliveEntry),
+		errs:    make(chan *KeepAliveError, 16),
+		wake:    make(chan struct{}, 1),
+	}
+}
+
+// Add starts keeping the sandbox alive
+func (k *Keeper) Add(sandboxID string) {
+	k.mu.Lock()
+	if _, ok := k.entries[sandboxID]; !ok {
+		k.entries[sandboxID] = &keepAliveEntry{}
+	}
+	k.mu.Unlock()
+
+	select {
+	case k.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Remove stops keeping the sandbox alive
+func (k *Keeper) Remove(sandboxID string) {
+	k.mu.Lock()
+	delete(k.entries, sandboxID)
+	k.mu.Unlock()
+}
+
+// Len returns the number of sandboxes being kept alive
+func (k *Keeper) Len() int {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	return len(k.entries)
+}
+
+// Errors returns the channel on which failures are reported.
+// Failures are dropped when the channel buffer is full; the channel is closed when Run returns.
+func (k *Keeper) Errors() <-chan *KeepAliveError {
+	return k.errs
+}
+
+// Run checks and extends sandboxes until ctx is done. It must be called at most once.
+func (k *Keeper) Run(ctx context.Context) {
+	defer close(k.errs)
+
+	ticker := time.NewTicker(k.opts.Interval)
+	defer ticker.Stop()
+
+	for {
+		k.tick(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-k.wake:
+		}
+	}
+}
+
+func (k *Keeper) tick(ctx context.Context) {
+	k.mu.Lock()
+	ids := make([]string, 0, len(k.entries))
+	for id := range k.entries {
+		ids = append(ids, id)
+	}
+	k.mu.Unlock()
+
+	for _, id := range ids {
+		if ctx.Err() != nil {
+			return
+		}
+		k.check(ctx, id)
+	}
+}
+
+func (k *Keeper) check(ctx context.Context, id string) {
+	k.mu.Lock()
+	entry, ok := k.entries[id]
+	if !ok {
+		k.mu.Unlock()
+		return
+	}
+	e := *entry
+	k.mu.Unlock()
+
+	now := time.Now()
+	if !e.known || e.timeoutAt.Sub(now) <= k.opts.Margin {
+		// Fetch the sandbox before every extension, so one paused, terminated or extended
+		// elsewhere is noticed without spending an extension on it
+		sandbox, err := k.client.Get(ctx, id)
+		if err != nil {
+			k.fail(id, err, client.IsNotFound(err))
+			return
+		}
+		if models.IsTerminalStatus(sandbox.Status) {
+			k.fail(id, ErrSandboxNotRunning, true)
+			return
+		}
+		if sandbox.TimeoutAt == nil {
+			// Paused sandboxes have no deadline; look again on the next tick
+			return
+		}
+		e = keepAliveEntry{
+			timeout:   sandbox.Timeout,
+			timeoutAt: *sandbox.TimeoutAt,
+			createdAt: sandbox.CreatedAt,
+			known:     true,
+		}
+		k.store(id, e)
+	}
+
+	if e.timeoutAt.Sub(now) > k.opts.Margin {
+		return
+	}
+
+	target := now.Add(k.opts.Extension)
+	if k.opts.MaxLifetime > 0 && !e.createdAt.IsZero() {
+		limit := e.createdAt.Add(k.opts.MaxLifetime)
+		if target.After(limit) {
+			target = limit
+		}
+		if !target.After(e.timeoutAt) {
+			k.fail(id, ErrMaxLifetimeReached, true)
+			return
+		}
+	}
+
+	delta := models.Seconds(target.Sub(e.timeoutAt))
+	newTimeout := e.timeout + delta
+
+	var sandbox *models.Sandbox
+	var err error
+	switch k.opts.Method {
+	case ExtendWithConnect:
+		sandbox, err = k.client.Connect(ctx, id, &models.ConnectSandboxRequest{Timeout: &newTimeout})
+	default:
+		sandbox, err = k.client.SetTimeout(ctx, id, models.SandboxTimeoutRequest{Timeout: newTimeout})
+	}
+	if err != nil {
+		if client.IsNotFound(err) {
+			k.fail(id, err, true)
+			return
+		}
+		// Refresh from the server on the next attempt in case our view is stale
+		e.known = false
+		k.store(id, e)
+		k.fail(id, err, false)
+		return
+	}
+
+	if sandbox != nil && sandbox.Status != "" && sandbox.Status != models.StatusRunning {
+		if models.IsTerminalStatus(sandbox.Status) || sandbox.Status == models.StatusTerminating {
+			k.fail(id, ErrSandboxNotRunning, true)
+			return
+		}
+		// Paused in the meantime; wait for a deadline again
+		e.known = false
+		k.store(id, e)
+		return
+	}
+
+	e.timeout = newTimeout
+	e.timeoutAt = e.timeoutAt.Add(models.SecondsDuration(delta))
+	if sandbox != nil && sandbox.TimeoutAt != nil {
+		e.timeout = sandbox.Timeout
+		e.timeoutAt = *sandbox.TimeoutAt
+	}
+	k.store(id, e)
+}
+
+func (k *Keeper) store(id string, e keepAliveEntry) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if entry, ok := k.entries[id]; ok {
+		*entry = e
+	}
+}
+
+func (k *Keeper) fail(id string, err error, remove bool) {
+	if remove {
+		k.Remove(id)
+	}
+	select {
+	case k.errs <- &KeepAliveError{SandboxID: id, Err: err}:
+	default:
+	}
+}
+
+// KeepAlive extends the sandbox's lifetime ahead of expiry until ctx is done,
+// the maximum lifetime is reached or the sandbox stops running.
+// Failures are reported on the returned channel, which is closed when keepalive stops.
+func KeepAlive(ctx context.Context, c *Client, sandboxID string, opts KeepAliveOptions) <-chan error {
+	ctx, cancel := context.WithCancel(ctx)
+	keeper := NewKeeper(c, opts)
+	keeper.Add(sandboxID)
+	go keeper.Run(ctx)
+
+	errs := make(chan error, 16)
+	go func() {
+		defer close(errs)
+		defer cancel()
+		for kerr := range keeper.Errors() {
+			select {
+			case errs <- kerr.Err:
+			default:
+			}
+			if keeper.Len() == 0 {
+				cancel()
+			}
+		}
+	}()
+	return errs
+}
@@ -0,0 +1,198 @@
+package sandboxes
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/scalebox/scalebox-sdk-golang/client"
+	"github.com/scalebox/scalebox-sdk-golang/models"
+)
+
+// keepAliveServer serves Get and SetTimeout for sandboxes expiring soon
+type keepAliveServer struct {
+	mu        sync.Mutex
+	createdAt time.Time
+	timeouts  map[string][]int
+	status    map[string]string // Overrides "running", e.g. after a sandbox is paused elsewhere
+}
+
+func (s *keepAliveServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id := strings.Split(strings.TrimPrefix(r.URL.Path, "/v1/sandboxes/"), "/")[0]
+	now := time.Now()
+	timeoutAt := now.Add(10 * time.Second)
+	sandbox := models.Sandbox{
+		SandboxID: id,
+		Status:    "running",
+		Timeout:   300,
+		CreatedAt: s.createdAt,
+		TimeoutAt: &timeoutAt,
+	}
+	if status, ok := s.status[id]; ok {
+		sandbox.Status = status
+		if status != "running" {
+			sandbox.TimeoutAt = nil
+		}
+	}
+
+	if r.Method == "POST" && strings.HasSuffix(r.URL.Path, "/timeout") {
+		var req models.SandboxTimeoutRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		s.timeouts[id] = append(s.timeouts[id], req.Timeout)
+		extended := now.Add(time.Hour)
+		sandbox.Timeout = req.Timeout
+		sandbox.TimeoutAt = &extended
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(sandbox)
+}
+
+func (s *keepAliveServer) calls(id string) []int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]int(nil), s.timeouts[id]...)
+}
+
+func TestKeepAliveExtendsBeforeExpiry(t *testing.T) {
+	backend := &keepAliveServer{createdAt: time.Now(), timeouts: make(map[string][]int)}
+	server := httptest.NewServer(backend)
+	defer server.Close()
+
+	sandboxClient := NewClient(client.NewClient(server.URL, "test-api-key"))
+	ctx, cancel := context.WithCancel(context.Background())
+
+	errs := KeepAlive(ctx, sandboxClient, "sbx-1", KeepAliveOptions{
+		Margin:    time.Minute,
+		Extension: 5 * time.Minute,
+		Interval:  10 * time.Millisecond,
+	})
+
+	deadline := time.Now().Add(2 * time.Second)
+	for len(backend.calls("sbx-1")) == 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	cancel()
+	for err := range errs {
+		t.Errorf("Unexpected keepalive error: %v", err)
+	}
+
+	calls := backend.calls("sbx-1")
+	if len(calls) != 1 {
+		t.Fatalf("Expected 1 SetTimeout call, got %d", len(calls))
+	}
+	// 300s current timeout plus roughly 290s to move TimeoutAt from now+10s to now+5m
+	if calls[0] < 585 || calls[0] > 595 {
+		t.Errorf("Expected new timeout around 590, got %d", calls[0])
+	}
+}
+
+func TestKeepAliveMaxLifetime(t *testing.T) {
+	backend := &keepAliveServer{createdAt: time.Now().Add(-time.Hour), timeouts: make(map[string][]int)}
+	server := httptest.NewServer(backend)
+	defer server.Close()
+
+	sandboxClient := NewClient(client.NewClient(server.URL, "test-api-key"))
+
+	errs := KeepAlive(context.Background(), sandboxClient, "sbx-1", KeepAliveOptions{
+		Interval:    10 * time.Millisecond,
+		MaxLifetime: time.Hour,
+	})
+
+	var got []error
+	for err := range errs {
+		got = append(got, err)
+	}
+	if len(got) != 1 || !errors.Is(got[0], ErrMaxLifetimeReached) {
+		t.Fatalf("Expected a single ErrMaxLifetimeReached, got %v", got)
+	}
+	if calls := backend.calls("sbx-1"); len(calls) != 0 {
+		t.Errorf("Expected no SetTimeout calls, got %v", calls)
+	}
+}
+
+func TestKeeperServesMultipleSandboxes(t *testing.T) {
+	backend := &keepAliveServer{createdAt: time.Now(), timeouts: make(map[string][]int)}
+	server := httptest.NewServer(backend)
+	defer server.Close()
+
+	sandboxClient := NewClient(client.NewClient(server.URL, "test-api-key"))
+	keeper := NewKeeper(sandboxClient, KeepAliveOptions{Interval: 10 * time.Millisecond})
+	keeper.Add("sbx-1")
+	keeper.Add("sbx-2")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		keeper.Run(ctx)
+		close(done)
+	}()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for (len(backend.calls("sbx-1")) == 0 || len(backend.calls("sbx-2")) == 0) && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	cancel()
+	<-done
+
+	for _, id := range []string{"sbx-1", "sbx-2"} {
+		if len(backend.calls(id)) != 1 {
+			t.Errorf("Expected 1 SetTimeout call for %s, got %d", id, len(backend.calls(id)))
+		}
+	}
+}
+
+func TestKeeperRechecksStatusBeforeExtending(t *testing.T) {
+	backend := &keepAliveServer{createdAt: time.Now(), timeouts: make(map[string][]int), status: make(map[string]string)}
+	server := httptest.NewServer(backend)
+	defer server.Close()
+
+	sandboxClient := NewClient(client.NewClient(server.URL, "test-api-key"))
+	// A long margin makes every tick due for an extension
+	keeper := NewKeeper(sandboxClient, KeepAliveOptions{Margin: 2 * time.Hour, Interval: 10 * time.Millisecond})
+	keeper.Add("sbx-1")
+	keeper.Add("sbx-2")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go keeper.Run(ctx)
+
+	deadline := time.Now().Add(2 * time.Second)
+	for len(backend.calls("sbx-1")) == 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	// Stopped elsewhere: terminated sandboxes are dropped, paused ones are watched but not extended
+	backend.mu.Lock()
+	backend.status["sbx-1"] = models.StatusTerminated
+	backend.status["sbx-2"] = models.StatusPaused
+	backend.mu.Unlock()
+	extended := len(backend.calls("sbx-2"))
+
+	select {
+	case kerr := <-keeper.Errors():
+		if kerr.SandboxID != "sbx-1" || !errors.Is(kerr, ErrSandboxNotRunning) {
+			t.Errorf("Unexpected error %v", kerr)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Terminated sandbox was not noticed")
+	}
+	time.Sleep(50 * time.Millisecond)
+	if keeper.Len() != 1 {
+		t.Errorf("Expected only the paused sandbox to be kept, got %d", keeper.Len())
+	}
+	if calls := backend.calls("sbx-2"); len(calls) > extended+1 {
+		t.Errorf("Paused sandbox extended %d more times", len(calls)-extended)
+	}
+}
@@ -0,0 +1,100 @@
+package sandboxes
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/scalebox/scalebox-sdk-golang/labels"
+	"github.com/scalebox/scalebox-sdk-golang/models"
+)
+
+// AddLabels adds or overwrites metadata labels, leaving other metadata untouched.
+// Keys and values are checked with labels.ValidateKey and labels.ValidateValue so they can be selected on;
+// invalid labels are returned as *models.ValidationError.
+func (c *Client) AddLabels(ctx context.Context, sandboxID string, set map[string]string) (*models.Sandbox, error) {
+	if err := validateLabels(set); err != nil {
+		return nil, err
+	}
+
+	path := fmt.Sprintf("/v1/sandboxes/%s/labels", sandboxID)
+	resp, err := c.baseClient.DoRequest(ctx, "POST", path, models.AddLabelsRequest{Labels: set}, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var sandbox models.Sandbox
+	if err := c.baseClient.ParseResponse(resp, &sandbox); err != nil {
+		return nil, err
+	}
+
+	return &sandbox, nil
+}
+
+// RemoveLabels removes metadata labels. Keys that are not present are ignored.
+func (c *Client) RemoveLabels(ctx context.Context, sandboxID string, keys ...string) (*models.Sandbox, error) {
+	if len(keys) == 0 {
+		return nil, &models.ValidationError{Errors: []models.FieldError{{Field: "keys", Message: "at least one key is required"}}}
+	}
+	var errs []models.FieldError
+	for _, k := range keys {
+		if err := labels.ValidateKey(k); err != nil {
+			errs = append(errs, models.FieldError{Field: "keys", Message: err.Error()})
+		}
+	}
+	if len(errs) > 0 {
+		return nil, &models.ValidationError{Errors: errs}
+	}
+
+	path := fmt.Sprintf("/v1/sandboxes/%s/labels", sandboxID)
+	queryParams := map[string]string{"keys": strings.Join(keys, ",")}
+	resp, err := c.baseClient.DoRequest(ctx, "DELETE", path, nil, queryParams)
+	if err != nil {
+		return nil, err
+	}
+
+	var sandbox models.Sandbox
+	if err := c.baseClient.ParseResponse(resp, &sandbox); err != nil {
+		return nil, err
+	}
+
+	return &sandbox, nil
+}
+
+func validateLabels(set map[string]string) error {
+	if len(set) == 0 {
+		return &models.ValidationError{Errors: []models.FieldError{{Field: "labels", Message: "at least one label is required"}}}
+	}
+	keys := make([]string, 0, len(set))
+	for k := range set {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var errs []models.FieldError
+	for _, k := range keys {
+		if err := labels.ValidateKey(k); err != nil {
+			errs = append(errs, models.FieldError{Field: "labels", Message: err.Error()})
+			continue
+		}
+		if err := labels.ValidateValue(set[k]); err != nil {
+			errs = append(errs, models.FieldError{Field: "labels." + k, Message: err.Error()})
+		}
+	}
+	if len(errs) > 0 {
+		return &models.ValidationError{Errors: errs}
+	}
+	return nil
+}
+
+// filterSandboxes keeps the sandboxes whose metadata matches the selector
+func filterSandboxes(list []models.Sandbox, selector labels.Selector) []models.Sandbox {
+	matched := list[:0]
+	for _, sb := range list {
+		if selector.Matches(sb.Metadata) {
+			matched = append(matched, sb)
+		}
+	}
+	return matched
+}
@@ -0,0 +1,98 @@
+package sandboxes
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/scalebox/scalebox-sdk-golang/client"
+	"github.com/scalebox/scalebox-sdk-golang/models"
+)
+
+func TestAddAndRemoveLabels(t *testing.T) {
+	metadata := map[string]string{"owner": "alice"}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/sandboxes/sbx-1/labels" {
+			t.Errorf("Unexpected path %s", r.URL.Path)
+		}
+		switch r.Method {
+		case "POST":
+			var req models.AddLabelsRequest
+			json.NewDecoder(r.Body).Decode(&req)
+			for k, v := range req.Labels {
+				metadata[k] = v
+			}
+		case "DELETE":
+			if got := r.URL.Query().Get("keys"); got != "team,env" {
+				t.Errorf("Expected keys=team,env, got %q", got)
+			}
+			delete(metadata, "team")
+			delete(metadata, "env")
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(models.Sandbox{SandboxID: "sbx-1", Metadata: metadata})
+	}))
+	defer server.Close()
+
+	sandboxClient := NewClient(client.NewClient(server.URL, "test-api-key"))
+	ctx := context.Background()
+
+	sandbox, err := sandboxClient.AddLabels(ctx, "sbx-1", map[string]string{"team": "ml", "env": "ci"})
+	if err != nil {
+		t.Fatalf("AddLabels failed: %v", err)
+	}
+	if sandbox.Metadata["team"] != "ml" || sandbox.Metadata["owner"] != "alice" {
+		t.Errorf("Unexpected metadata after AddLabels: %v", sandbox.Metadata)
+	}
+
+	sandbox, err = sandboxClient.RemoveLabels(ctx, "sbx-1", "team", "env")
+	if err != nil {
+		t.Fatalf("RemoveLabels failed: %v", err)
+	}
+	if len(sandbox.Metadata) != 1 {
+		t.Errorf("Unexpected metadata after RemoveLabels: %v", sandbox.Metadata)
+	}
+
+	if _, err := sandboxClient.AddLabels(ctx, "sbx-1", map[string]string{"bad key": "x"}); err == nil {
+		t.Error("Expected invalid label key to be rejected")
+	} else if _, ok := err.(*models.ValidationError); !ok {
+		t.Errorf("Expected *models.ValidationError, got %T", err)
+	}
+	if _, err := sandboxClient.RemoveLabels(ctx, "sbx-1"); err == nil {
+		t.Error("Expected RemoveLabels without keys to be rejected")
+	}
+}
+
+func TestListLabelSelector(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Query().Get("label_selector"); got != "team=ml,env in (ci,dev),!ephemeral" {
+			t.Errorf("Unexpected label_selector %q", got)
+		}
+		// Simulate a server that ignores the selector
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(models.SandboxListResponse{Sandboxes: []models.Sandbox{
+			{SandboxID: "sbx-1", Metadata: map[string]string{"team": "ml", "env": "ci"}},
+			{SandboxID: "sbx-2", Metadata: map[string]string{"team": "ml", "env": "prod"}},
+			{SandboxID: "sbx-3", Metadata: map[string]string{"team": "ml", "env": "dev", "ephemeral": "true"}},
+			{SandboxID: "sbx-4"},
+		}})
+	}))
+	defer server.Close()
+
+	sandboxClient := NewClient(client.NewClient(server.URL, "test-api-key"))
+	result, err := sandboxClient.List(context.Background(), &models.ListSandboxesOptions{
+		LabelSelector: "team = ml, env in (ci, dev), !ephemeral",
+	})
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(result.Sandboxes) != 1 || result.Sandboxes[0].SandboxID != "sbx-1" {
+		t.Errorf("Expected only sbx-1 to match, got %+v", result.Sandboxes)
+	}
+
+	if _, err := sandboxClient.List(context.Background(), &models.ListSandboxesOptions{LabelSelector: "env in ci"}); err == nil {
+		t.Error("Expected invalid selector to be rejected")
+	}
+}
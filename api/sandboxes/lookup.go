@@ -0,0 +1,186 @@
+package sandboxes
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/scalebox/scalebox-sdk-golang/client"
+	"github.com/scalebox/scalebox-sdk-golang/labels"
+	"github.com/scalebox/scalebox-sdk-golang/models"
+)
+
+// MetadataClientKey is the metadata label Ensure stores the caller's stable key under
+const MetadataClientKey = "scalebox.client-key"
+
+// lookupPageSize is the page size used when scanning all sandboxes
+const lookupPageSize = 100
+
+// EnsureAction reports what Ensure did to produce the sandbox
+type EnsureAction string
+
+// Ensure actions
+const (
+	EnsureCreated   EnsureAction = "created"   // No sandbox had the key, a new one was created
+	EnsureExisting  EnsureAction = "existing"  // A running or starting sandbox had the key
+	EnsureResumed   EnsureAction = "resumed"   // A paused sandbox had the key and was resumed
+	EnsureRecreated EnsureAction = "recreated" // Only terminated or failed sandboxes had the key, a new one was created
+)
+
+// EnsureResult is the outcome of Ensure
+type EnsureResult struct {
+	Sandbox  *models.Sandbox
+	Action   EnsureAction
+	Replaced string // ID of the terminated sandbox that was replaced, for EnsureRecreated
+}
+
+// GetByName returns the live sandbox created with the given name.
+// The backend appends a random "-suffix" to requested names, so a sandbox matches when its
+// name equals name or is name followed by '-' and a single suffix without further dashes,
+// e.g. "web" matches "web-ab12" but not "web-server-ab12"; an exact match wins.
+// It returns a 404 *client.APIError when nothing matches and *AmbiguousError when several do.
+func (c *Client) GetByName(ctx context.Context, name string) (*models.Sandbox, error) {
+	if name == "" {
+		return nil, &models.ValidationError{Errors: []models.FieldError{{Field: "name", Message: "must not be empty"}}}
+	}
+	all, err := c.ListAll(ctx, models.ListSandboxesOptions{Search: name})
+	if err != nil {
+		return nil, err
+	}
+
+	var exact, suffixed []models.Sandbox
+	for _, sb := range all {
+		switch {
+		case sb.Name == name:
+			exact = append(exact, sb)
+		case isSuffixed(sb.Name, name):
+			suffixed = append(suffixed, sb)
+		}
+	}
+	if len(exact) > 0 {
+		return single(fmt.Sprintf("name %q", name), exact)
+	}
+	return single(fmt.Sprintf("name %q", name), suffixed)
+}
+
+// isSuffixed reports whether got is name plus a backend-generated "-suffix"
+func isSuffixed(got, name string) bool {
+	suffix, ok := strings.CutPrefix(got, name+"-")
+	return ok && suffix != "" && !strings.Contains(suffix, "-")
+}
+
+// FindOne returns the single live sandbox whose metadata matches the label selector.
+// It returns a 404 *client.APIError when nothing matches and *AmbiguousError when several do.
+func (c *Client) FindOne(ctx context.Context, selector string) (*models.Sandbox, error) {
+	if _, err := labels.Parse(selector); err != nil {
+		return nil, err
+	}
+	all, err := c.ListAll(ctx, models.ListSandboxesOptions{LabelSelector: selector})
+	if err != nil {
+		return nil, err
+	}
+	return single(fmt.Sprintf("selector %q", selector), all)
+}
+
+// Ensure returns the sandbox identified by a stable client key, creating it if needed.
+// The key is stored in metadata under MetadataClientKey, so the same sandbox is found again
+// after a restart even though the backend renames it. A paused sandbox is resumed; if every
+// sandbox with the key is terminated or failed, a new one is created from req.
+//
+// Ensure is not atomic: two callers racing with the same key can both create a sandbox,
+// after which Ensure reports *AmbiguousError until one of them is deleted.
+func (c *Client) Ensure(ctx context.Context, key string, req models.CreateSandboxRequest) (*EnsureResult, error) {
+	if key == "" {
+		return nil, &models.ValidationError{Errors: []models.FieldError{{Field: "key", Message: "must not be empty"}}}
+	}
+	if err := labels.ValidateValue(key); err != nil {
+		return nil, &models.ValidationError{Errors: []models.FieldError{{Field: "key", Message: err.Error()}}}
+	}
+
+	selector := labels.SelectorFromSet(map[string]string{MetadataClientKey: key})
+	all, err := c.ListAll(ctx, models.ListSandboxesOptions{LabelSelector: selector.String(), IncludeTerminated: true})
+	if err != nil {
+		return nil, err
+	}
+
+	var live []models.Sandbox
+	replaced := ""
+	for _, sb := range all {
+		if models.IsTerminalStatus(sb.Status) || sb.Status == models.StatusTerminating {
+			replaced = sb.SandboxID
+			continue
+		}
+		live = append(live, sb)
+	}
+	if len(live) > 1 {
+		return nil, ambiguous(fmt.Sprintf("client key %q", key), live)
+	}
+
+	if len(live) == 1 {
+		sb := live[0]
+		switch sb.Status {
+		case models.StatusPausing:
+			if _, err := c.WaitForStatus(ctx, sb.SandboxID, models.StatusPaused, 0); err != nil {
+				return nil, err
+			}
+			fallthrough
+		case models.StatusPaused:
+			resumed, err := c.Resume(ctx, sb.SandboxID)
+			if err != nil {
+				return nil, err
+			}
+			return &EnsureResult{Sandbox: resumed, Action: EnsureResumed}, nil
+		}
+		return &EnsureResult{Sandbox: &sb, Action: EnsureExisting}, nil
+	}
+
+	req = req.Clone()
+	if req.Metadata == nil {
+		req.Metadata = make(map[string]string)
+	}
+	req.Metadata[MetadataClientKey] = key
+	created, err := c.Create(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if replaced != "" {
+		return &EnsureResult{Sandbox: created, Action: EnsureRecreated, Replaced: replaced}, nil
+	}
+	return &EnsureResult{Sandbox: created, Action: EnsureCreated}, nil
+}
+
+// ListAll pages through List and returns every matching sandbox.
+// The Limit and Offset options are ignored.
+func (c *Client) ListAll(ctx context.Context, opts models.ListSandboxesOptions) ([]models.Sandbox, error) {
+	var all []models.Sandbox
+	opts.Limit = lookupPageSize
+	for opts.Offset = 0; ; opts.Offset += lookupPageSize {
+		result, fetched, err := c.list(ctx, &opts)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, result.Sandboxes...)
+		if fetched < lookupPageSize {
+			return all, nil
+		}
+	}
+}
+
+// single returns the only sandbox in list, or a not found or ambiguous error
+func single(query string, list []models.Sandbox) (*models.Sandbox, error) {
+	switch len(list) {
+	case 0:
+		return nil, &client.APIError{StatusCode: 404, Message: fmt.Sprintf("no sandbox matches %s", query)}
+	case 1:
+		return &list[0], nil
+	}
+	return nil, ambiguous(query, list)
+}
+
+func ambiguous(query string, list []models.Sandbox) *AmbiguousError {
+	ids := make([]string, len(list))
+	for i, sb := range list {
+		ids[i] = sb.SandboxID
+	}
+	return &AmbiguousError{Query: query, SandboxIDs: ids}
+}
@@ -0,0 +1,214 @@
+package sandboxes
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/scalebox/scalebox-sdk-golang/client"
+	"github.com/scalebox/scalebox-sdk-golang/models"
+)
+
+// lookupServer is a fake API that lists, creates and resumes sandboxes.
+// Like the real server it ignores label selectors it does not understand, so filtering is client-side.
+type lookupServer struct {
+	mu        sync.Mutex
+	sandboxes []models.Sandbox
+	creates   int
+	resumes   int
+}
+
+func (s *lookupServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var resp interface{}
+	switch {
+	case r.Method == "GET" && r.URL.Path == "/v1/sandboxes":
+		q := r.URL.Query()
+		var matched []models.Sandbox
+		for _, sb := range s.sandboxes {
+			if models.IsTerminalStatus(sb.Status) && q.Get("include_terminated") != "true" {
+				continue
+			}
+			if search := q.Get("search"); search != "" && !strings.Contains(sb.Name, search) {
+				continue
+			}
+			matched = append(matched, sb)
+		}
+		limit, _ := strconv.Atoi(q.Get("limit"))
+		offset, _ := strconv.Atoi(q.Get("offset"))
+		if offset > len(matched) {
+			offset = len(matched)
+		}
+		if limit > 0 && offset+limit < len(matched) {
+			matched = matched[:offset+limit]
+		}
+		resp = models.SandboxListResponse{Sandboxes: matched[offset:]}
+	case r.Method == "POST" && r.URL.Path == "/v1/sandboxes":
+		var req models.CreateSandboxRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		s.creates++
+		sb := models.Sandbox{
+			SandboxID: fmt.Sprintf("sbx-new-%d", s.creates),
+			Name:      req.Name + "-x" + strconv.Itoa(s.creates),
+			Status:    models.StatusRunning,
+			Metadata:  req.Metadata,
+		}
+		s.sandboxes = append(s.sandboxes, sb)
+		resp = sb
+	case r.Method == "POST" && strings.HasSuffix(r.URL.Path, "/resume"):
+		id := strings.Split(r.URL.Path, "/")[3]
+		for i := range s.sandboxes {
+			if s.sandboxes[i].SandboxID == id {
+				s.resumes++
+				s.sandboxes[i].Status = models.StatusRunning
+				resp = s.sandboxes[i]
+			}
+		}
+	default:
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+func keyed(id, key, status string) models.Sandbox {
+	return models.Sandbox{SandboxID: id, Name: "worker-" + id, Status: status, Metadata: map[string]string{MetadataClientKey: key}}
+}
+
+func TestGetByName(t *testing.T) {
+	fake := &lookupServer{sandboxes: []models.Sandbox{
+		{SandboxID: "sbx-1", Name: "build-a1b2", Status: models.StatusRunning},
+		{SandboxID: "sbx-2", Name: "build-cache-c3d4", Status: models.StatusRunning},
+		{SandboxID: "sbx-3", Name: "test-e5f6", Status: models.StatusRunning},
+		{SandboxID: "sbx-4", Name: "test-0000", Status: models.StatusPaused},
+	}}
+	// Pad the list so the lookup has to page
+	for i := 0; i < lookupPageSize; i++ {
+		fake.sandboxes = append(fake.sandboxes, models.Sandbox{SandboxID: fmt.Sprintf("sbx-pad-%d", i), Name: "build-cache-pad", Status: models.StatusRunning})
+	}
+	fake.sandboxes = append(fake.sandboxes,
+		models.Sandbox{SandboxID: "sbx-5", Name: "build", Status: models.StatusRunning},
+		models.Sandbox{SandboxID: "sbx-6", Name: "web-server-ab12", Status: models.StatusRunning},
+		models.Sandbox{SandboxID: "sbx-7", Name: "web-9f8e", Status: models.StatusRunning},
+	)
+	server := httptest.NewServer(fake)
+	defer server.Close()
+	sandboxClient := NewClient(client.NewClient(server.URL, "test-api-key"))
+	ctx := context.Background()
+
+	sb, err := sandboxClient.GetByName(ctx, "build")
+	if err != nil || sb.SandboxID != "sbx-5" {
+		t.Fatalf("Expected exact match on the second page to win, got %v, %v", sb, err)
+	}
+
+	if _, err := sandboxClient.GetByName(ctx, "test"); err == nil {
+		t.Fatal("Expected ambiguous names to fail")
+	} else if amb, ok := err.(*AmbiguousError); !ok || len(amb.SandboxIDs) != 2 {
+		t.Errorf("Expected *AmbiguousError with 2 sandboxes, got %v", err)
+	}
+
+	// "web" must not pick up "web-server-<suffix>"
+	for name, want := range map[string]string{"web": "sbx-7", "web-server": "sbx-6"} {
+		if sb, err := sandboxClient.GetByName(ctx, name); err != nil || sb.SandboxID != want {
+			t.Errorf("GetByName(%q): expected %s, got %v, %v", name, want, sb, err)
+		}
+	}
+
+	if _, err := sandboxClient.GetByName(ctx, "deploy"); !client.IsNotFound(err) {
+		t.Errorf("Expected not found, got %v", err)
+	}
+}
+
+func TestFindOne(t *testing.T) {
+	fake := &lookupServer{sandboxes: []models.Sandbox{
+		{SandboxID: "sbx-1", Status: models.StatusRunning, Metadata: map[string]string{"team": "ml", "env": "ci"}},
+		{SandboxID: "sbx-2", Status: models.StatusRunning, Metadata: map[string]string{"team": "ml", "env": "dev"}},
+	}}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+	sandboxClient := NewClient(client.NewClient(server.URL, "test-api-key"))
+	ctx := context.Background()
+
+	sb, err := sandboxClient.FindOne(ctx, "team=ml,env=ci")
+	if err != nil || sb.SandboxID != "sbx-1" {
+		t.Fatalf("Expected sbx-1, got %v, %v", sb, err)
+	}
+	var amb *AmbiguousError
+	if _, err := sandboxClient.FindOne(ctx, "team=ml"); !errors.As(err, &amb) {
+		t.Errorf("Expected *AmbiguousError, got %v", err)
+	}
+	if _, err := sandboxClient.FindOne(ctx, "team=ops"); !client.IsNotFound(err) {
+		t.Errorf("Expected not found, got %v", err)
+	}
+	if _, err := sandboxClient.FindOne(ctx, "team in ml"); err == nil {
+		t.Error("Expected invalid selector to be rejected")
+	}
+}
+
+func TestEnsure(t *testing.T) {
+	fake := &lookupServer{sandboxes: []models.Sandbox{
+		keyed("sbx-run", "run", models.StatusRunning),
+		keyed("sbx-paused", "paused", models.StatusPaused),
+		keyed("sbx-dead", "dead", models.StatusTerminated),
+		keyed("sbx-dup-1", "dup", models.StatusRunning),
+		keyed("sbx-dup-2", "dup", models.StatusStarting),
+	}}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+	sandboxClient := NewClient(client.NewClient(server.URL, "test-api-key"))
+	ctx := context.Background()
+	req := models.CreateSandboxRequest{Name: "worker", Metadata: map[string]string{"team": "ml"}}
+
+	tests := []struct {
+		key      string
+		action   EnsureAction
+		id       string
+		replaced string
+	}{
+		{"run", EnsureExisting, "sbx-run", ""},
+		{"paused", EnsureResumed, "sbx-paused", ""},
+		{"dead", EnsureRecreated, "sbx-new-1", "sbx-dead"},
+		{"fresh", EnsureCreated, "sbx-new-2", ""},
+		// Calling again finds the sandboxes created above
+		{"dead", EnsureExisting, "sbx-new-1", ""},
+		{"fresh", EnsureExisting, "sbx-new-2", ""},
+	}
+	for _, tt := range tests {
+		result, err := sandboxClient.Ensure(ctx, tt.key, req)
+		if err != nil {
+			t.Fatalf("Ensure(%q) failed: %v", tt.key, err)
+		}
+		if result.Action != tt.action || result.Sandbox.SandboxID != tt.id || result.Replaced != tt.replaced {
+			t.Errorf("Ensure(%q) = %s %s replaced %q, want %s %s replaced %q",
+				tt.key, result.Action, result.Sandbox.SandboxID, result.Replaced, tt.action, tt.id, tt.replaced)
+		}
+	}
+	if fake.creates != 2 || fake.resumes != 1 {
+		t.Errorf("Expected 2 creates and 1 resume, got %d and %d", fake.creates, fake.resumes)
+	}
+	created := fake.sandboxes[len(fake.sandboxes)-1]
+	if created.Metadata[MetadataClientKey] != "fresh" || created.Metadata["team"] != "ml" {
+		t.Errorf("Expected client key merged into metadata, got %v", created.Metadata)
+	}
+	if _, ok := req.Metadata[MetadataClientKey]; ok {
+		t.Error("Ensure must not modify the caller's request")
+	}
+
+	if _, err := sandboxClient.Ensure(ctx, "dup", req); err == nil {
+		t.Error("Expected duplicate keys to be reported")
+	} else if _, ok := err.(*AmbiguousError); !ok {
+		t.Errorf("Expected *AmbiguousError, got %v", err)
+	}
+	if _, err := sandboxClient.Ensure(ctx, "bad key", req); err == nil {
+		t.Error("Expected invalid key to be rejected")
+	}
+}
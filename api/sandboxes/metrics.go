@@ -0,0 +1,179 @@
+package sandboxes
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/scalebox/scalebox-sdk-golang/models"
+)
+
+// GetMetricsRange defaults
+const (
+	DefaultMetricsMaxPoints        = 1000
+	DefaultMetricsPointsPerRequest = 250
+	DefaultMetricsConcurrency      = 4
+)
+
+// MetricsRangeOptions configures GetMetricsRange
+type MetricsRangeOptions struct {
+	// Step is the resolution, rounded up to whole seconds. When zero, a step that keeps
+	// the range within MaxPoints is chosen; see MetricsStep.
+	Step time.Duration
+	// MaxPoints bounds the points in the range when Step is chosen, defaults to DefaultMetricsMaxPoints
+	MaxPoints int
+	// PointsPerRequest bounds the points asked for in one GetMetrics call, defaults to
+	// DefaultMetricsPointsPerRequest. Longer ranges are split into several calls.
+	PointsPerRequest int
+	// Concurrency is the number of calls in flight at once, defaults to DefaultMetricsConcurrency
+	Concurrency int
+	// TimeFormat encodes the start and end of each call, defaults to models.TimeFormatRFC3339
+	TimeFormat models.TimeFormat
+}
+
+// GetMetricsRange retrieves the metrics of a sandbox between start and end at a bounded
+// resolution. The range is split into windows of PointsPerRequest points fetched concurrently;
+// their points are merged in time order with duplicates removed. The other response fields
+// are taken from the latest window. If any call fails, the others are cancelled and the
+// first error is returned.
+func (c *Client) GetMetricsRange(ctx context.Context, sandboxID string, start, end time.Time, opts MetricsRangeOptions) (*models.SandboxMetricsResponse, error) {
+	if err := opts.validate(start, end); err != nil {
+		return nil, err
+	}
+	if opts.MaxPoints == 0 {
+		opts.MaxPoints = DefaultMetricsMaxPoints
+	}
+	if opts.PointsPerRequest == 0 {
+		opts.PointsPerRequest = DefaultMetricsPointsPerRequest
+	}
+	if opts.Concurrency == 0 {
+		opts.Concurrency = DefaultMetricsConcurrency
+	}
+
+	step := MetricsStep(end.Sub(start), opts.MaxPoints)
+	if opts.Step > 0 {
+		step = roundUpSecond(opts.Step)
+	}
+	windows := metricsWindows(start, end, step*time.Duration(opts.PointsPerRequest-1))
+	stepSeconds := int(step / time.Second)
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	responses := make([]*models.SandboxMetricsResponse, len(windows))
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		firstErr error
+	)
+	next := make(chan int, len(windows))
+	for i := range windows {
+		next <- i
+	}
+	close(next)
+	workers := opts.Concurrency
+	if workers > len(windows) {
+		workers = len(windows)
+	}
+	// Workers take windows in order, so no window starts after a failure is seen
+	for n := 0; n < workers; n++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				if ctx.Err() != nil {
+					return
+				}
+				resp, err := c.GetMetrics(ctx, sandboxID, &models.GetSandboxMetricsOptions{
+					Start:      &windows[i][0],
+					End:        &windows[i][1],
+					Step:       &stepSeconds,
+					TimeFormat: opts.TimeFormat,
+				})
+				mu.Lock()
+				if err != nil && firstErr == nil {
+					firstErr = err
+					cancel()
+				}
+				responses[i] = resp
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	result := *responses[len(responses)-1]
+	windowPoints := make([][]models.MetricsDataPoint, len(responses))
+	for i, resp := range responses {
+		windowPoints[i] = resp.Metrics
+	}
+	result.Metrics = []models.MetricsDataPoint{}
+	for _, p := range models.MergeMetrics(windowPoints...) {
+		if !p.Timestamp.Before(start) && !p.Timestamp.After(end) {
+			result.Metrics = append(result.Metrics, p)
+		}
+	}
+	return &result, nil
+}
+
+// MetricsStep returns a whole-second step that covers span in at most maxPoints points,
+// counting the points at both ends
+func MetricsStep(span time.Duration, maxPoints int) time.Duration {
+	if maxPoints < 2 {
+		return roundUpSecond(span)
+	}
+	return roundUpSecond(span / time.Duration(maxPoints-1))
+}
+
+// validate checks the range and options before any call is made
+func (o MetricsRangeOptions) validate(start, end time.Time) error {
+	var errs []models.FieldError
+	if !start.Before(end) {
+		errs = append(errs, models.FieldError{Field: "end", Message: "must be after start"})
+	}
+	if o.Step < 0 {
+		errs = append(errs, models.FieldError{Field: "step", Message: "must not be negative"})
+	}
+	if o.MaxPoints < 0 || o.MaxPoints == 1 {
+		errs = append(errs, models.FieldError{Field: "max_points", Message: "must be at least 2"})
+	}
+	if o.PointsPerRequest < 0 || o.PointsPerRequest == 1 {
+		errs = append(errs, models.FieldError{Field: "points_per_request", Message: "must be at least 2"})
+	}
+	if o.Concurrency < 0 {
+		errs = append(errs, models.FieldError{Field: "concurrency", Message: "must not be negative"})
+	}
+	if len(errs) > 0 {
+		return &models.ValidationError{Errors: errs}
+	}
+	return nil
+}
+
+// metricsWindows splits [start, end] into consecutive windows of at most size.
+// Adjacent windows share their boundary; the duplicate point is removed when merging.
+func metricsWindows(start, end time.Time, size time.Duration) [][2]time.Time {
+	var windows [][2]time.Time
+	for from := start; from.Before(end); from = from.Add(size) {
+		to := from.Add(size)
+		if to.After(end) {
+			to = end
+		}
+		windows = append(windows, [2]time.Time{from, to})
+	}
+	return windows
+}
+
+func roundUpSecond(d time.Duration) time.Duration {
+	if d < time.Second {
+		return time.Second
+	}
+	if r := d % time.Second; r != 0 {
+		d += time.Second - r
+	}
+	return d
+}
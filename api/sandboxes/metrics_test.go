@@ -0,0 +1,141 @@
+package sandboxes
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/scalebox/scalebox-sdk-golang/client"
+	"github.com/scalebox/scalebox-sdk-golang/models"
+)
+
+// metricsServer returns one point per step between the requested start and end, inclusive
+type metricsServer struct {
+	mu       sync.Mutex
+	queries  []map[string]string
+	failFrom time.Time // Fail windows starting at this time, if set
+}
+
+func (s *metricsServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	s.mu.Lock()
+	s.queries = append(s.queries, map[string]string{"start": q.Get("start"), "end": q.Get("end"), "step": q.Get("step")})
+	s.mu.Unlock()
+
+	start, end := parseQueryTime(q.Get("start")), parseQueryTime(q.Get("end"))
+	step, _ := strconv.Atoi(q.Get("step"))
+	w.Header().Set("Content-Type", "application/json")
+	if !s.failFrom.IsZero() && start.Equal(s.failFrom) {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "too many points"})
+		return
+	}
+	resp := models.SandboxMetricsResponse{SandboxID: "sbx-1", Status: models.StatusRunning, Timestamp: end, Metrics: []models.MetricsDataPoint{}}
+	for t := start; !t.After(end); t = t.Add(time.Duration(step) * time.Second) {
+		resp.Metrics = append(resp.Metrics, models.MetricsDataPoint{Timestamp: t, CPUUsedPct: float64(t.Unix() % 100)})
+	}
+	json.NewEncoder(w).Encode(resp)
+}
+
+func parseQueryTime(v string) time.Time {
+	if sec, err := strconv.ParseInt(v, 10, 64); err == nil {
+		return time.Unix(sec, 0).UTC()
+	}
+	t, _ := time.Parse(time.RFC3339, v)
+	return t
+}
+
+func TestGetMetricsRange(t *testing.T) {
+	fake := &metricsServer{}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+	c := NewClient(client.NewClient(server.URL, "test-api-key"))
+	ctx := context.Background()
+	end := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	start := end.Add(-7 * 24 * time.Hour)
+
+	resp, err := c.GetMetricsRange(ctx, "sbx-1", start, end, MetricsRangeOptions{})
+	if err != nil {
+		t.Fatalf("GetMetricsRange failed: %v", err)
+	}
+	// 7 days in at most 1000 points needs a 606s step, and windows of 249 steps need 5 calls
+	if len(fake.queries) != 5 {
+		t.Errorf("Expected 5 calls, got %d", len(fake.queries))
+	}
+	for _, q := range fake.queries {
+		if q["step"] != "606" {
+			t.Errorf("Expected step 606, got %s", q["step"])
+		}
+	}
+	points := resp.Metrics
+	if len(points) > DefaultMetricsMaxPoints || len(points) < DefaultMetricsMaxPoints-5 {
+		t.Errorf("Expected close to %d points, got %d", DefaultMetricsMaxPoints, len(points))
+	}
+	for i := 1; i < len(points); i++ {
+		if !points[i].Timestamp.After(points[i-1].Timestamp) {
+			t.Fatalf("Points %d and %d out of order or duplicated: %s, %s", i-1, i, points[i-1].Timestamp, points[i].Timestamp)
+		}
+	}
+	if !points[0].Timestamp.Equal(start) || resp.SandboxID != "sbx-1" || !resp.Timestamp.Equal(end) {
+		t.Errorf("Unexpected response %s %s, first point %s", resp.SandboxID, resp.Timestamp, points[0].Timestamp)
+	}
+
+	// An explicit step and Unix timestamps
+	fake.queries = nil
+	resp, err = c.GetMetricsRange(ctx, "sbx-1", end.Add(-time.Hour), end, MetricsRangeOptions{Step: 90 * time.Second, TimeFormat: models.TimeFormatUnix})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fake.queries) != 1 || fake.queries[0]["start"] != strconv.FormatInt(end.Add(-time.Hour).Unix(), 10) || fake.queries[0]["step"] != "90" {
+		t.Errorf("Unexpected queries %v", fake.queries)
+	}
+	if len(resp.Metrics) != 41 {
+		t.Errorf("Expected 41 points, got %d", len(resp.Metrics))
+	}
+}
+
+func TestGetMetricsRangeErrors(t *testing.T) {
+	end := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	start := end.Add(-24 * time.Hour)
+	fake := &metricsServer{failFrom: start}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+	c := NewClient(client.NewClient(server.URL, "test-api-key"))
+
+	_, err := c.GetMetricsRange(context.Background(), "sbx-1", start, end, MetricsRangeOptions{PointsPerRequest: 100, Concurrency: 1})
+	if client.StatusCode(err) != http.StatusBadRequest {
+		t.Errorf("Expected the failed window's error, got %v", err)
+	}
+	if len(fake.queries) != 1 {
+		t.Errorf("Expected the remaining windows to be cancelled, got %d calls", len(fake.queries))
+	}
+
+	_, err = c.GetMetricsRange(context.Background(), "sbx-1", end, start, MetricsRangeOptions{MaxPoints: 1, PointsPerRequest: -1})
+	verr, ok := err.(*models.ValidationError)
+	if !ok || !verr.HasField("end") || !verr.HasField("max_points") || !verr.HasField("points_per_request") {
+		t.Errorf("Expected a validation error, got %v", err)
+	}
+}
+
+func TestMetricsStep(t *testing.T) {
+	tests := []struct {
+		span      time.Duration
+		maxPoints int
+		want      time.Duration
+	}{
+		{5 * time.Minute, 1000, time.Second},
+		{time.Hour, 61, time.Minute},
+		{time.Hour, 60, 62 * time.Second},
+		{7 * 24 * time.Hour, 1000, 606 * time.Second},
+	}
+	for _, tt := range tests {
+		if got := MetricsStep(tt.span, tt.maxPoints); got != tt.want {
+			t.Errorf("MetricsStep(%s, %d) = %s, want %s", tt.span, tt.maxPoints, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,266 @@
+package sandboxes
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/scalebox/scalebox-sdk-golang/models"
+)
+
+// Registry defaults
+const (
+	DefaultShutdownTimeout     = 30 * time.Second
+	DefaultShutdownConcurrency = 8
+)
+
+// ErrRegistryClosed is returned when creating a sandbox through a registry that is shutting down
+var ErrRegistryClosed = errors.New("sandboxes: registry is shutting down")
+
+// RegistryOptions configures a Registry
+type RegistryOptions struct {
+	Policy      TeardownPolicy // For sandboxes created with Registry.Create
+	Timeout     time.Duration  // Deadline for Shutdown, defaults to DefaultShutdownTimeout
+	Concurrency int            // Teardowns run at once during Shutdown, defaults to DefaultShutdownConcurrency
+}
+
+// TeardownError reports a sandbox that could not be torn down during Shutdown
+type TeardownError struct {
+	SandboxID string
+	Err       error
+}
+
+func (e *TeardownError) Error() string {
+	return "teardown " + e.SandboxID + ": " + e.Err.Error()
+}
+
+func (e *TeardownError) Unwrap() error {
+	return e.Err
+}
+
+// ShutdownSummary reports the outcome of Registry.Shutdown
+type ShutdownSummary struct {
+	Total    int
+	TornDown int
+	Failures []*TeardownError
+	Duration time.Duration
+}
+
+// Err returns the joined teardown failures, or nil
+func (s *ShutdownSummary) Err() error {
+	errs := make([]error, len(s.Failures))
+	for i, f := range s.Failures {
+		errs[i] = f
+	}
+	return errors.Join(errs...)
+}
+
+func (s *ShutdownSummary) String() string {
+	msg := fmt.Sprintf("tore down %d of %d sandboxes in %s", s.TornDown, s.Total, s.Duration.Round(time.Millisecond))
+	if len(s.Failures) == 0 {
+		return msg
+	}
+	failed := make([]string, len(s.Failures))
+	for i, f := range s.Failures {
+		failed[i] = f.Error()
+	}
+	return msg + "; failed: " + strings.Join(failed, "; ")
+}
+
+type teardownFunc func(ctx context.Context) error
+
+// Registry tracks sandboxes created by a process so they can all be torn down on shutdown,
+// e.g. when the process receives SIGINT or SIGTERM (see HandleSignals)
+type Registry struct {
+	client *Client
+	opts   RegistryOptions
+
+	mu       sync.Mutex
+	entries  map[string]teardownFunc
+	closed   bool // No new sandboxes are created
+	drained  bool // Shutdown has collected the entries; later ones are torn down immediately
+	inflight sync.WaitGroup
+}
+
+// NewRegistry creates a Registry
+func NewRegistry(c *Client, opts RegistryOptions) *Registry {
+	if opts.Timeout <= 0 {
+		opts.Timeout = DefaultShutdownTimeout
+	}
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = DefaultShutdownConcurrency
+	}
+	return &Registry{client: c, opts: opts, entries: make(map[string]teardownFunc)}
+}
+
+// Create creates a sandbox and tracks it with the registry's policy.
+// It returns ErrRegistryClosed once Shutdown has started.
+func (r *Registry) Create(ctx context.Context, req models.CreateSandboxRequest) (*models.Sandbox, error) {
+	if err := r.begin(); err != nil {
+		return nil, err
+	}
+	defer r.inflight.Done()
+
+	sandbox, err := r.client.Create(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	r.Track(sandbox.SandboxID, r.opts.Policy)
+	return sandbox, nil
+}
+
+// Track adds an existing sandbox to the registry.
+// Sandboxes tracked after Shutdown has collected its work are torn down immediately.
+func (r *Registry) Track(sandboxID string, policy TeardownPolicy) {
+	r.track(sandboxID, func(ctx context.Context) error {
+		return teardown(ctx, r.client, sandboxID, policy)
+	})
+}
+
+// Forget removes a sandbox from the registry without tearing it down
+func (r *Registry) Forget(sandboxID string) {
+	r.mu.Lock()
+	delete(r.entries, sandboxID)
+	r.mu.Unlock()
+}
+
+// Len returns the number of tracked sandboxes
+func (r *Registry) Len() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.entries)
+}
+
+// Shutdown stops new creations and tears down every tracked sandbox, running up to
+// Concurrency teardowns at once and giving up when ctx or the registry Timeout expires.
+// Sandboxes still being created when Shutdown starts are waited for and torn down too.
+func (r *Registry) Shutdown(ctx context.Context) *ShutdownSummary {
+	start := time.Now()
+	ctx, cancel := context.WithTimeout(ctx, r.opts.Timeout)
+	defer cancel()
+
+	r.mu.Lock()
+	r.closed = true
+	r.mu.Unlock()
+
+	creating := make(chan struct{})
+	go func() {
+		r.inflight.Wait()
+		close(creating)
+	}()
+	select {
+	case <-creating:
+	case <-ctx.Done():
+	}
+
+	r.mu.Lock()
+	r.drained = true
+	ids := make([]string, 0, len(r.entries))
+	funcs := make([]teardownFunc, 0, len(r.entries))
+	for id, fn := range r.entries {
+		ids = append(ids, id)
+		funcs = append(funcs, fn)
+	}
+	r.entries = make(map[string]teardownFunc)
+	r.mu.Unlock()
+
+	summary := &ShutdownSummary{Total: len(ids)}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, r.opts.Concurrency)
+	for i := range ids {
+		wg.Add(1)
+		go func(id string, fn teardownFunc) {
+			defer wg.Done()
+			var err error
+			select {
+			case sem <- struct{}{}:
+				err = fn(ctx)
+				<-sem
+			case <-ctx.Done():
+				err = ctx.Err()
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				summary.Failures = append(summary.Failures, &TeardownError{SandboxID: id, Err: err})
+			} else {
+				summary.TornDown++
+			}
+		}(ids[i], funcs[i])
+	}
+	wg.Wait()
+	summary.Duration = time.Since(start)
+	return summary
+}
+
+// HandleSignals shuts the registry down when the process receives one of sigs, SIGINT and SIGTERM by default.
+// The summary is passed to onShutdown, if given, and the signal is then raised again with the
+// registry's handler removed, so the process exits as it would have without the registry.
+// The returned function stops handling signals.
+func (r *Registry) HandleSignals(onShutdown func(*ShutdownSummary), sigs ...os.Signal) (stop func()) {
+	if len(sigs) == 0 {
+		sigs = []os.Signal{os.Interrupt, syscall.SIGTERM}
+	}
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, sigs...)
+	quit := make(chan struct{})
+	go r.handleSignal(ch, quit, onShutdown, func(sig os.Signal) {
+		signal.Stop(ch)
+		if p, err := os.FindProcess(os.Getpid()); err == nil {
+			p.Signal(sig)
+		}
+	})
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			signal.Stop(ch)
+			close(quit)
+		})
+	}
+}
+
+func (r *Registry) handleSignal(ch <-chan os.Signal, quit <-chan struct{}, onShutdown func(*ShutdownSummary), reraise func(os.Signal)) {
+	select {
+	case sig := <-ch:
+		summary := r.Shutdown(context.Background())
+		if onShutdown != nil {
+			onShutdown(summary)
+		}
+		reraise(sig)
+	case <-quit:
+	}
+}
+
+// begin registers a creation in flight, unless the registry is shutting down
+func (r *Registry) begin() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		return ErrRegistryClosed
+	}
+	r.inflight.Add(1)
+	return nil
+}
+
+func (r *Registry) track(sandboxID string, fn teardownFunc) {
+	r.mu.Lock()
+	if !r.drained {
+		r.entries[sandboxID] = fn
+		r.mu.Unlock()
+		return
+	}
+	r.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), r.opts.Timeout)
+	defer cancel()
+	fn(ctx)
+}
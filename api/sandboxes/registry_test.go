@@ -0,0 +1,114 @@
+package sandboxes
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/scalebox/scalebox-sdk-golang/client"
+	"github.com/scalebox/scalebox-sdk-golang/models"
+)
+
+func TestRegistryShutdown(t *testing.T) {
+	fake := newTeardownServer()
+	fake.delay = 20 * time.Millisecond
+	server := httptest.NewServer(fake)
+	defer server.Close()
+	c := NewClient(client.NewClient(server.URL, "test-api-key"))
+	ctx := context.Background()
+	req := models.CreateSandboxRequest{Name: "registered"}
+
+	r := NewRegistry(c, RegistryOptions{Policy: PauseOnDone, Concurrency: 2})
+	for i := 0; i < 4; i++ {
+		if _, err := r.Create(ctx, req); err != nil {
+			t.Fatalf("Create failed: %v", err)
+		}
+	}
+	scoped, err := Scoped(ctx, c, req, ScopeOptions{Registry: r})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.Track("sbx-external", TerminateOnDone)
+	fake.fail["sbx-2"] = http.StatusBadRequest
+	if r.Len() != 6 {
+		t.Fatalf("Expected 6 tracked sandboxes, got %d", r.Len())
+	}
+
+	summary := r.Shutdown(ctx)
+	if summary.Total != 6 || summary.TornDown != 5 || len(summary.Failures) != 1 || summary.Failures[0].SandboxID != "sbx-2" {
+		t.Errorf("Unexpected summary: %s", summary)
+	}
+	if summary.Err() == nil || !strings.Contains(summary.String(), "tore down 5 of 6") {
+		t.Errorf("Expected the failure in the summary, got %s", summary)
+	}
+	if fake.action("sbx-1") != "pause" || fake.action("sbx-external") != "terminate" || fake.action(scoped.Sandbox.SandboxID) != "terminate" {
+		t.Errorf("Unexpected teardown calls %v", fake.calls)
+	}
+	if fake.maxSeen > 2 {
+		t.Errorf("Expected at most 2 concurrent teardowns, got %d", fake.maxSeen)
+	}
+	waitDone(t, scoped)
+	if r.Len() != 0 {
+		t.Errorf("Expected an empty registry, got %d", r.Len())
+	}
+
+	if _, err := r.Create(ctx, req); !errors.Is(err, ErrRegistryClosed) {
+		t.Errorf("Expected ErrRegistryClosed, got %v", err)
+	}
+	if _, err := Scoped(ctx, c, req, ScopeOptions{Registry: r}); !errors.Is(err, ErrRegistryClosed) {
+		t.Errorf("Expected ErrRegistryClosed from Scoped, got %v", err)
+	}
+
+	// Sandboxes tracked after shutdown are torn down immediately
+	r.Track("sbx-late", TerminateOnDone)
+	if fake.action("sbx-late") != "terminate" {
+		t.Error("Expected a late sandbox to be torn down")
+	}
+}
+
+func TestRegistryShutdownDeadline(t *testing.T) {
+	fake := newTeardownServer()
+	fake.delay = 200 * time.Millisecond
+	server := httptest.NewServer(fake)
+	defer server.Close()
+	c := NewClient(client.NewClient(server.URL, "test-api-key"))
+
+	r := NewRegistry(c, RegistryOptions{Timeout: 50 * time.Millisecond, Concurrency: 1})
+	r.Track("sbx-a", TerminateOnDone)
+	r.Track("sbx-b", TerminateOnDone)
+	summary := r.Shutdown(context.Background())
+	if len(summary.Failures) != 2 || summary.Duration > 150*time.Millisecond {
+		t.Errorf("Expected both teardowns to hit the deadline, got %s", summary)
+	}
+	for _, f := range summary.Failures {
+		if !errors.Is(f, context.DeadlineExceeded) {
+			t.Errorf("Expected a deadline error, got %v", f)
+		}
+	}
+}
+
+func TestRegistryHandleSignal(t *testing.T) {
+	fake := newTeardownServer()
+	server := httptest.NewServer(fake)
+	defer server.Close()
+	r := NewRegistry(NewClient(client.NewClient(server.URL, "test-api-key")), RegistryOptions{})
+	r.Track("sbx-1", TerminateOnDone)
+
+	ch := make(chan os.Signal, 1)
+	var summary *ShutdownSummary
+	var reraised os.Signal
+	ch <- os.Interrupt
+	r.handleSignal(ch, nil, func(s *ShutdownSummary) { summary = s }, func(sig os.Signal) { reraised = sig })
+	if summary == nil || summary.TornDown != 1 || reraised != os.Interrupt {
+		t.Errorf("Expected shutdown then re-raise, got %v and %v", summary, reraised)
+	}
+
+	stop := r.HandleSignals(nil)
+	stop()
+	stop()
+}
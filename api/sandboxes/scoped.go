@@ -0,0 +1,150 @@
+package sandboxes
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/scalebox/scalebox-sdk-golang/client"
+	"github.com/scalebox/scalebox-sdk-golang/models"
+)
+
+// DefaultTeardownTimeout bounds the teardown call made when a scope ends
+const DefaultTeardownTimeout = 30 * time.Second
+
+// TeardownPolicy selects what happens to a sandbox when its scope ends
+type TeardownPolicy int
+
+const (
+	// TerminateOnDone terminates the sandbox
+	TerminateOnDone TeardownPolicy = iota
+	// PauseOnDone pauses the sandbox so its state can be resumed later
+	PauseOnDone
+)
+
+func (p TeardownPolicy) String() string {
+	if p == PauseOnDone {
+		return "pause"
+	}
+	return "terminate"
+}
+
+// ScopeOptions configures Scoped
+type ScopeOptions struct {
+	Policy          TeardownPolicy
+	TeardownTimeout time.Duration // Deadline for the teardown call, defaults to DefaultTeardownTimeout
+	Registry        *Registry     // Optional: also tear the sandbox down when the registry shuts down
+}
+
+// ScopedSandbox is a sandbox whose lifetime is tied to a context
+type ScopedSandbox struct {
+	Sandbox *models.Sandbox
+
+	client *Client
+	opts   ScopeOptions
+	once   sync.Once
+	done   chan struct{}
+	err    error
+
+	mu   sync.Mutex
+	stop func() bool // Unregisters the context callback
+}
+
+// Scoped creates a sandbox that is terminated, or paused by policy, once ctx is cancelled.
+// The teardown runs in the background with its own TeardownTimeout, since ctx is already done;
+// call Close to tear down early and wait for the result.
+func Scoped(ctx context.Context, c *Client, req models.CreateSandboxRequest, opts ScopeOptions) (*ScopedSandbox, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if opts.TeardownTimeout <= 0 {
+		opts.TeardownTimeout = DefaultTeardownTimeout
+	}
+
+	if opts.Registry != nil {
+		if err := opts.Registry.begin(); err != nil {
+			return nil, err
+		}
+		defer opts.Registry.inflight.Done()
+	}
+
+	sandbox, err := c.Create(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &ScopedSandbox{Sandbox: sandbox, client: c, opts: opts, done: make(chan struct{})}
+	if opts.Registry != nil {
+		opts.Registry.track(sandbox.SandboxID, s.teardown)
+	}
+	s.mu.Lock()
+	s.stop = context.AfterFunc(ctx, func() {
+		tctx, cancel := context.WithTimeout(context.Background(), s.opts.TeardownTimeout)
+		defer cancel()
+		s.teardown(tctx)
+	})
+	s.mu.Unlock()
+	return s, nil
+}
+
+// Close tears the sandbox down now, if that has not happened yet, and returns the teardown error
+func (s *ScopedSandbox) Close() error {
+	ctx, cancel := context.WithTimeout(context.Background(), s.opts.TeardownTimeout)
+	defer cancel()
+	return s.teardown(ctx)
+}
+
+// Release detaches the sandbox from its scope, so it keeps running after ctx is cancelled
+func (s *ScopedSandbox) Release() {
+	s.once.Do(func() { s.finish(nil) })
+}
+
+// Done is closed once the sandbox has been torn down or released
+func (s *ScopedSandbox) Done() <-chan struct{} {
+	return s.done
+}
+
+// Err returns the teardown error, once Done is closed
+func (s *ScopedSandbox) Err() error {
+	select {
+	case <-s.done:
+		return s.err
+	default:
+		return nil
+	}
+}
+
+func (s *ScopedSandbox) teardown(ctx context.Context) error {
+	s.once.Do(func() {
+		s.finish(teardown(ctx, s.client, s.Sandbox.SandboxID, s.opts.Policy))
+	})
+	<-s.done
+	return s.err
+}
+
+func (s *ScopedSandbox) finish(err error) {
+	s.err = err
+	s.mu.Lock()
+	if s.stop != nil {
+		s.stop()
+	}
+	s.mu.Unlock()
+	if s.opts.Registry != nil {
+		s.opts.Registry.Forget(s.Sandbox.SandboxID)
+	}
+	close(s.done)
+}
+
+// teardown applies the policy to a sandbox. Sandboxes that no longer exist count as torn down.
+func teardown(ctx context.Context, c *Client, sandboxID string, policy TeardownPolicy) error {
+	var err error
+	if policy == PauseOnDone {
+		_, err = c.Pause(ctx, sandboxID)
+	} else {
+		_, err = c.Terminate(ctx, sandboxID, nil)
+	}
+	if client.IsNotFound(err) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,153 @@
+package sandboxes
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/scalebox/scalebox-sdk-golang/client"
+	"github.com/scalebox/scalebox-sdk-golang/models"
+)
+
+// teardownServer is a fake API that creates sandboxes and records pause and terminate calls
+type teardownServer struct {
+	mu       sync.Mutex
+	created  int
+	calls    map[string]string // sandbox ID -> last teardown action
+	count    int
+	fail     map[string]int // sandbox ID -> status code to fail teardown with
+	delay    time.Duration
+	inFlight int
+	maxSeen  int
+}
+
+func newTeardownServer() *teardownServer {
+	return &teardownServer{calls: make(map[string]string), fail: make(map[string]int)}
+}
+
+func (s *teardownServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if r.Method == "POST" && r.URL.Path == "/v1/sandboxes" {
+		s.mu.Lock()
+		s.created++
+		id := fmt.Sprintf("sbx-%d", s.created)
+		s.mu.Unlock()
+		json.NewEncoder(w).Encode(models.Sandbox{SandboxID: id, Status: models.StatusStarting})
+		return
+	}
+
+	parts := strings.Split(r.URL.Path, "/")
+	id, action := parts[3], parts[4]
+	s.mu.Lock()
+	s.inFlight++
+	if s.inFlight > s.maxSeen {
+		s.maxSeen = s.inFlight
+	}
+	code := s.fail[id]
+	s.mu.Unlock()
+	time.Sleep(s.delay)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.inFlight--
+	if code != 0 {
+		w.WriteHeader(code)
+		json.NewEncoder(w).Encode(map[string]string{"error": "teardown failed"})
+		return
+	}
+	s.calls[id] = action
+	s.count++
+	json.NewEncoder(w).Encode(models.Sandbox{SandboxID: id})
+}
+
+func (s *teardownServer) action(id string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.calls[id]
+}
+
+func waitDone(t *testing.T, s *ScopedSandbox) {
+	t.Helper()
+	select {
+	case <-s.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for teardown")
+	}
+}
+
+func TestScoped(t *testing.T) {
+	fake := newTeardownServer()
+	server := httptest.NewServer(fake)
+	defer server.Close()
+	c := NewClient(client.NewClient(server.URL, "test-api-key"))
+	req := models.CreateSandboxRequest{Name: "scoped"}
+
+	// Cancelling the context terminates the sandbox
+	ctx, cancel := context.WithCancel(context.Background())
+	s, err := Scoped(ctx, c, req, ScopeOptions{})
+	if err != nil {
+		t.Fatalf("Scoped failed: %v", err)
+	}
+	if fake.action(s.Sandbox.SandboxID) != "" {
+		t.Fatal("Expected no teardown before the context ends")
+	}
+	cancel()
+	waitDone(t, s)
+	if s.Err() != nil || fake.action(s.Sandbox.SandboxID) != "terminate" {
+		t.Errorf("Expected terminate, got %q (%v)", fake.action(s.Sandbox.SandboxID), s.Err())
+	}
+
+	// PauseOnDone pauses instead, and Close tears down once even if the context ends later
+	ctx, cancel = context.WithCancel(context.Background())
+	s, err = Scoped(ctx, c, req, ScopeOptions{Policy: PauseOnDone})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	before := fake.count
+	cancel()
+	time.Sleep(20 * time.Millisecond)
+	if fake.action(s.Sandbox.SandboxID) != "pause" || fake.count != before {
+		t.Errorf("Expected a single pause, got %q and %d extra calls", fake.action(s.Sandbox.SandboxID), fake.count-before)
+	}
+
+	// Released sandboxes outlive their scope
+	ctx, cancel = context.WithCancel(context.Background())
+	s, err = Scoped(ctx, c, req, ScopeOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.Release()
+	cancel()
+	time.Sleep(20 * time.Millisecond)
+	if fake.action(s.Sandbox.SandboxID) != "" {
+		t.Error("Expected a released sandbox to be left alone")
+	}
+
+	// Sandboxes that are already gone count as torn down
+	ctx, cancel = context.WithCancel(context.Background())
+	s, err = Scoped(ctx, c, req, ScopeOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	fake.mu.Lock()
+	fake.fail[s.Sandbox.SandboxID] = http.StatusNotFound
+	fake.mu.Unlock()
+	cancel()
+	waitDone(t, s)
+	if s.Err() != nil {
+		t.Errorf("Expected not found to count as torn down, got %v", s.Err())
+	}
+
+	if _, err := Scoped(ctx, c, req, ScopeOptions{}); err != context.Canceled {
+		t.Errorf("Expected context.Canceled for a done context, got %v", err)
+	}
+}
@@ -0,0 +1,315 @@
+package sandboxes
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/scalebox/scalebox-sdk-golang/client"
+	"github.com/scalebox/scalebox-sdk-golang/models"
+)
+
+// Metrics streaming defaults
+const (
+	DefaultStreamInterval    = 5 * time.Second
+	DefaultStreamMaxFailures = 3 // Consecutive failed polls before the stream gives up
+)
+
+// metricsStreamPath is the experimental server-sent events endpoint tried before polling when
+// ClientOptions.MetricsEventStream is set. It is not part of the documented API. Any answer
+// other than a 200 event stream, e.g. 404 from servers without it, means polling.
+const metricsStreamPath = "/v1/sandboxes/%s/metrics/stream"
+
+// MetricsStream delivers the new metrics of a running sandbox; see StreamMetrics
+type MetricsStream struct {
+	points chan models.MetricsDataPoint
+	done   chan struct{}
+	err    error
+	status string
+}
+
+// Points returns the channel of new points, in time order and without duplicates.
+// It is closed when the stream ends.
+func (s *MetricsStream) Points() <-chan models.MetricsDataPoint {
+	return s.points
+}
+
+// Done is closed when the stream has ended
+func (s *MetricsStream) Done() <-chan struct{} {
+	return s.done
+}
+
+// Err returns why the stream ended once Done is closed: nil when the sandbox stopped,
+// the context error when ctx ended, or the last API error after repeated failures
+func (s *MetricsStream) Err() error {
+	select {
+	case <-s.done:
+		return s.err
+	default:
+		return nil
+	}
+}
+
+// Status returns the last sandbox status seen, e.g. terminated once the stream has ended that way
+func (s *MetricsStream) Status() string {
+	select {
+	case <-s.done:
+		return s.status
+	default:
+		return ""
+	}
+}
+
+// StreamMetrics streams new metrics of a sandbox until ctx ends or the sandbox reaches a
+// terminal status. It polls GetMetrics every interval (DefaultStreamInterval when zero),
+// starting each request where the previous one ended; with ClientOptions.MetricsEventStream
+// it first tries the server's event stream. Points must be consumed; the stream waits for a slow reader.
+// Use NewMetricsFanout to share the stream between several consumers.
+func (c *Client) StreamMetrics(ctx context.Context, sandboxID string, interval time.Duration) *MetricsStream {
+	if interval <= 0 {
+		interval = DefaultStreamInterval
+	}
+	s := &MetricsStream{points: make(chan models.MetricsDataPoint), done: make(chan struct{})}
+	go func() {
+		// Done is closed first so Err is set once a reader sees Points closed
+		defer close(s.points)
+		defer close(s.done)
+		r := &metricsStreamer{client: c, sandboxID: sandboxID, interval: interval, out: s.points}
+		s.err = r.run(ctx)
+		s.status = r.status
+	}()
+	return s
+}
+
+// metricsStreamer produces the points of one MetricsStream
+type metricsStreamer struct {
+	client    *Client
+	sandboxID string
+	interval  time.Duration
+	out       chan<- models.MetricsDataPoint
+	last      time.Time // Timestamp of the last point sent
+	status    string
+}
+
+// errStreamUnavailable means the server has no event stream and polling should be used
+var errStreamUnavailable = errors.New("sandboxes: metrics event stream unavailable")
+
+func (r *metricsStreamer) run(ctx context.Context) error {
+	if !r.client.opts.MetricsEventStream {
+		return r.poll(ctx)
+	}
+	err := r.events(ctx)
+	if err == nil || ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if err != errStreamUnavailable && stopped(r.status) {
+		return nil
+	}
+	// Without an event stream, or when it broke off, continue by polling from the last point
+	return r.poll(ctx)
+}
+
+// events consumes the server's event stream. It returns nil when the sandbox stopped,
+// errStreamUnavailable when the server has no stream, or the error that ended it.
+func (r *metricsStreamer) events(ctx context.Context) error {
+	resp, err := r.client.streamingClient().DoRequestValues(ctx, "GET", fmt.Sprintf(metricsStreamPath, r.sandboxID), nil, nil,
+		map[string]string{"Accept": "text/event-stream"})
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || !strings.HasPrefix(resp.Header.Get("Content-Type"), "text/event-stream") {
+		return errStreamUnavailable
+	}
+
+	// Events are "data:" lines with a JSON data point; "event: status" carries {"status": ...}
+	scanner := bufio.NewScanner(resp.Body)
+	event := ""
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == "":
+			event = ""
+		case strings.HasPrefix(line, "event:"):
+			event = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+		case strings.HasPrefix(line, "data:"):
+			data := []byte(strings.TrimSpace(strings.TrimPrefix(line, "data:")))
+			if event == "status" {
+				var st models.SandboxStatus
+				if err := json.Unmarshal(data, &st); err != nil {
+					return fmt.Errorf("decode status event: %w", err)
+				}
+				r.status = st.Status
+				if stopped(st.Status) {
+					return nil
+				}
+				continue
+			}
+			var p models.MetricsDataPoint
+			if err := json.Unmarshal(data, &p); err != nil {
+				return fmt.Errorf("decode metrics event: %w", err)
+			}
+			if err := r.send(ctx, p); err != nil {
+				return err
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	return errors.New("sandboxes: metrics event stream closed")
+}
+
+// streamingClient returns a copy of the base client whose HTTP client has no overall timeout,
+// which would otherwise cut every event stream off after e.g. 30 seconds; ctx bounds the request
+func (c *Client) streamingClient() *client.Client {
+	base := *c.baseClient
+	if base.HTTPClient != nil && base.HTTPClient.Timeout != 0 {
+		httpClient := *base.HTTPClient
+		httpClient.Timeout = 0
+		base.HTTPClient = &httpClient
+	}
+	return &base
+}
+
+// poll asks for the points after the last one sent every interval
+func (r *metricsStreamer) poll(ctx context.Context) error {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+	step := int(r.interval / time.Second)
+	if step < 1 {
+		step = 1
+	}
+
+	failures := 0
+	for {
+		start := r.last
+		if start.IsZero() {
+			start = time.Now().Add(-r.interval)
+		}
+		resp, err := r.client.GetMetrics(ctx, r.sandboxID, &models.GetSandboxMetricsOptions{Start: &start, Step: &step})
+		switch {
+		case ctx.Err() != nil:
+			return ctx.Err()
+		case client.IsNotFound(err):
+			r.status = models.StatusTerminated
+			return nil
+		case err != nil:
+			if failures++; failures >= DefaultStreamMaxFailures {
+				return err
+			}
+		default:
+			failures = 0
+			for _, p := range resp.Metrics {
+				if err := r.send(ctx, p); err != nil {
+					return err
+				}
+			}
+			r.status = resp.Status
+			if stopped(resp.Status) {
+				return nil
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// send delivers p unless it is not newer than the last point sent
+func (r *metricsStreamer) send(ctx context.Context, p models.MetricsDataPoint) error {
+	if !p.Timestamp.After(r.last) {
+		return nil
+	}
+	select {
+	case r.out <- p:
+		r.last = p.Timestamp
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func stopped(status string) bool {
+	return models.IsTerminalStatus(status) || status == models.StatusTerminating
+}
+
+// MetricsFanout shares one MetricsStream between several subscribers
+type MetricsFanout struct {
+	mu     sync.Mutex
+	subs   map[*metricsSubscriber]struct{}
+	closed bool
+}
+
+type metricsSubscriber struct {
+	ch chan models.MetricsDataPoint
+}
+
+// NewMetricsFanout starts forwarding the points of s to subscribers.
+// Points are dropped for subscribers whose buffer is full, so a slow subscriber does not
+// hold up the others, and while there are no subscribers.
+func NewMetricsFanout(s *MetricsStream) *MetricsFanout {
+	f := &MetricsFanout{subs: make(map[*metricsSubscriber]struct{})}
+	go func() {
+		for p := range s.Points() {
+			f.mu.Lock()
+			for sub := range f.subs {
+				select {
+				case sub.ch <- p:
+				default:
+				}
+			}
+			f.mu.Unlock()
+		}
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		f.closed = true
+		for sub := range f.subs {
+			close(sub.ch)
+		}
+		f.subs = nil
+	}()
+	return f
+}
+
+// Subscribe returns a channel receiving the points that arrive from now on, buffering up to
+// buffer of them, and a function that ends the subscription. The channel is closed when the
+// subscription or the stream ends.
+func (f *MetricsFanout) Subscribe(buffer int) (<-chan models.MetricsDataPoint, func()) {
+	sub := &metricsSubscriber{ch: make(chan models.MetricsDataPoint, buffer)}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.closed {
+		close(sub.ch)
+		return sub.ch, func() {}
+	}
+	f.subs[sub] = struct{}{}
+
+	var once sync.Once
+	return sub.ch, func() {
+		once.Do(func() {
+			f.mu.Lock()
+			defer f.mu.Unlock()
+			if _, ok := f.subs[sub]; ok {
+				delete(f.subs, sub)
+				close(sub.ch)
+			}
+		})
+	}
+}
+
+// Len returns the number of subscribers
+func (f *MetricsFanout) Len() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.subs)
+}
@@ -0,0 +1,206 @@
+package sandboxes
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/scalebox/scalebox-sdk-golang/client"
+	"github.com/scalebox/scalebox-sdk-golang/models"
+)
+
+var streamEpoch = time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+
+func streamPoint(i int) models.MetricsDataPoint {
+	return models.MetricsDataPoint{Timestamp: streamEpoch.Add(time.Duration(i) * time.Second), CPUUsedPct: float64(i)}
+}
+
+func collect(t *testing.T, points <-chan models.MetricsDataPoint) []int {
+	t.Helper()
+	var got []int
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case p, ok := <-points:
+			if !ok {
+				return got
+			}
+			got = append(got, int(p.CPUUsedPct))
+		case <-timeout:
+			t.Fatalf("Timed out, received %v", got)
+		}
+	}
+}
+
+func TestStreamMetricsPolling(t *testing.T) {
+	// Each poll returns overlapping windows; the last one reports the sandbox terminated
+	polls := []struct {
+		points []int
+		status string
+	}{
+		{[]int{0, 1}, models.StatusRunning},
+		{[]int{1, 2}, models.StatusRunning},
+		{nil, models.StatusRunning},
+		{[]int{2, 3}, models.StatusTerminated},
+	}
+	var mu sync.Mutex
+	var starts []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v1/sandboxes/sbx-1/metrics/stream" {
+			t.Error("Expected no event stream request without MetricsEventStream")
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		mu.Lock()
+		n := len(starts)
+		starts = append(starts, r.URL.Query().Get("start"))
+		mu.Unlock()
+		poll := polls[n]
+		resp := models.SandboxMetricsResponse{SandboxID: "sbx-1", Status: poll.status, Metrics: []models.MetricsDataPoint{}}
+		for _, i := range poll.points {
+			resp.Metrics = append(resp.Metrics, streamPoint(i))
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(resp)
+	}))
+	defer server.Close()
+	c := NewClient(client.NewClient(server.URL, "test-api-key"))
+
+	stream := c.StreamMetrics(context.Background(), "sbx-1", 5*time.Millisecond)
+	if got := collect(t, stream.Points()); fmt.Sprint(got) != "[0 1 2 3]" {
+		t.Errorf("Expected each point once, got %v", got)
+	}
+	<-stream.Done()
+	if stream.Err() != nil || stream.Status() != models.StatusTerminated {
+		t.Errorf("Expected the stream to end with the sandbox, got %v (%s)", stream.Err(), stream.Status())
+	}
+	if len(starts) != 4 || starts[1] != streamPoint(1).Timestamp.Format(time.RFC3339) || starts[3] != streamPoint(2).Timestamp.Format(time.RFC3339) {
+		t.Errorf("Expected each poll to start at the last point, got %v", starts)
+	}
+}
+
+func TestStreamMetricsEvents(t *testing.T) {
+	ready := make(chan struct{})
+	polled := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/sandboxes/sbx-1/metrics/stream" {
+			polled = true
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		if r.Header.Get("Accept") != "text/event-stream" {
+			t.Errorf("Expected an event stream request, got Accept %q", r.Header.Get("Accept"))
+		}
+		<-ready
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, i := range []int{0, 1, 1, 2} {
+			data, _ := json.Marshal(streamPoint(i))
+			fmt.Fprintf(w, "data: %s\n\n", data)
+		}
+		fmt.Fprint(w, "event: status\ndata: {\"sandbox_id\":\"sbx-1\",\"status\":\"paused\"}\n\n")
+		fmt.Fprint(w, "event: status\ndata: {\"sandbox_id\":\"sbx-1\",\"status\":\"terminating\"}\n\n")
+	}))
+	defer server.Close()
+	c := NewClientWithOptions(client.NewClient(server.URL, "test-api-key"), ClientOptions{MetricsEventStream: true})
+
+	stream := c.StreamMetrics(context.Background(), "sbx-1", time.Second)
+	fanout := NewMetricsFanout(stream)
+	first, _ := fanout.Subscribe(10)
+	second, _ := fanout.Subscribe(10)
+	leaving, unsubscribe := fanout.Subscribe(10)
+	unsubscribe()
+	unsubscribe()
+	if fanout.Len() != 2 {
+		t.Errorf("Expected 2 subscribers, got %d", fanout.Len())
+	}
+	close(ready)
+
+	for _, sub := range []<-chan models.MetricsDataPoint{first, second} {
+		if got := collect(t, sub); fmt.Sprint(got) != "[0 1 2]" {
+			t.Errorf("Expected every subscriber to get each point once, got %v", got)
+		}
+	}
+	if _, ok := <-leaving; ok {
+		t.Error("Expected an ended subscription to be closed")
+	}
+	<-stream.Done()
+	if stream.Err() != nil || stream.Status() != models.StatusTerminating || polled {
+		t.Errorf("Expected the event stream to end the stream, got %v (%s, polled %v)", stream.Err(), stream.Status(), polled)
+	}
+	if late, _ := fanout.Subscribe(1); late != nil {
+		if _, ok := <-late; ok {
+			t.Error("Expected a subscription to an ended stream to be closed")
+		}
+	}
+}
+
+func TestStreamMetricsOutlivesClientTimeout(t *testing.T) {
+	polled := make(chan struct{}, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/sandboxes/sbx-1/metrics/stream" {
+			select {
+			case polled <- struct{}{}:
+			default:
+			}
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		for i := 0; i < 3; i++ {
+			data, _ := json.Marshal(streamPoint(i))
+			fmt.Fprintf(w, "data: %s\n\n", data)
+			w.(http.Flusher).Flush()
+			time.Sleep(60 * time.Millisecond)
+		}
+		fmt.Fprint(w, "event: status\ndata: {\"sandbox_id\":\"sbx-1\",\"status\":\"terminated\"}\n\n")
+	}))
+	defer server.Close()
+	// The event stream runs longer than the client's request timeout
+	base := client.NewClient(server.URL, "test-api-key")
+	base.HTTPClient.Timeout = 50 * time.Millisecond
+	c := NewClientWithOptions(base, ClientOptions{MetricsEventStream: true})
+
+	stream := c.StreamMetrics(context.Background(), "sbx-1", time.Second)
+	if got := collect(t, stream.Points()); fmt.Sprint(got) != "[0 1 2]" {
+		t.Errorf("Expected every point from the event stream, got %v", got)
+	}
+	if stream.Err() != nil || stream.Status() != models.StatusTerminated {
+		t.Errorf("Expected the event stream to end the stream, got %v (%s)", stream.Err(), stream.Status())
+	}
+	select {
+	case <-polled:
+		t.Error("Expected no fallback to polling")
+	default:
+	}
+	if base.HTTPClient.Timeout != 50*time.Millisecond {
+		t.Error("Streaming must not modify the base client")
+	}
+}
+
+func TestStreamMetricsCancel(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v1/sandboxes/sbx-1/metrics/stream" {
+			w.WriteHeader(http.StatusNotImplemented)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(models.SandboxMetricsResponse{SandboxID: "sbx-1", Status: models.StatusRunning, Metrics: []models.MetricsDataPoint{}})
+	}))
+	defer server.Close()
+	// Servers without the event stream fall back to polling
+	c := NewClientWithOptions(client.NewClient(server.URL, "test-api-key"), ClientOptions{MetricsEventStream: true})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stream := c.StreamMetrics(ctx, "sbx-1", 5*time.Millisecond)
+	time.Sleep(20 * time.Millisecond)
+	cancel()
+	collect(t, stream.Points())
+	if stream.Err() != context.Canceled {
+		t.Errorf("Expected context.Canceled, got %v", stream.Err())
+	}
+}
@@ -0,0 +1,42 @@
+package sandboxes
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/scalebox/scalebox-sdk-golang/models"
+)
+
+// DefaultWaitInterval is the polling interval used by WaitForStatus when none is given
+const DefaultWaitInterval = 2 * time.Second
+
+// WaitForStatus polls the lightweight status endpoint until the sandbox reaches the wanted status.
+// It fails early when the sandbox enters a terminal status other than the wanted one.
+func (c *Client) WaitForStatus(ctx context.Context, sandboxID, status string, interval time.Duration) (*models.SandboxStatus, error) {
+	if interval <= 0 {
+		interval = DefaultWaitInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		current, err := c.GetStatus(ctx, sandboxID)
+		if err != nil {
+			return nil, err
+		}
+		if current.Status == status {
+			return current, nil
+		}
+		if models.IsTerminalStatus(current.Status) {
+			return current, fmt.Errorf("sandbox %s entered status %q while waiting for %q", sandboxID, current.Status, status)
+		}
+
+		select {
+		case <-ctx.Done():
+			return current, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,580 @@
+// Package budget guards against runaway sandbox creation.
+//
+// A Guard enforces Limits on the sandboxes of a project or label selector: how many may be
+// active at once, how many CPUs and how much memory they may hold, and how much they may
+// spend since SpendSince, estimated with a usage.PriceSheet. It keeps a local account of the
+// sandboxes, updated by every creation made through Client and corrected by Reconcile, which
+// lists the sandboxes again. Creations that would cross a limit fail with an *ExceededError,
+// which matches ErrBudgetExceeded. With PauseNewest set, Reconcile also pauses the newest
+// sandboxes of a limit that was crossed anyway, e.g. by sandboxes created elsewhere.
+package budget
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/scalebox/scalebox-sdk-golang/api/sandboxes"
+	"github.com/scalebox/scalebox-sdk-golang/labels"
+	"github.com/scalebox/scalebox-sdk-golang/models"
+	"github.com/scalebox/scalebox-sdk-golang/usage"
+)
+
+// Budget guard defaults
+const (
+	DefaultInterval    = time.Minute
+	DefaultExemptLabel = "scalebox.budget.exempt"
+)
+
+// ErrBudgetExceeded matches every *ExceededError with errors.Is
+var ErrBudgetExceeded = errors.New("budget exceeded")
+
+// Resource is a quantity a Limit caps
+type Resource string
+
+// Limited resources
+const (
+	ResourceSandboxes Resource = "sandboxes"
+	ResourceCPU       Resource = "cpu"
+	ResourceMemory    Resource = "memory_mb"
+	ResourceSpend     Resource = "spend"
+)
+
+// ExceededError is returned by Create when a sandbox would cross a limit
+type ExceededError struct {
+	Limit     string   // Name of the limit
+	Resource  Resource // The first resource that would be crossed
+	Used      float64  // Already in use, or spent
+	Requested float64  // Asked for by the request; zero for spend
+	Max       float64
+}
+
+func (e *ExceededError) Error() string {
+	if e.Resource == ResourceSpend {
+		return fmt.Sprintf("budget %s: estimated spend %.2f has reached the limit of %.2f", e.Limit, e.Used, e.Max)
+	}
+	return fmt.Sprintf("budget %s: %s would be %g, limit %g", e.Limit, e.Resource, e.Used+e.Requested, e.Max)
+}
+
+// Is reports whether target is ErrBudgetExceeded
+func (e *ExceededError) Is(target error) bool {
+	return target == ErrBudgetExceeded
+}
+
+// Limit caps the sandboxes of a project, a label selector or both. Zero maximums are unlimited.
+type Limit struct {
+	// Name identifies the limit in errors and status, defaults to its project and selector
+	Name string
+	// ProjectID limits the sandboxes of one project; empty matches every project
+	ProjectID string
+	// Selector limits the sandboxes whose metadata matches, e.g. "team=ml"; empty matches all
+	Selector string
+	// MaxSandboxes, MaxCPU and MaxMemoryMB cap the active (starting, running or pausing) sandboxes
+	MaxSandboxes int
+	MaxCPU       int
+	MaxMemoryMB  int
+	// MaxSpend caps the estimated spend since Options.SpendSince, in the price sheet's currency
+	MaxSpend float64
+}
+
+// name returns the name of the limit, or describes its scope
+func (l Limit) name() string {
+	switch {
+	case l.Name != "":
+		return l.Name
+	case l.ProjectID != "" && l.Selector != "":
+		return "project=" + l.ProjectID + " selector=" + l.Selector
+	case l.ProjectID != "":
+		return "project=" + l.ProjectID
+	case l.Selector != "":
+		return "selector=" + l.Selector
+	}
+	return "all"
+}
+
+// Options configures a Guard
+type Options struct {
+	Limits []Limit
+	// Prices estimates spend; required when a limit has MaxSpend
+	Prices usage.PriceSheet
+	// SpendSince is when spend starts counting, defaults to the start of the current month in UTC
+	SpendSince time.Time
+	// DefaultProjectID is the project of requests without a ProjectID, so project limits apply
+	// to them; leave it empty if the account's default project is not limited
+	DefaultProjectID string
+	// PauseNewest makes Reconcile pause the newest active sandboxes of a crossed limit until it
+	// is respected again; all of them when its spend limit is reached
+	PauseNewest bool
+	// ExemptLabel marks sandboxes that are never paused, whatever its value other than "false".
+	// They still count towards the limits. Defaults to DefaultExemptLabel.
+	ExemptLabel string
+	// Interval is how often Run reconciles, defaults to DefaultInterval
+	Interval time.Duration
+	// Now returns the current time, defaults to time.Now
+	Now func() time.Time
+}
+
+// Validate checks the limits and prices
+func (o Options) Validate() error {
+	var errs []models.FieldError
+	if len(o.Limits) == 0 {
+		errs = append(errs, models.FieldError{Field: "limits", Message: "at least one limit is required"})
+	}
+	spend := false
+	for i, l := range o.Limits {
+		field := fmt.Sprintf("limits[%d]", i)
+		if _, err := labels.Parse(l.Selector); err != nil {
+			errs = append(errs, models.FieldError{Field: field + ".selector", Message: err.Error()})
+		}
+		if l.MaxSandboxes < 0 || l.MaxCPU < 0 || l.MaxMemoryMB < 0 || l.MaxSpend < 0 {
+			errs = append(errs, models.FieldError{Field: field, Message: "maximums must not be negative"})
+		} else if l.MaxSandboxes == 0 && l.MaxCPU == 0 && l.MaxMemoryMB == 0 && l.MaxSpend == 0 {
+			errs = append(errs, models.FieldError{Field: field, Message: "sets no maximum"})
+		}
+		spend = spend || l.MaxSpend > 0
+	}
+	if err := o.Prices.Validate(); err != nil {
+		errs = append(errs, err.(*models.ValidationError).Errors...)
+	} else if spend && o.Prices == (usage.PriceSheet{Currency: o.Prices.Currency}) {
+		errs = append(errs, models.FieldError{Field: "prices", Message: "are required by spend limits"})
+	}
+	if o.Interval < 0 {
+		errs = append(errs, models.FieldError{Field: "interval", Message: "must not be negative"})
+	}
+	if len(errs) > 0 {
+		return &models.ValidationError{Errors: errs}
+	}
+	return nil
+}
+
+// withDefaults fills in the zero options
+func (o Options) withDefaults() Options {
+	if o.ExemptLabel == "" {
+		o.ExemptLabel = DefaultExemptLabel
+	}
+	if o.Interval == 0 {
+		o.Interval = DefaultInterval
+	}
+	if o.Now == nil {
+		o.Now = time.Now
+	}
+	return o
+}
+
+// limit is a Limit with its selector parsed
+type limit struct {
+	Limit
+	selector labels.Selector
+}
+
+func (l *limit) matches(projectID string, metadata map[string]string) bool {
+	return (l.ProjectID == "" || l.ProjectID == projectID) && l.selector.Matches(metadata)
+}
+
+// account is a sandbox as the guard accounts for it
+type account struct {
+	id                       string
+	projectID                string
+	metadata                 map[string]string
+	cpu, memoryMB, storageGB int
+	active                   bool      // Starting, running or pausing
+	createdAt                time.Time // Orders sandboxes for PauseNewest
+	since                    time.Time // Spend before this is in the reconciled spend
+	recorded                 time.Time // When a creation through the guard was recorded; zero if listed
+}
+
+// Guard enforces budget limits on sandbox creation, see the package documentation
+type Guard struct {
+	client *sandboxes.Client
+	opts   Options
+	limits []limit
+
+	first      sync.Mutex // Serializes the reconciliation of the first creations
+	mu         sync.Mutex
+	reconciled time.Time // Zero until the first Reconcile
+	accounts   map[string]*account
+	pending    map[*account]struct{} // Admitted creations waiting for the API
+	spend      []float64             // Per limit, up to reconciled
+}
+
+// New creates a Guard for the sandboxes of c. Create sandboxes through Client to enforce it.
+func New(c *sandboxes.Client, opts Options) (*Guard, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	opts = opts.withDefaults()
+	g := &Guard{client: c, opts: opts, accounts: make(map[string]*account), pending: make(map[*account]struct{})}
+	for _, l := range opts.Limits {
+		selector, _ := labels.Parse(l.Selector)
+		g.limits = append(g.limits, limit{Limit: l, selector: selector})
+	}
+	g.spend = make([]float64, len(g.limits))
+	return g, nil
+}
+
+// Client returns a client whose Create is checked against the limits
+func (g *Guard) Client() *sandboxes.Client {
+	return g.client.WithCreateGuard(g)
+}
+
+// Admit implements sandboxes.CreateGuard. It reconciles first if the guard never has, and
+// rejects the request with an *ExceededError if it would cross a limit. Sizes left to the
+// server are counted at the smallest size until the API returns the sandbox.
+func (g *Guard) Admit(ctx context.Context, req models.CreateSandboxRequest) (func(*models.Sandbox, error), error) {
+	if err := g.reconcileFirst(ctx); err != nil {
+		return nil, fmt.Errorf("budget: reconcile: %w", err)
+	}
+
+	now := g.opts.Now()
+	a := &account{
+		projectID: req.ProjectID,
+		metadata:  req.Metadata,
+		cpu:       max(req.CPUCount, models.MinCPUCount),
+		memoryMB:  max(req.MemoryMB, models.MinMemoryMB),
+		storageGB: max(req.StorageGB, models.MinStorageGB),
+		active:    true,
+		createdAt: now,
+		since:     now,
+	}
+	if a.projectID == "" {
+		a.projectID = g.opts.DefaultProjectID
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	for i := range g.limits {
+		l := &g.limits[i]
+		if !l.matches(a.projectID, a.metadata) {
+			continue
+		}
+		if err := g.check(i, a, now); err != nil {
+			return nil, err
+		}
+	}
+	g.pending[a] = struct{}{}
+	return func(sb *models.Sandbox, err error) {
+		g.mu.Lock()
+		defer g.mu.Unlock()
+		delete(g.pending, a)
+		if err != nil || sb == nil {
+			return
+		}
+		if _, ok := g.accounts[sb.SandboxID]; ok {
+			// A reconciliation has already accounted for it
+			return
+		}
+		a.id = sb.SandboxID
+		if sb.ProjectID != "" {
+			a.projectID = sb.ProjectID
+		}
+		if sb.Metadata != nil {
+			a.metadata = sb.Metadata
+		}
+		if cpu, memoryMB, storageGB := usage.Resources(sb); cpu > 0 {
+			a.cpu, a.memoryMB, a.storageGB = cpu, memoryMB, storageGB
+		}
+		if !sb.CreatedAt.IsZero() {
+			a.createdAt = sb.CreatedAt
+		}
+		a.recorded = g.opts.Now()
+		g.accounts[a.id] = a
+	}, nil
+}
+
+// reconcileFirst reconciles if the guard never has. Concurrent first creations wait for one
+// reconciliation instead of each listing the sandboxes; a failed one is retried by the next.
+func (g *Guard) reconcileFirst(ctx context.Context) error {
+	if g.isReconciled() {
+		return nil
+	}
+	g.first.Lock()
+	defer g.first.Unlock()
+	if g.isReconciled() {
+		return nil
+	}
+	if _, err := g.Reconcile(ctx); err != nil && !errors.Is(err, errPause) {
+		return err
+	}
+	return nil
+}
+
+func (g *Guard) isReconciled() bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return !g.reconciled.IsZero()
+}
+
+// check returns an *ExceededError if adding a to limit i would cross it
+func (g *Guard) check(i int, a *account, now time.Time) error {
+	l := &g.limits[i]
+	used := g.usage(i, now)
+	for _, c := range []struct {
+		resource  Resource
+		used, add float64
+		max       int
+	}{
+		{ResourceSandboxes, float64(used.Sandboxes), 1, l.MaxSandboxes},
+		{ResourceCPU, float64(used.CPU), float64(a.cpu), l.MaxCPU},
+		{ResourceMemory, float64(used.MemoryMB), float64(a.memoryMB), l.MaxMemoryMB},
+	} {
+		if c.max > 0 && c.used+c.add > float64(c.max) {
+			return &ExceededError{Limit: l.name(), Resource: c.resource, Used: c.used, Requested: c.add, Max: float64(c.max)}
+		}
+	}
+	if l.MaxSpend > 0 && used.Spend >= l.MaxSpend {
+		return &ExceededError{Limit: l.name(), Resource: ResourceSpend, Used: used.Spend, Max: l.MaxSpend}
+	}
+	return nil
+}
+
+// usage returns the local account of limit i, pending creations included. Callers hold mu.
+func (g *Guard) usage(i int, now time.Time) LimitStatus {
+	l := &g.limits[i]
+	s := LimitStatus{Limit: l.name(), Spend: g.spend[i]}
+	add := func(a *account) {
+		if !l.matches(a.projectID, a.metadata) {
+			return
+		}
+		if a.active {
+			s.Sandboxes++
+			s.CPU += a.cpu
+			s.MemoryMB += a.memoryMB
+		}
+		if now.After(a.since) {
+			s.Spend += g.hourlyCost(a) * now.Sub(a.since).Hours()
+		}
+	}
+	for _, a := range g.accounts {
+		add(a)
+	}
+	for a := range g.pending {
+		add(a)
+	}
+	return s
+}
+
+// hourlyCost estimates what a sandbox costs per hour in its current state
+func (g *Guard) hourlyCost(a *account) float64 {
+	storage := float64(a.storageGB) / 24
+	if !a.active {
+		return g.opts.Prices.Cost(0, 0, storage)
+	}
+	return g.opts.Prices.Cost(float64(a.cpu), float64(a.memoryMB)/1024, storage)
+}
+
+// Status returns the local account of every limit without listing sandboxes
+func (g *Guard) Status() *Status {
+	now := g.opts.Now()
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.status(now)
+}
+
+// status builds the status of every limit. Callers hold mu.
+func (g *Guard) status(now time.Time) *Status {
+	s := &Status{At: now, Reconciled: g.reconciled, SpendSince: g.spendSince(now), Limits: []LimitStatus{}}
+	for i := range g.limits {
+		ls := g.usage(i, now)
+		ls.Exceeded = g.limits[i].exceeded(ls)
+		s.Limits = append(s.Limits, ls)
+	}
+	return s
+}
+
+// exceeded returns the resources whose usage crossed the limit
+func (l *limit) exceeded(s LimitStatus) []Resource {
+	var crossed []Resource
+	if l.MaxSandboxes > 0 && s.Sandboxes > l.MaxSandboxes {
+		crossed = append(crossed, ResourceSandboxes)
+	}
+	if l.MaxCPU > 0 && s.CPU > l.MaxCPU {
+		crossed = append(crossed, ResourceCPU)
+	}
+	if l.MaxMemoryMB > 0 && s.MemoryMB > l.MaxMemoryMB {
+		crossed = append(crossed, ResourceMemory)
+	}
+	if l.MaxSpend > 0 && s.Spend >= l.MaxSpend {
+		crossed = append(crossed, ResourceSpend)
+	}
+	return crossed
+}
+
+// spendSince returns when spend starts counting at now
+func (g *Guard) spendSince(now time.Time) time.Time {
+	if !g.opts.SpendSince.IsZero() {
+		return g.opts.SpendSince
+	}
+	now = now.UTC()
+	return time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// errPause marks failed pauses, which do not invalidate a reconciliation
+var errPause = errors.New("pause")
+
+// Reconcile lists the sandboxes, replaces the local account with what the API reports and
+// re-estimates spend. Creations recorded while it lists, and those still pending, are kept.
+// With PauseNewest set it then pauses the newest sandboxes of every crossed limit; failed
+// pauses are recorded in the status and returned joined as the error.
+func (g *Guard) Reconcile(ctx context.Context) (*Status, error) {
+	spend := false
+	for _, l := range g.limits {
+		spend = spend || l.MaxSpend > 0
+	}
+	// Creations recorded from here on may be missing from the list
+	started := g.opts.Now()
+	// Spend includes sandboxes that have since terminated
+	list, err := g.client.ListAll(ctx, models.ListSandboxesOptions{IncludeTerminated: spend})
+	if err != nil {
+		return nil, err
+	}
+	now := g.opts.Now()
+	since := g.spendSince(now)
+
+	accounts := make(map[string]*account)
+	for i := range list {
+		sb := &list[i]
+		if models.IsTerminalStatus(sb.Status) || sb.Status == models.StatusTerminating {
+			continue
+		}
+		cpu, memoryMB, storageGB := usage.Resources(sb)
+		accounts[sb.SandboxID] = &account{
+			id:        sb.SandboxID,
+			projectID: sb.ProjectID,
+			metadata:  sb.Metadata,
+			cpu:       cpu,
+			memoryMB:  memoryMB,
+			storageGB: storageGB,
+			active:    sb.Status == models.StatusStarting || sb.Status == models.StatusRunning || sb.Status == models.StatusPausing,
+			createdAt: sb.CreatedAt,
+			since:     now,
+		}
+	}
+	spent := make([]float64, len(g.limits))
+	for i := range g.limits {
+		l := &g.limits[i]
+		if l.MaxSpend == 0 || !now.After(since) {
+			continue
+		}
+		var matching []models.Sandbox
+		for _, sb := range list {
+			if l.matches(sb.ProjectID, sb.Metadata) {
+				matching = append(matching, sb)
+			}
+		}
+		report, err := usage.Aggregate(matching, usage.Options{From: since, To: now, Prices: g.opts.Prices, Now: func() time.Time { return now }})
+		if err != nil {
+			return nil, err
+		}
+		spent[i] = report.Total.Cost
+	}
+
+	g.mu.Lock()
+	for id, a := range g.accounts {
+		if _, listed := accounts[id]; !listed && !a.recorded.IsZero() && !a.recorded.Before(started) {
+			accounts[id] = a
+		}
+	}
+	// Pending creations stay in g.pending until the API answers
+	g.accounts, g.spend, g.reconciled = accounts, spent, now
+	status := g.status(now)
+	var victims []*account
+	if g.opts.PauseNewest {
+		victims = g.victims(status)
+	}
+	g.mu.Unlock()
+
+	var errs []error
+	for _, a := range victims {
+		if _, err := g.client.Pause(ctx, a.id); err != nil {
+			status.Failed = append(status.Failed, PauseFailure{SandboxID: a.id, Error: err.Error()})
+			errs = append(errs, fmt.Errorf("%w %s: %w", errPause, a.id, err))
+			continue
+		}
+		g.mu.Lock()
+		a.active = false
+		g.mu.Unlock()
+		status.Paused = append(status.Paused, a.id)
+	}
+	if len(status.Paused) > 0 {
+		// Report the limits as they stand after pausing
+		g.mu.Lock()
+		status.Limits = g.status(now).Limits
+		g.mu.Unlock()
+	}
+	return status, errors.Join(errs...)
+}
+
+// victims picks the newest active, non-exempt sandboxes to pause until every crossed limit is
+// respected again. Callers hold mu.
+func (g *Guard) victims(status *Status) []*account {
+	var candidates []*account
+	for _, a := range g.accounts {
+		if value, ok := a.metadata[g.opts.ExemptLabel]; a.active && (!ok || value == "false") {
+			candidates = append(candidates, a)
+		}
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		if !candidates[i].createdAt.Equal(candidates[j].createdAt) {
+			return candidates[i].createdAt.After(candidates[j].createdAt)
+		}
+		return candidates[i].id < candidates[j].id
+	})
+
+	chosen := make(map[*account]bool)
+	var victims []*account
+	for i, ls := range status.Limits {
+		if len(ls.Exceeded) == 0 {
+			continue
+		}
+		l := &g.limits[i]
+		// Start from the usage left once the sandboxes already chosen are paused
+		for a := range chosen {
+			if l.matches(a.projectID, a.metadata) {
+				ls.Sandboxes--
+				ls.CPU -= a.cpu
+				ls.MemoryMB -= a.memoryMB
+			}
+		}
+		// Pausing does not undo spend, so a spent budget pauses every candidate
+		spend := l.MaxSpend > 0 && ls.Spend >= l.MaxSpend
+		ls.Spend = 0
+		for _, a := range candidates {
+			if !spend && len(l.exceeded(ls)) == 0 {
+				break
+			}
+			if chosen[a] || !l.matches(a.projectID, a.metadata) {
+				continue
+			}
+			chosen[a] = true
+			victims = append(victims, a)
+			ls.Sandboxes--
+			ls.CPU -= a.cpu
+			ls.MemoryMB -= a.memoryMB
+		}
+	}
+	return victims
+}
+
+// Run reconciles every Interval until ctx ends, passing each status and error to onStatus.
+// A failed reconciliation does not stop Run; it returns the context error.
+func (g *Guard) Run(ctx context.Context, onStatus func(*Status, error)) error {
+	ticker := time.NewTicker(g.opts.Interval)
+	defer ticker.Stop()
+	for {
+		status, err := g.Reconcile(ctx)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if onStatus != nil {
+			onStatus(status, err)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
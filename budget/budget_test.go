@@ -0,0 +1,375 @@
+package budget
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/scalebox/scalebox-sdk-golang/api/sandboxes"
+	"github.com/scalebox/scalebox-sdk-golang/client"
+	"github.com/scalebox/scalebox-sdk-golang/models"
+	"github.com/scalebox/scalebox-sdk-golang/usage"
+)
+
+var now = time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+// fleetServer is a fake API that lists, creates and pauses sandboxes
+type fleetServer struct {
+	mu        sync.Mutex
+	sandboxes []models.Sandbox
+	clock     func() time.Time
+	failures  map[string]bool // Sandboxes whose pause fails
+	paused    []string
+	created   int
+	lists     int
+	onList    func() // Runs once after the next list is taken, before it is returned
+}
+
+func (s *fleetServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	w.Header().Set("Content-Type", "application/json")
+	switch {
+	case r.Method == "GET":
+		s.lists++
+		listed := append([]models.Sandbox(nil), s.sandboxes...)
+		if hook := s.onList; hook != nil {
+			s.onList = nil
+			s.mu.Unlock()
+			hook()
+			s.mu.Lock()
+		}
+		json.NewEncoder(w).Encode(models.SandboxListResponse{Sandboxes: listed})
+	case r.URL.Path == "/v1/sandboxes":
+		var req models.CreateSandboxRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		s.created++
+		at := s.clock()
+		sb := models.Sandbox{
+			SandboxID: fmt.Sprintf("new-%d", s.created),
+			ProjectID: req.ProjectID,
+			Status:    models.StatusRunning,
+			CPUCount:  req.CPUCount,
+			MemoryMB:  req.MemoryMB,
+			StorageGB: req.StorageGB,
+			Metadata:  req.Metadata,
+			CreatedAt: at,
+			StartedAt: &at,
+		}
+		s.sandboxes = append(s.sandboxes, sb)
+		json.NewEncoder(w).Encode(sb)
+	case strings.HasSuffix(r.URL.Path, "/pause"):
+		id := strings.Split(r.URL.Path, "/")[3]
+		if s.failures[id] {
+			w.WriteHeader(http.StatusConflict)
+			json.NewEncoder(w).Encode(map[string]string{"error": "busy"})
+			return
+		}
+		s.paused = append(s.paused, id)
+		for i := range s.sandboxes {
+			if s.sandboxes[i].SandboxID == id {
+				s.sandboxes[i].Status = models.StatusPaused
+			}
+		}
+		json.NewEncoder(w).Encode(models.Sandbox{SandboxID: id, Status: models.StatusPaused})
+	}
+}
+
+func running(id, project, team string, cpu int, age time.Duration) models.Sandbox {
+	started := now.Add(-age)
+	return models.Sandbox{
+		SandboxID: id,
+		ProjectID: project,
+		Status:    models.StatusRunning,
+		CPUCount:  cpu,
+		MemoryMB:  1024 * cpu,
+		StorageGB: 10,
+		CreatedAt: started,
+		StartedAt: &started,
+		Metadata:  map[string]string{"team": team},
+	}
+}
+
+func newFleet(t *testing.T, sbs ...models.Sandbox) (*fleetServer, *sandboxes.Client, *time.Time) {
+	clock := now
+	fake := &fleetServer{sandboxes: sbs, clock: func() time.Time { return clock }, failures: make(map[string]bool)}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+	return fake, sandboxes.NewClient(client.NewClient(server.URL, "test-api-key")), &clock
+}
+
+func request(project, team string, cpu int) models.CreateSandboxRequest {
+	return models.CreateSandboxRequest{Template: "base", ProjectID: project, CPUCount: cpu, MemoryMB: 1024 * cpu, Metadata: map[string]string{"team": team}}
+}
+
+func TestGuardRejectsCreations(t *testing.T) {
+	paused := running("sbx-3", "prj-1", "ml", 8, 3*time.Hour)
+	paused.Status = models.StatusPaused
+	fake, c, clock := newFleet(t,
+		running("sbx-1", "prj-1", "ml", 2, 2*time.Hour),
+		running("sbx-2", "prj-1", "ml", 2, time.Hour),
+		paused,
+	)
+	guard, err := New(c, Options{
+		Limits: []Limit{
+			{Selector: "team=ml", MaxSandboxes: 3, MaxCPU: 8},
+			{Name: "batch", ProjectID: "prj-2", MaxCPU: 4},
+		},
+		DefaultProjectID: "prj-2",
+		Now:              func() time.Time { return *clock },
+	})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	guarded := guard.Client()
+	ctx := context.Background()
+
+	// The first creation reconciles; paused sandboxes do not count
+	if _, err := guarded.Create(ctx, request("prj-1", "ml", 2)); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	_, err = guarded.Create(ctx, request("prj-1", "ml", 1))
+	var exceeded *ExceededError
+	if !errors.Is(err, ErrBudgetExceeded) || !errors.As(err, &exceeded) {
+		t.Fatalf("Expected ErrBudgetExceeded, got %v", err)
+	}
+	if exceeded.Limit != "selector=team=ml" || exceeded.Resource != ResourceSandboxes || exceeded.Used != 3 || exceeded.Max != 3 {
+		t.Errorf("Unexpected error %+v", exceeded)
+	}
+	if _, err := guarded.Create(ctx, request("prj-1", "web", 2)); err != nil {
+		t.Errorf("Sandboxes outside the limits are not limited, got %v", err)
+	}
+
+	// Concurrent creations reserve their resources; requests without a project count towards prj-2
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	admitted, rejected := 0, 0
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := guarded.Create(ctx, request("", "batch", 1))
+			mu.Lock()
+			defer mu.Unlock()
+			if err == nil {
+				admitted++
+			} else if errors.Is(err, ErrBudgetExceeded) {
+				rejected++
+			}
+		}()
+	}
+	wg.Wait()
+	if admitted != 4 || rejected != 6 {
+		t.Errorf("Admitted %d and rejected %d concurrent creations, want 4 and 6", admitted, rejected)
+	}
+
+	status := guard.Status()
+	if ml := status.Limits[0]; ml.Sandboxes != 3 || ml.CPU != 6 || ml.MemoryMB != 6144 || status.Exceeded() {
+		t.Errorf("Unexpected status %+v", status.Limits)
+	}
+	if fake.created != 6 {
+		t.Errorf("Created %d sandboxes, want 6", fake.created)
+	}
+	// The unguarded client is not limited
+	if _, err := c.Create(ctx, request("prj-1", "ml", 1)); err != nil {
+		t.Errorf("Unguarded Create failed: %v", err)
+	}
+}
+
+func TestReconcilePausesNewest(t *testing.T) {
+	exempt := running("sbx-6", "prj-1", "ml", 1, time.Minute)
+	exempt.Metadata[DefaultExemptLabel] = "true"
+	fake, c, clock := newFleet(t,
+		running("sbx-1", "prj-1", "ml", 1, 5*time.Hour),
+		running("sbx-2", "prj-1", "ml", 1, 4*time.Hour),
+		running("sbx-3", "prj-1", "ml", 1, 3*time.Hour),
+		running("sbx-4", "prj-1", "ml", 1, 2*time.Hour),
+		running("sbx-5", "prj-1", "ml", 1, time.Hour),
+		running("sbx-7", "prj-1", "web", 4, time.Minute),
+		exempt,
+	)
+	fake.failures["sbx-4"] = true
+	guard, err := New(c, Options{
+		Limits:      []Limit{{Selector: "team=ml", MaxSandboxes: 3}},
+		PauseNewest: true,
+		Now:         func() time.Time { return *clock },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	status, err := guard.Reconcile(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "sbx-4") {
+		t.Errorf("Expected the failed pause to be returned, got %v", err)
+	}
+	// The exempt sandbox counts but is kept; sbx-4 fails, so sbx-3 is paused too
+	if got := strings.Join(status.Paused, ","); got != "sbx-5,sbx-3" {
+		t.Errorf("Paused %s, want sbx-5,sbx-3", got)
+	}
+	if len(status.Failed) != 1 || status.Failed[0].SandboxID != "sbx-4" {
+		t.Errorf("Unexpected failures %+v", status.Failed)
+	}
+	if l := status.Limits[0]; l.Sandboxes != 4 || len(l.Exceeded) != 1 {
+		t.Errorf("Unexpected status after pausing %+v", l)
+	}
+
+	var text bytes.Buffer
+	status.WriteText(&text)
+	if !strings.Contains(text.String(), "EXCEEDED (sandboxes)") || !strings.Contains(text.String(), "failed to pause sbx-4") {
+		t.Errorf("Unexpected text status:\n%s", text.String())
+	}
+	var js bytes.Buffer
+	status.WriteJSON(&js)
+	var decoded Status
+	if err := json.Unmarshal(js.Bytes(), &decoded); err != nil || len(decoded.Paused) != 2 {
+		t.Errorf("JSON status does not round trip: %v\n%s", err, js.String())
+	}
+
+	// Once the pause succeeds, the limit is respected
+	delete(fake.failures, "sbx-4")
+	status, err = guard.Reconcile(context.Background())
+	if err != nil || strings.Join(status.Paused, ",") != "sbx-4" || status.Exceeded() {
+		t.Errorf("Unexpected second reconciliation %+v, %v", status, err)
+	}
+}
+
+func TestReconcileKeepsConcurrentCreations(t *testing.T) {
+	fake, c, clock := newFleet(t, running("sbx-1", "prj-1", "ml", 1, time.Hour))
+	guard, err := New(c, Options{
+		Limits: []Limit{{Selector: "team=ml", MaxSandboxes: 2}},
+		Now:    func() time.Time { return *clock },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	if _, err := guard.Reconcile(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	// A creation finishing while Reconcile lists is missing from the list but still counts
+	guarded := guard.Client()
+	fake.onList = func() {
+		if _, err := guarded.Create(ctx, request("prj-1", "ml", 1)); err != nil {
+			t.Errorf("Create failed: %v", err)
+		}
+	}
+	status, err := guard.Reconcile(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l := status.Limits[0]; l.Sandboxes != 2 {
+		t.Errorf("Counted %d sandboxes after reconciling, want 2", l.Sandboxes)
+	}
+	if _, err := guarded.Create(ctx, request("prj-1", "ml", 1)); !errors.Is(err, ErrBudgetExceeded) {
+		t.Errorf("Expected ErrBudgetExceeded, got %v", err)
+	}
+}
+
+func TestFirstCreationsReconcileOnce(t *testing.T) {
+	fake, c, clock := newFleet(t, running("sbx-1", "prj-1", "ml", 1, time.Hour))
+	guard, err := New(c, Options{
+		Limits: []Limit{{Selector: "team=ml", MaxSandboxes: 10}},
+		Now:    func() time.Time { return *clock },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	guarded := guard.Client()
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := guarded.Create(context.Background(), request("prj-1", "ml", 1)); err != nil {
+				t.Errorf("Create failed: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+	if fake.lists != 1 {
+		t.Errorf("Listed the sandboxes %d times for the first creations, want once", fake.lists)
+	}
+}
+
+func TestSpendLimit(t *testing.T) {
+	spent := running("sbx-old", "prj-1", "ml", 2, 20*time.Hour)
+	ended := now.Add(-16 * time.Hour)
+	spent.Status, spent.EndedAt, spent.TotalRunningSeconds = models.StatusTerminated, &ended, 4*3600
+	spent.StartedAt = nil
+	fake, c, clock := newFleet(t, spent, running("sbx-1", "prj-1", "ml", 1, time.Hour))
+	guard, err := New(c, Options{
+		Limits:      []Limit{{ProjectID: "prj-1", MaxSpend: 10}},
+		Prices:      usage.PriceSheet{Currency: "USD", VCPUHour: 1},
+		SpendSince:  now.Add(-24 * time.Hour),
+		PauseNewest: true,
+		Now:         func() time.Time { return *clock },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	guarded := guard.Client()
+	ctx := context.Background()
+
+	// 8 vCPU-hours by the terminated sandbox and 1 by the running one
+	if _, err := guarded.Create(ctx, request("prj-1", "ml", 1)); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if spend := guard.Status().Limits[0].Spend; spend != 9 {
+		t.Errorf("Spend = %g, want 9", spend)
+	}
+
+	// Between reconciliations two running vCPUs burn through the last unit in half an hour
+	*clock = now.Add(30 * time.Minute)
+	_, err = guarded.Create(ctx, request("prj-1", "ml", 1))
+	var exceeded *ExceededError
+	if !errors.As(err, &exceeded) || exceeded.Resource != ResourceSpend || exceeded.Used != 10 {
+		t.Fatalf("Expected the spend limit to be reached, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "estimated spend 10.00 has reached the limit of 10.00") {
+		t.Errorf("Unexpected message %q", err)
+	}
+
+	// Reconciliation agrees and pauses every running sandbox of the project
+	status, err := guard.Reconcile(ctx)
+	if err != nil {
+		t.Fatalf("Reconcile failed: %v", err)
+	}
+	if strings.Join(status.Paused, ",") != "new-1,sbx-1" || strings.Join(fake.paused, ",") != "new-1,sbx-1" {
+		t.Errorf("Paused %v, want new-1,sbx-1", status.Paused)
+	}
+	if l := status.Limits[0]; l.Sandboxes != 0 || l.Spend != 10 {
+		t.Errorf("Unexpected status %+v", l)
+	}
+}
+
+func TestOptionsValidate(t *testing.T) {
+	_, err := New(nil, Options{
+		Limits: []Limit{
+			{Selector: "team in (ml", MaxCPU: 1},
+			{ProjectID: "prj-1"},
+			{MaxSpend: 100},
+		},
+		Interval: -time.Second,
+	})
+	verr, ok := err.(*models.ValidationError)
+	if !ok {
+		t.Fatalf("Expected *models.ValidationError, got %v", err)
+	}
+	for _, field := range []string{"limits[0].selector", "limits[1]", "prices", "interval"} {
+		if !verr.HasField(field) {
+			t.Errorf("Expected an error for %s, got %v", field, verr)
+		}
+	}
+	if _, err := New(nil, Options{}); err == nil {
+		t.Error("Expected a guard without limits to be rejected")
+	}
+}
@@ -0,0 +1,77 @@
+package budget
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// Status is the usage of every limit at a point in time
+type Status struct {
+	At         time.Time      `json:"at"`
+	Reconciled time.Time      `json:"reconciled"` // Last time the account was corrected from List
+	SpendSince time.Time      `json:"spend_since"`
+	Limits     []LimitStatus  `json:"limits"`
+	Paused     []string       `json:"paused,omitempty"` // Sandboxes Reconcile paused, newest first
+	Failed     []PauseFailure `json:"failed,omitempty"`
+}
+
+// LimitStatus is the usage of one limit
+type LimitStatus struct {
+	Limit     string     `json:"limit"`
+	Sandboxes int        `json:"sandboxes"` // Active sandboxes
+	CPU       int        `json:"cpu"`
+	MemoryMB  int        `json:"memory_mb"`
+	Spend     float64    `json:"spend"`              // Estimated since SpendSince
+	Exceeded  []Resource `json:"exceeded,omitempty"` // Resources over the limit, or spend that reached it
+}
+
+// PauseFailure is a sandbox Reconcile failed to pause
+type PauseFailure struct {
+	SandboxID string `json:"sandbox_id"`
+	Error     string `json:"error"`
+}
+
+// Exceeded reports whether any limit is crossed
+func (s *Status) Exceeded() bool {
+	for _, l := range s.Limits {
+		if len(l.Exceeded) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// WriteJSON writes the status as indented JSON, for machine consumption
+func (s *Status) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(s)
+}
+
+// WriteText writes a line per limit and per paused sandbox, for humans
+func (s *Status) WriteText(w io.Writer) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Budget at %s (spend since %s)\n", s.At.Format(time.RFC3339), s.SpendSince.Format(time.RFC3339))
+	for _, l := range s.Limits {
+		fmt.Fprintf(&b, "  %s: %d sandboxes, %d CPU, %d MB, spend %.2f", l.Limit, l.Sandboxes, l.CPU, l.MemoryMB, l.Spend)
+		if len(l.Exceeded) > 0 {
+			exceeded := make([]string, len(l.Exceeded))
+			for i, r := range l.Exceeded {
+				exceeded[i] = string(r)
+			}
+			fmt.Fprintf(&b, " EXCEEDED (%s)", strings.Join(exceeded, ", "))
+		}
+		b.WriteString("\n")
+	}
+	for _, id := range s.Paused {
+		fmt.Fprintf(&b, "  paused %s\n", id)
+	}
+	for _, f := range s.Failed {
+		fmt.Fprintf(&b, "  failed to pause %s: %s\n", f.SandboxID, f.Error)
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"log"
 	"net/http"
 	"net/url"
 	"time"
@@ -16,6 +17,11 @@ type Client struct {
 	BaseURL    string
 	APIKey     string
 	HTTPClient *http.Client
+
+	// SchemaMode enables schema-drift detection when parsing responses
+	SchemaMode SchemaMode
+	// OnSchemaWarning receives drift warnings in SchemaWarn mode, defaults to logging them
+	OnSchemaWarning func(SchemaWarning)
 }
 
 // NewClient creates a new Scalebox API client
@@ -40,6 +46,19 @@ func NewClientWithHTTPClient(baseURL, apiKey string, httpClient *http.Client) *C
 
 // DoRequest performs an HTTP request
 func (c *Client) DoRequest(ctx context.Context, method, path string, body interface{}, queryParams map[string]string) (*http.Response, error) {
+	var query url.Values
+	if len(queryParams) > 0 {
+		query = make(url.Values, len(queryParams))
+		for k, v := range queryParams {
+			query.Set(k, v)
+		}
+	}
+	return c.DoRequestValues(ctx, method, path, body, query, nil)
+}
+
+// DoRequestValues performs an HTTP request with query parameters that may repeat
+// (e.g. status=running&status=paused) and additional request headers such as If-Match
+func (c *Client) DoRequestValues(ctx context.Context, method, path string, body interface{}, query url.Values, headers map[string]string) (*http.Response, error) {
 	// Build URL
 	u, err := url.Parse(c.BaseURL)
 	if err != nil {
@@ -48,10 +67,12 @@ func (c *Client) DoRequest(ctx context.Context, method, path string, body interf
 	u.Path = path
 
 	// Add query parameters
-	if len(queryParams) > 0 {
+	if len(query) > 0 {
 		q := u.Query()
-		for k, v := range queryParams {
-			q.Set(k, v)
+		for k, values := range query {
+			for _, v := range values {
+				q.Add(k, v)
+			}
 		}
 		u.RawQuery = q.Encode()
 	}
@@ -75,6 +96,9 @@ func (c *Client) DoRequest(ctx context.Context, method, path string, body interf
 	// Set headers
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("X-API-KEY", c.APIKey)
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
 
 	// Perform request
 	resp, err := c.HTTPClient.Do(req)
@@ -143,15 +167,39 @@ func (c *Client) ParseResponse(resp *http.Response, target interface{}) error {
 			if err := json.Unmarshal(wrapped.Data, target); err != nil {
 				return fmt.Errorf("failed to parse response data: %w", err)
 			}
-			return nil
+			return c.checkSchema(wrapped.Data, target)
 		}
 
 		// Not wrapped, parse directly
 		if err := json.Unmarshal(body, target); err != nil {
 			return fmt.Errorf("failed to parse response: %w", err)
 		}
+		return c.checkSchema(body, target)
+	}
+
+	return nil
+}
+
+// checkSchema reports drift between a decoded payload and its target according to SchemaMode
+func (c *Client) checkSchema(data []byte, target interface{}) error {
+	if c.SchemaMode == SchemaIgnore {
+		return nil
 	}
 
+	warnings, err := CheckSchema(data, target)
+	if err != nil || len(warnings) == 0 {
+		return nil
+	}
+	if c.SchemaMode == SchemaStrict {
+		return &SchemaDriftError{Warnings: warnings}
+	}
+	for _, w := range warnings {
+		if c.OnSchemaWarning != nil {
+			c.OnSchemaWarning(w)
+		} else {
+			log.Printf("scalebox: response schema drift: %s", w)
+		}
+	}
 	return nil
 }
 
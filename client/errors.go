@@ -33,6 +33,14 @@ func IsForbidden(err error) bool {
 	return false
 }
 
+// IsConflict checks if the error is a 409 Conflict or 412 Precondition Failed error
+func IsConflict(err error) bool {
+	if apiErr, ok := err.(*APIError); ok {
+		return apiErr.StatusCode == 409 || apiErr.StatusCode == 412
+	}
+	return false
+}
+
 // StatusCode returns the HTTP status code from an error if it's an APIError
 func StatusCode(err error) int {
 	if apiErr, ok := err.(*APIError); ok {
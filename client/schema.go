@@ -0,0 +1,162 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// SchemaMode controls schema-drift detection in ParseResponse
+type SchemaMode int
+
+const (
+	// SchemaIgnore decodes responses without checking for drift (default)
+	SchemaIgnore SchemaMode = iota
+	// SchemaWarn reports drift through Client.OnSchemaWarning and still succeeds
+	SchemaWarn
+	// SchemaStrict fails ParseResponse with a *SchemaDriftError after decoding the target
+	SchemaStrict
+)
+
+// SchemaWarningKind classifies a schema drift warning
+type SchemaWarningKind string
+
+// Schema drift warning kinds
+const (
+	UnknownField SchemaWarningKind = "unknown_field" // Sent by the server but not modelled
+	MissingField SchemaWarningKind = "missing_field" // Modelled as required but not sent by the server
+)
+
+// SchemaWarning describes a difference between a response and the model it was decoded into
+type SchemaWarning struct {
+	Kind SchemaWarningKind
+	Path string // JSON path, e.g. "sandboxes[0].new_field"
+	Type string // Go type that was being decoded
+}
+
+func (w SchemaWarning) String() string {
+	return fmt.Sprintf("%s %s (%s)", w.Kind, w.Path, w.Type)
+}
+
+// SchemaDriftError is returned by ParseResponse in SchemaStrict mode when the response
+// does not match the model. The target has still been decoded.
+type SchemaDriftError struct {
+	Warnings []SchemaWarning
+}
+
+func (e *SchemaDriftError) Error() string {
+	msgs := make([]string, len(e.Warnings))
+	for i, w := range e.Warnings {
+		msgs[i] = w.String()
+	}
+	return "response schema drift: " + strings.Join(msgs, "; ")
+}
+
+// CheckSchema compares a JSON document with the JSON fields of target's type.
+// It reports fields present in data but not modelled, and non-omitempty fields that are absent.
+// Maps, interfaces and json.RawMessage values are not inspected.
+func CheckSchema(data []byte, target interface{}) ([]SchemaWarning, error) {
+	var doc interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	var warnings []SchemaWarning
+	checkValue(doc, reflect.TypeOf(target), "", &warnings)
+	return warnings, nil
+}
+
+type schemaField struct {
+	typ       reflect.Type
+	omitempty bool
+}
+
+var rawMessageType = reflect.TypeOf(json.RawMessage(nil))
+
+func checkValue(doc interface{}, t reflect.Type, path string, warnings *[]SchemaWarning) {
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t == rawMessageType {
+		return
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		obj, ok := doc.(map[string]interface{})
+		if !ok {
+			return
+		}
+		fields := make(map[string]schemaField)
+		collectFields(t, fields)
+
+		keys := make([]string, 0, len(obj))
+		for k := range obj {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			f, ok := fields[k]
+			if !ok {
+				*warnings = append(*warnings, SchemaWarning{Kind: UnknownField, Path: joinPath(path, k), Type: t.String()})
+				continue
+			}
+			checkValue(obj[k], f.typ, joinPath(path, k), warnings)
+		}
+
+		names := make([]string, 0, len(fields))
+		for name := range fields {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if _, ok := obj[name]; !ok && !fields[name].omitempty {
+				*warnings = append(*warnings, SchemaWarning{Kind: MissingField, Path: joinPath(path, name), Type: t.String()})
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		items, ok := doc.([]interface{})
+		if !ok {
+			return
+		}
+		for i, item := range items {
+			checkValue(item, t.Elem(), fmt.Sprintf("%s[%d]", path, i), warnings)
+		}
+	}
+}
+
+func collectFields(t reflect.Type, fields map[string]schemaField) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		if f.Anonymous && name == "" {
+			ft := f.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				collectFields(ft, fields)
+				continue
+			}
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		fields[name] = schemaField{typ: f.Type, omitempty: strings.Contains(opts, "omitempty")}
+	}
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
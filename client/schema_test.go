@@ -0,0 +1,57 @@
+package client
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"testing"
+)
+
+type schemaTestItem struct {
+	ID   string `json:"id"`
+	Note string `json:"note,omitempty"`
+}
+
+type schemaTestList struct {
+	Items []schemaTestItem `json:"items"`
+	Total int              `json:"total"`
+}
+
+func TestCheckSchema(t *testing.T) {
+	warnings, err := CheckSchema([]byte(`{"items": [{"id": "a"}, {"id": "b", "color": "red"}], "next": null}`), &schemaTestList{})
+	if err != nil {
+		t.Fatalf("CheckSchema failed: %v", err)
+	}
+
+	expected := []SchemaWarning{
+		{Kind: UnknownField, Path: "items[1].color", Type: "client.schemaTestItem"},
+		{Kind: UnknownField, Path: "next", Type: "client.schemaTestList"},
+		{Kind: MissingField, Path: "total", Type: "client.schemaTestList"},
+	}
+	if len(warnings) != len(expected) {
+		t.Fatalf("Expected %v, got %v", expected, warnings)
+	}
+	for i := range expected {
+		if warnings[i] != expected[i] {
+			t.Errorf("Warning %d: expected %v, got %v", i, expected[i], warnings[i])
+		}
+	}
+}
+
+func TestParseResponseSchemaWarn(t *testing.T) {
+	var got []SchemaWarning
+	c := NewClient("http://localhost", "test-api-key")
+	c.SchemaMode = SchemaWarn
+	c.OnSchemaWarning = func(w SchemaWarning) { got = append(got, w) }
+
+	body := `{"success": true, "data": {"items": [], "total": 0, "cursor": "x"}}`
+	resp := &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewBufferString(body))}
+
+	var list schemaTestList
+	if err := c.ParseResponse(resp, &list); err != nil {
+		t.Fatalf("ParseResponse failed: %v", err)
+	}
+	if len(got) != 1 || got[0].Path != "cursor" {
+		t.Errorf("Expected one warning for cursor, got %v", got)
+	}
+}
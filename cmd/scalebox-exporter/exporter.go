@@ -0,0 +1,297 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/scalebox/scalebox-sdk-golang/api/sandboxes"
+	"github.com/scalebox/scalebox-sdk-golang/metrics"
+	"github.com/scalebox/scalebox-sdk-golang/models"
+)
+
+// openMetricsContentType is the content type of the OpenMetrics text format
+const openMetricsContentType = "application/openmetrics-text; version=1.0.0; charset=utf-8"
+
+// statuses are always reported by scalebox_sandboxes, so counts drop to zero instead of vanishing
+var statuses = []string{
+	models.StatusStarting, models.StatusRunning, models.StatusPausing, models.StatusPaused,
+	models.StatusTerminating, models.StatusTerminated, models.StatusFailed,
+}
+
+// options configures an exporter
+type options struct {
+	List        models.ListSandboxesOptions // Sandboxes to export
+	CacheTTL    time.Duration               // How long fetched metrics are reused
+	Lookback    time.Duration               // Metrics history requested per sandbox; the latest point is exported
+	Concurrency int                         // GetMetrics calls in flight at once
+	Timeout     time.Duration               // Deadline of one scrape
+	Now         func() time.Time
+}
+
+// cachedMetrics is the last metrics response of a sandbox
+type cachedMetrics struct {
+	resp      *models.SandboxMetricsResponse
+	fetchedAt time.Time
+}
+
+// exporter serves the metrics of a sandbox fleet to Prometheus
+type exporter struct {
+	client *sandboxes.Client
+	opts   options
+
+	scrapeMu sync.Mutex // Serializes scrapes so concurrent ones share the cache
+	mu       sync.Mutex
+	cache    map[string]cachedMetrics
+}
+
+func newExporter(c *sandboxes.Client, opts options) *exporter {
+	if opts.Now == nil {
+		opts.Now = time.Now
+	}
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = 1
+	}
+	return &exporter{client: c, opts: opts, cache: make(map[string]cachedMetrics)}
+}
+
+// sandboxMetrics is what a scrape found out about one sandbox
+type sandboxMetrics struct {
+	sandbox models.Sandbox
+	resp    *models.SandboxMetricsResponse // Nil when not running or when fetching failed
+}
+
+// scrape is the result of one collection
+type scrape struct {
+	up        bool
+	sandboxes []sandboxMetrics
+	errors    int // GetMetrics calls that failed
+	duration  time.Duration
+}
+
+func (e *exporter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if e.opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, e.opts.Timeout)
+		defer cancel()
+	}
+	s := e.collect(ctx)
+	w.Header().Set("Content-Type", openMetricsContentType)
+	writeOpenMetrics(w, s, e.opts.Now())
+}
+
+// collect lists the sandboxes and fetches the metrics of the running ones,
+// reusing cached metrics younger than CacheTTL
+func (e *exporter) collect(ctx context.Context) *scrape {
+	e.scrapeMu.Lock()
+	defer e.scrapeMu.Unlock()
+	start := e.opts.Now()
+	s := &scrape{}
+	defer func() { s.duration = e.opts.Now().Sub(start) }()
+
+	list, err := e.client.ListAll(ctx, e.opts.List)
+	if err != nil {
+		return s
+	}
+	s.up = true
+	s.sandboxes = make([]sandboxMetrics, len(list))
+	live := make(map[string]bool, len(list))
+	var (
+		wg  sync.WaitGroup
+		sem = make(chan struct{}, e.opts.Concurrency)
+		mu  sync.Mutex
+	)
+	for i := range list {
+		s.sandboxes[i].sandbox = list[i]
+		id := list[i].SandboxID
+		live[id] = true
+		if list[i].Status != models.StatusRunning {
+			continue
+		}
+		if resp, ok := e.cached(id, start); ok {
+			s.sandboxes[i].resp = resp
+			continue
+		}
+		wg.Add(1)
+		go func(i int, id string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			from := start.Add(-e.opts.Lookback)
+			resp, err := e.client.GetMetrics(ctx, id, &models.GetSandboxMetricsOptions{Start: &from})
+			if err != nil {
+				mu.Lock()
+				s.errors++
+				mu.Unlock()
+				return
+			}
+			s.sandboxes[i].resp = resp
+			e.mu.Lock()
+			e.cache[id] = cachedMetrics{resp: resp, fetchedAt: start}
+			e.mu.Unlock()
+		}(i, id)
+	}
+	wg.Wait()
+
+	// Forget sandboxes that are gone
+	e.mu.Lock()
+	for id := range e.cache {
+		if !live[id] {
+			delete(e.cache, id)
+		}
+	}
+	e.mu.Unlock()
+	return s
+}
+
+func (e *exporter) cached(id string, now time.Time) (*models.SandboxMetricsResponse, bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	c, ok := e.cache[id]
+	if !ok || now.Sub(c.fetchedAt) >= e.opts.CacheTTL {
+		return nil, false
+	}
+	return c.resp, true
+}
+
+// family is one metric family of the exposition
+type family struct {
+	name, typ, unit, help string
+	samples               []string
+}
+
+func (f *family) add(labels string, value float64) {
+	f.samples = append(f.samples, f.name+labels+" "+formatValue(value))
+}
+
+// writeOpenMetrics writes the scrape in the OpenMetrics text format
+func writeOpenMetrics(w io.Writer, s *scrape, now time.Time) error {
+	up := &family{name: "scalebox_up", typ: "gauge", help: "Whether listing sandboxes succeeded."}
+	duration := &family{name: "scalebox_scrape_duration_seconds", typ: "gauge", unit: "seconds", help: "Time taken to collect the sandbox metrics."}
+	fetchErrors := &family{name: "scalebox_scrape_metrics_errors", typ: "gauge", help: "Sandboxes whose metrics could not be fetched in this scrape."}
+	counts := &family{name: "scalebox_sandboxes", typ: "gauge", help: "Sandboxes by status."}
+	info := &family{name: "scalebox_sandbox_info", typ: "gauge", help: "Sandbox details, always 1."}
+	cpuCount := &family{name: "scalebox_sandbox_cpu_count", typ: "gauge", help: "Requested CPU count."}
+	cpuUsed := &family{name: "scalebox_sandbox_cpu_used_percent", typ: "gauge", help: "CPU usage in percent of the requested CPUs."}
+	memUsed := &family{name: "scalebox_sandbox_memory_used_bytes", typ: "gauge", unit: "bytes", help: "Memory in use."}
+	memTotal := &family{name: "scalebox_sandbox_memory_total_bytes", typ: "gauge", unit: "bytes", help: "Requested memory."}
+	diskUsed := &family{name: "scalebox_sandbox_disk_used_bytes", typ: "gauge", unit: "bytes", help: "Storage in use."}
+	diskTotal := &family{name: "scalebox_sandbox_disk_total_bytes", typ: "gauge", unit: "bytes", help: "Requested storage."}
+	uptime := &family{name: "scalebox_sandbox_uptime_seconds", typ: "gauge", unit: "seconds", help: "Time the sandbox has been running."}
+	remaining := &family{name: "scalebox_sandbox_remaining_lifetime_seconds", typ: "gauge", unit: "seconds", help: "Time until the sandbox times out."}
+
+	up.add("", boolValue(s.up))
+	duration.add("", s.duration.Seconds())
+	fetchErrors.add("", float64(s.errors))
+
+	// Without a listing there is nothing to count, so the fleet families stay empty rather than report zeros
+	if s.up {
+		byStatus := make(map[string]int)
+		for _, st := range statuses {
+			byStatus[st] = 0
+		}
+		for _, m := range s.sandboxes {
+			sb := &m.sandbox
+			byStatus[sb.Status]++
+			id := labelSet("sandbox_id", sb.SandboxID)
+			info.add(labelSet("sandbox_id", sb.SandboxID, "name", sb.Name, "project", project(sb), "template", template(sb), "status", sb.Status), 1)
+			cpuCount.add(id, float64(sb.CPUCount))
+			if sb.TimeoutAt != nil && !models.IsTerminalStatus(sb.Status) {
+				left := sb.TimeoutAt.Sub(now).Seconds()
+				if left < 0 {
+					left = 0
+				}
+				remaining.add(id, left)
+			}
+			if m.resp == nil {
+				continue
+			}
+			uptime.add(id, float64(m.resp.UptimeSeconds))
+			series := metrics.FromResponse(m.resp)
+			if len(series) == 0 {
+				continue
+			}
+			p := series[len(series)-1]
+			cpuUsed.add(id, p.CPUUsedPct)
+			memUsed.add(id, float64(p.MemUsed))
+			memTotal.add(id, float64(p.MemTotal))
+			diskUsed.add(id, float64(p.DiskUsed))
+			diskTotal.add(id, float64(p.DiskTotal))
+		}
+		keys := make([]string, 0, len(byStatus))
+		for st := range byStatus {
+			keys = append(keys, st)
+		}
+		sort.Strings(keys)
+		for _, st := range keys {
+			counts.add(labelSet("status", st), float64(byStatus[st]))
+		}
+	}
+
+	var b strings.Builder
+	for _, f := range []*family{up, duration, fetchErrors, counts, info, cpuCount, cpuUsed, memUsed, memTotal, diskUsed, diskTotal, uptime, remaining} {
+		fmt.Fprintf(&b, "# TYPE %s %s\n", f.name, f.typ)
+		if f.unit != "" {
+			fmt.Fprintf(&b, "# UNIT %s %s\n", f.name, f.unit)
+		}
+		fmt.Fprintf(&b, "# HELP %s %s\n", f.name, f.help)
+		for _, sample := range f.samples {
+			b.WriteString(sample)
+			b.WriteByte('\n')
+		}
+	}
+	b.WriteString("# EOF\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// labelSet formats label name and value pairs, e.g. {sandbox_id="sbx-1"}
+func labelSet(pairs ...string) string {
+	var b strings.Builder
+	b.WriteByte('{')
+	for i := 0; i < len(pairs); i += 2 {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(pairs[i])
+		b.WriteString(`="`)
+		b.WriteString(labelEscaper.Replace(pairs[i+1]))
+		b.WriteByte('"')
+	}
+	b.WriteByte('}')
+	return b.String()
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func formatValue(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+func boolValue(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+func project(sb *models.Sandbox) string {
+	if sb.ProjectName != nil && *sb.ProjectName != "" {
+		return *sb.ProjectName
+	}
+	return sb.ProjectID
+}
+
+func template(sb *models.Sandbox) string {
+	if sb.TemplateName != nil && *sb.TemplateName != "" {
+		return *sb.TemplateName
+	}
+	return sb.TemplateID
+}
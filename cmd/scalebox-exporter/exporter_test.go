@@ -0,0 +1,173 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/scalebox/scalebox-sdk-golang/api/sandboxes"
+	"github.com/scalebox/scalebox-sdk-golang/client"
+	"github.com/scalebox/scalebox-sdk-golang/models"
+)
+
+var now = time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+// fleetServer is a fake API listing fixed sandboxes and serving their metrics
+type fleetServer struct {
+	mu        sync.Mutex
+	sandboxes []models.Sandbox
+	fail      map[string]bool
+	calls     map[string]int // Metrics requests per sandbox
+	inFlight  int
+	maxSeen   int
+	delay     time.Duration
+}
+
+func (s *fleetServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if !strings.HasSuffix(r.URL.Path, "/metrics") {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		json.NewEncoder(w).Encode(models.SandboxListResponse{Sandboxes: s.sandboxes})
+		return
+	}
+	id := strings.Split(r.URL.Path, "/")[3]
+	s.mu.Lock()
+	s.calls[id]++
+	s.inFlight++
+	s.maxSeen = max(s.maxSeen, s.inFlight)
+	fail := s.fail[id]
+	s.mu.Unlock()
+	time.Sleep(s.delay)
+	s.mu.Lock()
+	s.inFlight--
+	s.mu.Unlock()
+
+	if fail {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"error": "boom"})
+		return
+	}
+	json.NewEncoder(w).Encode(models.SandboxMetricsResponse{
+		SandboxID:     id,
+		Status:        models.StatusRunning,
+		UptimeSeconds: 3600,
+		Metrics: []models.MetricsDataPoint{
+			{Timestamp: now.Add(-time.Minute), CPUCount: 2, CPUUsedPct: 10, MemUsed: 1 << 20, MemTotal: 4 << 20, DiskUsed: 1 << 30, DiskTotal: 10 << 30},
+			{Timestamp: now, CPUCount: 2, CPUUsedPct: 37.5, MemUsed: 2 << 20, MemTotal: 4 << 20, DiskUsed: 1 << 30, DiskTotal: 10 << 30},
+		},
+	})
+}
+
+func fleet() *fleetServer {
+	timeout := now.Add(90 * time.Second)
+	tpl := "python"
+	sbs := []models.Sandbox{
+		{SandboxID: "sbx-1", Name: `web "prod"`, ProjectID: "prj-1", TemplateID: "tpl-1", TemplateName: &tpl, Status: models.StatusRunning, CPUCount: 2, TimeoutAt: &timeout},
+		{SandboxID: "sbx-2", Name: "worker", ProjectID: "prj-1", TemplateID: "tpl-2", Status: models.StatusRunning, CPUCount: 2},
+		{SandboxID: "sbx-3", Name: "idle", ProjectID: "prj-2", TemplateID: "tpl-2", Status: models.StatusPaused, CPUCount: 4},
+	}
+	for i := 4; i <= 9; i++ {
+		sbs = append(sbs, models.Sandbox{SandboxID: "sbx-" + string(rune('0'+i)), Name: "batch", Status: models.StatusRunning, CPUCount: 1})
+	}
+	return &fleetServer{sandboxes: sbs, fail: make(map[string]bool), calls: make(map[string]int)}
+}
+
+func scrapeText(t *testing.T, h http.Handler) string {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	if ct := rec.Header().Get("Content-Type"); ct != openMetricsContentType {
+		t.Errorf("Content-Type = %q", ct)
+	}
+	body, _ := io.ReadAll(rec.Body)
+	return string(body)
+}
+
+func TestExporter(t *testing.T) {
+	fake := fleet()
+	fake.fail["sbx-2"] = true
+	fake.delay = 10 * time.Millisecond
+	server := httptest.NewServer(fake)
+	defer server.Close()
+	c := sandboxes.NewClient(client.NewClient(server.URL, "test-api-key"))
+	clock := now
+	e := newExporter(c, options{CacheTTL: 30 * time.Second, Lookback: time.Minute, Concurrency: 3, Now: func() time.Time { return clock }})
+
+	text := scrapeText(t, e)
+	for _, want := range []string{
+		"# TYPE scalebox_up gauge\n# HELP scalebox_up Whether listing sandboxes succeeded.\nscalebox_up 1\n",
+		"scalebox_scrape_metrics_errors 1\n",
+		`scalebox_sandboxes{status="running"} 8`,
+		`scalebox_sandboxes{status="paused"} 1`,
+		`scalebox_sandboxes{status="failed"} 0`,
+		`scalebox_sandbox_info{sandbox_id="sbx-1",name="web \"prod\"",project="prj-1",template="python",status="running"} 1`,
+		`scalebox_sandbox_cpu_count{sandbox_id="sbx-3"} 4`,
+		`scalebox_sandbox_cpu_used_percent{sandbox_id="sbx-1"} 37.5`,
+		`scalebox_sandbox_memory_used_bytes{sandbox_id="sbx-1"} 2097152`,
+		"# UNIT scalebox_sandbox_disk_total_bytes bytes\n",
+		`scalebox_sandbox_disk_total_bytes{sandbox_id="sbx-1"} 10737418240`,
+		`scalebox_sandbox_uptime_seconds{sandbox_id="sbx-1"} 3600`,
+		`scalebox_sandbox_remaining_lifetime_seconds{sandbox_id="sbx-1"} 90`,
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("Exposition lacks %q:\n%s", want, text)
+		}
+	}
+	if !strings.HasSuffix(text, "# EOF\n") {
+		t.Error("Exposition does not end with # EOF")
+	}
+	// Paused sandboxes and failed fetches export no usage
+	if strings.Contains(text, `scalebox_sandbox_uptime_seconds{sandbox_id="sbx-3"}`) || strings.Contains(text, `cpu_used_percent{sandbox_id="sbx-2"}`) {
+		t.Errorf("Unexpected usage samples:\n%s", text)
+	}
+	if fake.calls["sbx-3"] != 0 || fake.maxSeen > 3 {
+		t.Errorf("Fetched paused sandbox %d times, %d requests in flight (limit 3)", fake.calls["sbx-3"], fake.maxSeen)
+	}
+
+	// Within the TTL cached metrics are reused; failed fetches are retried
+	clock = now.Add(10 * time.Second)
+	scrapeText(t, e)
+	if fake.calls["sbx-1"] != 1 || fake.calls["sbx-2"] != 2 {
+		t.Errorf("Calls after a cached scrape = %v", fake.calls)
+	}
+	clock = now.Add(time.Minute)
+	fake.sandboxes = fake.sandboxes[:2]
+	text = scrapeText(t, e)
+	if fake.calls["sbx-1"] != 2 || len(e.cache) != 1 {
+		t.Errorf("Expected refetching after the TTL and sandboxes that are gone to be forgotten, calls %v, cache %d", fake.calls, len(e.cache))
+	}
+	if !strings.Contains(text, `scalebox_sandbox_remaining_lifetime_seconds{sandbox_id="sbx-1"} 30`) {
+		t.Errorf("Remaining lifetime not updated:\n%s", text)
+	}
+}
+
+func TestExporterListFailure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+	c := sandboxes.NewClient(client.NewClient(server.URL, "bad-key"))
+	e := newExporter(c, options{Lookback: time.Minute, Timeout: time.Second})
+
+	text := scrapeText(t, e)
+	if !strings.Contains(text, "scalebox_up 0\n") || strings.Contains(text, "scalebox_sandbox_info{") || strings.Contains(text, "scalebox_sandboxes{") {
+		t.Errorf("Expected a down scrape without sandboxes:\n%s", text)
+	}
+	// Scrapes may run at the same time
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			e.collect(context.Background())
+		}()
+	}
+	wg.Wait()
+}
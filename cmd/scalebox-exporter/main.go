@@ -0,0 +1,103 @@
+// Command scalebox-exporter serves sandbox fleet metrics to Prometheus.
+//
+//	scalebox-exporter [--listen :9464] [--selector S] [--project P] [--cache-ttl 30s] [--concurrency 8]
+//
+// On every scrape of /metrics it lists the sandboxes, fetches the latest metrics of the running
+// ones (reusing metrics younger than --cache-ttl) and writes them in the OpenMetrics text format:
+// CPU, memory and disk per sandbox, uptime, remaining lifetime and sandbox counts by status.
+// The API endpoint and key are read from SCALEBOX_BASE_URL and SCALEBOX_API_KEY.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/scalebox/scalebox-sdk-golang/api/sandboxes"
+	"github.com/scalebox/scalebox-sdk-golang/internal/cliutil"
+)
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := run(ctx, os.Stderr, os.Args[1:], cliutil.ClientFromEnv, nil); err != nil {
+		if !errors.Is(err, cliutil.ErrUsage) {
+			fmt.Fprintln(os.Stderr, "scalebox-exporter:", err)
+		}
+		os.Exit(1)
+	}
+}
+
+// run serves /metrics until ctx ends. If ready is set, it receives the listening address.
+func run(ctx context.Context, stderr io.Writer, args []string, newClient func() (*sandboxes.Client, error), ready func(addr string)) error {
+	fs := flag.NewFlagSet("scalebox-exporter", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	listen := fs.String("listen", ":9464", "address to serve /metrics on")
+	var opts options
+	fs.StringVar(&opts.List.LabelSelector, "selector", "", "only export sandboxes matching this label selector")
+	fs.StringVar(&opts.List.ProjectID, "project", "", "only export sandboxes of this project")
+	fs.BoolVar(&opts.List.IncludeTerminated, "include-terminated", false, "also count terminated sandboxes")
+	fs.DurationVar(&opts.CacheTTL, "cache-ttl", 30*time.Second, "reuse sandbox metrics fetched less than this long ago")
+	fs.DurationVar(&opts.Lookback, "lookback", 2*time.Minute, "metrics history requested per sandbox; the latest point is exported")
+	fs.IntVar(&opts.Concurrency, "concurrency", 8, "metrics requests in flight at once")
+	fs.DurationVar(&opts.Timeout, "scrape-timeout", 20*time.Second, "deadline of one scrape")
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: scalebox-exporter [--listen ADDR] [--selector S] [--project P] [--cache-ttl D] [--concurrency N]")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return cliutil.ErrUsage
+	}
+	if fs.NArg() > 0 {
+		fs.Usage()
+		return cliutil.ErrUsage
+	}
+	if opts.Concurrency < 1 {
+		return fmt.Errorf("--concurrency must be at least 1, got %d", opts.Concurrency)
+	}
+	if opts.CacheTTL < 0 || opts.Lookback <= 0 {
+		return errors.New("--cache-ttl must not be negative and --lookback must be positive")
+	}
+
+	c, err := newClient()
+	if err != nil {
+		return err
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", newExporter(c, opts))
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprintln(w, "scalebox-exporter: metrics at /metrics")
+	})
+
+	ln, err := net.Listen("tcp", *listen)
+	if err != nil {
+		return err
+	}
+	server := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	stop := context.AfterFunc(ctx, func() {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		server.Shutdown(shutdownCtx)
+	})
+	defer stop()
+	if ready != nil {
+		ready(ln.Addr().String())
+	}
+	if err := server.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
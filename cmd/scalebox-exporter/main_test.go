@@ -0,0 +1,68 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/scalebox/scalebox-sdk-golang/api/sandboxes"
+	"github.com/scalebox/scalebox-sdk-golang/client"
+)
+
+func TestRun(t *testing.T) {
+	backend := httptest.NewServer(fleet())
+	defer backend.Close()
+	newClient := func() (*sandboxes.Client, error) {
+		return sandboxes.NewClient(client.NewClient(backend.URL, "test-api-key")), nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	addrs := make(chan string, 1)
+	done := make(chan error, 1)
+	var stderr bytes.Buffer
+	go func() {
+		done <- run(ctx, &stderr, []string{"--listen", "127.0.0.1:0", "--cache-ttl", "1m"}, newClient, func(addr string) { addrs <- addr })
+	}()
+
+	var addr string
+	select {
+	case addr = <-addrs:
+	case err := <-done:
+		t.Fatalf("run failed: %v\n%s", err, stderr.String())
+	}
+	resp, err := http.Get("http://" + addr + "/metrics")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || !strings.Contains(string(body), `scalebox_sandboxes{status="running"} 8`) {
+		t.Errorf("Unexpected /metrics response %d:\n%s", resp.StatusCode, body)
+	}
+
+	cancel()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("run returned %v after shutdown", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("run did not return after ctx ended")
+	}
+}
+
+func TestRunUsage(t *testing.T) {
+	newClient := func() (*sandboxes.Client, error) { return nil, nil }
+	for _, args := range [][]string{{"extra"}, {"--concurrency", "0"}, {"--lookback", "0s"}, {"--unknown"}} {
+		var stderr bytes.Buffer
+		if err := run(context.Background(), &stderr, args, newClient, nil); err == nil {
+			t.Errorf("Expected %v to be rejected", args)
+		}
+	}
+}
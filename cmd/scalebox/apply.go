@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+
+	"github.com/scalebox/scalebox-sdk-golang/declarative"
+	"github.com/scalebox/scalebox-sdk-golang/internal/cliutil"
+)
+
+func runApply(ctx context.Context, e *env, args []string) error {
+	fs := flag.NewFlagSet("apply", flag.ContinueOnError)
+	fs.SetOutput(e.stderr)
+	file := fs.String("f", "", "fleet manifest (YAML or JSON)")
+	prune := fs.Bool("prune", false, "delete sandboxes of the fleet that are not in the manifest")
+	dryRun := fs.Bool("dry-run", false, "print the plan without applying it")
+	output := fs.String("output", "text", "output format: text or json")
+	concurrency := fs.Int("concurrency", declarative.DefaultConcurrency, "actions to run at once")
+	fs.Usage = func() {
+		fmt.Fprintln(e.stderr, "Usage: scalebox apply -f fleet.yaml [--prune] [--dry-run] [--output text|json] [--concurrency N]")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return cliutil.ErrUsage
+	}
+	if *file == "" || fs.NArg() > 0 {
+		fs.Usage()
+		return cliutil.ErrUsage
+	}
+	if *output != "text" && *output != "json" {
+		return fmt.Errorf("unknown output format %q, want text or json", *output)
+	}
+
+	manifest, err := declarative.LoadManifest(*file)
+	if err != nil {
+		return err
+	}
+	c, err := e.newClient()
+	if err != nil {
+		return err
+	}
+	plan, err := declarative.NewPlan(ctx, c, manifest, declarative.PlanOptions{Prune: *prune})
+	if err != nil {
+		return err
+	}
+
+	if *dryRun || plan.Empty() {
+		if *output == "json" {
+			return plan.WriteJSON(e.stdout)
+		}
+		return plan.WriteText(e.stdout)
+	}
+
+	if *output == "text" {
+		if err := plan.WriteText(e.stdout); err != nil {
+			return err
+		}
+	}
+	results, applyErr := declarative.Apply(ctx, c, plan, declarative.ApplyOptions{
+		Concurrency: *concurrency,
+		OnResult: func(r declarative.Result) {
+			if *output != "text" {
+				return
+			}
+			if r.Err() != nil {
+				fmt.Fprintf(e.stdout, "  failed %s %s: %v\n", r.Action.Type, r.SandboxID, r.Err())
+			} else {
+				fmt.Fprintf(e.stdout, "  done   %s %s\n", r.Action.Type, r.SandboxID)
+			}
+		},
+	})
+	if *output == "json" {
+		enc := json.NewEncoder(e.stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(struct {
+			Plan    *declarative.Plan    `json:"plan"`
+			Results []declarative.Result `json:"results"`
+		}{plan, results}); err != nil {
+			return err
+		}
+	}
+	if applyErr != nil {
+		return errors.New("some actions failed:\n" + applyErr.Error())
+	}
+	return nil
+}
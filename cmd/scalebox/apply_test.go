@@ -0,0 +1,84 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/scalebox/scalebox-sdk-golang/api/sandboxes"
+	"github.com/scalebox/scalebox-sdk-golang/client"
+	"github.com/scalebox/scalebox-sdk-golang/declarative"
+	"github.com/scalebox/scalebox-sdk-golang/internal/cliutil"
+	"github.com/scalebox/scalebox-sdk-golang/models"
+)
+
+const manifest = `api_version: scalebox/v1
+fleet: dev
+sandboxes:
+  - labels: {owner: alice}
+    spec: {name: alice-dev, template: base, cpu_count: 2, memory_mb: 2048, timeout: 3600}
+`
+
+func TestApplyDryRun(t *testing.T) {
+	var mutations int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" {
+			mutations++
+		}
+		if got := r.URL.Query().Get("label_selector"); got != "scalebox.fleet=dev" {
+			t.Errorf("Expected the fleet selector, got %q", got)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(models.SandboxListResponse{Sandboxes: []models.Sandbox{{
+			SandboxID: "sbx-old",
+			Status:    models.StatusRunning,
+			Metadata:  map[string]string{declarative.MetadataFleet: "dev", "owner": "bob"},
+		}}})
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "fleet.yaml")
+	if err := os.WriteFile(path, []byte(manifest), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	var stdout, stderr bytes.Buffer
+	e := &env{stdout: &stdout, stderr: &stderr, newClient: func() (*sandboxes.Client, error) {
+		return sandboxes.NewClient(client.NewClient(server.URL, "test-api-key")), nil
+	}}
+
+	if err := run(context.Background(), e, []string{"apply", "-f", path, "--prune", "--dry-run", "--output", "json"}); err != nil {
+		t.Fatalf("apply failed: %v\n%s", err, stderr.String())
+	}
+	if mutations != 0 {
+		t.Errorf("Expected a dry run to make no changes, got %d", mutations)
+	}
+	var plan declarative.Plan
+	if err := json.Unmarshal(stdout.Bytes(), &plan); err != nil {
+		t.Fatalf("Output is not a JSON plan: %v\n%s", err, stdout.String())
+	}
+	if counts := plan.Counts(); counts[declarative.ActionCreate] != 1 || counts[declarative.ActionDelete] != 1 {
+		t.Errorf("Unexpected plan %+v", plan)
+	}
+
+	stdout.Reset()
+	if err := run(context.Background(), e, []string{"apply", "-f", path, "--dry-run"}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(stdout.String(), "+ create") || !strings.Contains(stdout.String(), "--prune: sbx-old") {
+		t.Errorf("Unexpected text plan:\n%s", stdout.String())
+	}
+
+	if err := run(context.Background(), e, []string{"apply"}); !errors.Is(err, cliutil.ErrUsage) {
+		t.Errorf("Expected usage error without -f, got %v", err)
+	}
+	if err := run(context.Background(), e, []string{"destroy-everything"}); !errors.Is(err, cliutil.ErrUsage) {
+		t.Errorf("Expected usage error for unknown command, got %v", err)
+	}
+}
@@ -0,0 +1,81 @@
+// Command scalebox manages sandboxes from the command line.
+//
+//	scalebox apply -f fleet.yaml [--prune] [--dry-run] [--output text|json] [--concurrency N]
+//	scalebox reap --selector owner=ci --older-than 2h [--dry-run] [--max-deletions N] [--output text|json]
+//	scalebox recommend (--sandbox ID | --template T | --selector S) [--policy P] [--window D] [--output text|json|request]
+//	scalebox usage [--from DATE] [--to DATE] [--group-by G] [--prices prices.json] [--output table|csv|json]
+//
+// The API endpoint and key are read from SCALEBOX_BASE_URL and SCALEBOX_API_KEY.
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/scalebox/scalebox-sdk-golang/api/sandboxes"
+	"github.com/scalebox/scalebox-sdk-golang/internal/cliutil"
+)
+
+// command is a scalebox subcommand
+type command struct {
+	name    string
+	summary string
+	run     func(ctx context.Context, env *env, args []string) error
+}
+
+var commands = []command{
+	{"apply", "reconcile a fleet of sandboxes with a manifest", runApply},
+	{"reap", "delete orphaned sandboxes matching a selector", runReap},
+	{"recommend", "suggest sandbox sizes from metrics history", runRecommend},
+	{"usage", "report sandbox usage and cost by project, owner, template or label", runUsage},
+}
+
+// env carries what subcommands need from the process
+type env struct {
+	stdout, stderr io.Writer
+	newClient      func() (*sandboxes.Client, error)
+}
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	e := &env{stdout: os.Stdout, stderr: os.Stderr, newClient: cliutil.ClientFromEnv}
+	if err := run(ctx, e, os.Args[1:]); err != nil {
+		if !errors.Is(err, cliutil.ErrUsage) {
+			fmt.Fprintln(os.Stderr, "scalebox:", err)
+		}
+		os.Exit(1)
+	}
+}
+
+func run(ctx context.Context, e *env, args []string) error {
+	if len(args) == 0 || args[0] == "-h" || args[0] == "--help" || args[0] == "help" {
+		usage(e.stderr)
+		return cliutil.ErrUsage
+	}
+	for _, cmd := range commands {
+		if cmd.name == args[0] {
+			return cmd.run(ctx, e, args[1:])
+		}
+	}
+	fmt.Fprintf(e.stderr, "scalebox: unknown command %q\n", args[0])
+	usage(e.stderr)
+	return cliutil.ErrUsage
+}
+
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: scalebox <command> [flags]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %-10s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Environment: SCALEBOX_API_KEY (required), SCALEBOX_BASE_URL (default "+cliutil.DefaultBaseURL+")")
+}
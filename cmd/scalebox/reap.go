@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/scalebox/scalebox-sdk-golang/internal/cliutil"
+	"github.com/scalebox/scalebox-sdk-golang/reaper"
+)
+
+func runReap(ctx context.Context, e *env, args []string) error {
+	fs := flag.NewFlagSet("reap", flag.ContinueOnError)
+	fs.SetOutput(e.stderr)
+	var opts reaper.Options
+	fs.StringVar(&opts.Selector, "selector", "", "label selector, e.g. owner=ci")
+	fs.StringVar(&opts.NamePrefix, "name-prefix", "", "sandbox name prefix")
+	statuses := fs.String("status", "", "comma-separated statuses to match, e.g. running,paused; use paused for idle sandboxes")
+	fs.DurationVar(&opts.OlderThan, "older-than", 0, "match sandboxes created at least this long ago")
+	fs.DurationVar(&opts.PausedLongerThan, "paused-longer-than", 0, "match sandboxes paused for at least this long")
+	persistenceDays := fs.Int("max-persistence-days", -1, "match paused sandboxes with at most this many persistence days left")
+	fs.StringVar(&opts.ProtectionLabel, "protect-label", reaper.DefaultProtectionLabel, "label that protects a sandbox from reaping")
+	fs.IntVar(&opts.MaxDeletions, "max-deletions", reaper.DefaultMaxDeletions, "maximum sandboxes to delete in this run, at least 1; use --dry-run to delete none")
+	fs.BoolVar(&opts.DryRun, "dry-run", false, "report what would be deleted without deleting")
+	output := fs.String("output", "text", "output format: text or json")
+	fs.Usage = func() {
+		fmt.Fprintln(e.stderr, "Usage: scalebox reap (--selector S | --name-prefix P) [criteria] [--dry-run] [--max-deletions N] [--output text|json]")
+		fmt.Fprintln(e.stderr, "All criteria must match. There is no separate idle criterion: idle sandboxes are matched by")
+		fmt.Fprintln(e.stderr, "status, e.g. --status paused --paused-longer-than 24h.")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return cliutil.ErrUsage
+	}
+	if fs.NArg() > 0 {
+		fs.Usage()
+		return cliutil.ErrUsage
+	}
+	if *output != "text" && *output != "json" {
+		return fmt.Errorf("unknown output format %q, want text or json", *output)
+	}
+	// 0 would fall back to reaper.DefaultMaxDeletions, which is not what the caller asked for
+	if opts.MaxDeletions < 1 {
+		return fmt.Errorf("--max-deletions must be at least 1, got %d; use --dry-run to delete nothing", opts.MaxDeletions)
+	}
+	if *statuses != "" {
+		opts.Statuses = strings.Split(*statuses, ",")
+	}
+	if *persistenceDays >= 0 {
+		opts.MaxPersistenceDaysRemaining = persistenceDays
+	}
+	if err := opts.Validate(); err != nil {
+		return err
+	}
+
+	c, err := e.newClient()
+	if err != nil {
+		return err
+	}
+	report, runErr := reaper.Run(ctx, c, opts)
+	if report == nil {
+		return runErr
+	}
+	if *output == "json" {
+		err = report.WriteJSON(e.stdout)
+	} else {
+		err = report.WriteText(e.stdout)
+	}
+	if runErr != nil {
+		return errors.New("some deletions failed:\n" + runErr.Error())
+	}
+	return err
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/scalebox/scalebox-sdk-golang/api/sandboxes"
+	"github.com/scalebox/scalebox-sdk-golang/client"
+	"github.com/scalebox/scalebox-sdk-golang/models"
+	"github.com/scalebox/scalebox-sdk-golang/reaper"
+)
+
+func TestReapDryRun(t *testing.T) {
+	var deletes int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "DELETE" {
+			deletes++
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(models.SandboxListResponse{Sandboxes: []models.Sandbox{
+			{SandboxID: "sbx-old", Name: "ci-1", Status: models.StatusRunning, CreatedAt: time.Now().Add(-3 * time.Hour), Metadata: map[string]string{"owner": "ci"}},
+			{SandboxID: "sbx-new", Name: "ci-2", Status: models.StatusRunning, CreatedAt: time.Now(), Metadata: map[string]string{"owner": "ci"}},
+		}})
+	}))
+	defer server.Close()
+
+	var stdout, stderr bytes.Buffer
+	e := &env{stdout: &stdout, stderr: &stderr, newClient: func() (*sandboxes.Client, error) {
+		return sandboxes.NewClient(client.NewClient(server.URL, "test-api-key")), nil
+	}}
+	err := run(context.Background(), e, []string{"reap", "--selector", "owner=ci", "--older-than", "2h", "--dry-run", "--output", "json"})
+	if err != nil {
+		t.Fatalf("reap failed: %v\n%s", err, stderr.String())
+	}
+	if deletes != 0 {
+		t.Errorf("Dry run deleted %d sandboxes", deletes)
+	}
+	var report reaper.Report
+	if err := json.Unmarshal(stdout.Bytes(), &report); err != nil {
+		t.Fatalf("Output is not a JSON report: %v\n%s", err, stdout.String())
+	}
+	if len(report.Entries) != 1 || report.Entries[0].SandboxID != "sbx-old" || report.Entries[0].Outcome != reaper.OutcomeWouldDelete {
+		t.Errorf("Unexpected report %+v", report)
+	}
+
+	if err := run(context.Background(), e, []string{"reap", "--older-than", "2h"}); err == nil {
+		t.Error("Expected an unscoped reap to be rejected")
+	}
+	if err := run(context.Background(), e, []string{"reap", "--selector", "owner=ci", "--max-deletions", "0"}); err == nil || !strings.Contains(err.Error(), "max-deletions") {
+		t.Errorf("Expected --max-deletions 0 to be rejected, got %v", err)
+	}
+}
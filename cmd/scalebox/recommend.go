@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+
+	"github.com/scalebox/scalebox-sdk-golang/internal/cliutil"
+	"github.com/scalebox/scalebox-sdk-golang/models"
+	"github.com/scalebox/scalebox-sdk-golang/recommend"
+)
+
+func runRecommend(ctx context.Context, e *env, args []string) error {
+	fs := flag.NewFlagSet("recommend", flag.ContinueOnError)
+	fs.SetOutput(e.stderr)
+	var list models.ListSandboxesOptions
+	sandboxID := fs.String("sandbox", "", "sandbox ID to size")
+	fs.StringVar(&list.TemplateName, "template", "", "size all sandboxes of this template")
+	fs.StringVar(&list.LabelSelector, "selector", "", "size all sandboxes matching this label selector, e.g. team=ml")
+	fs.StringVar(&list.ProjectID, "project", "", "only sandboxes of this project")
+	policy := fs.String("policy", recommend.Balanced.Name, "headroom policy: conservative, balanced or aggressive")
+	var opts recommend.Options
+	fs.DurationVar(&opts.Window, "window", recommend.DefaultWindow, "metrics history to analyse")
+	fs.Float64Var(&opts.MinConfidence, "min-confidence", recommend.DefaultMinConfidence, "confidence (0-1) below which resources are never reduced")
+	output := fs.String("output", "text", "output format: text, json, or request for the patched create request")
+	fs.Usage = func() {
+		fmt.Fprintln(e.stderr, "Usage: scalebox recommend (--sandbox ID | --template T | --selector S) [--policy P] [--window D] [--output text|json|request]")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return cliutil.ErrUsage
+	}
+	if fs.NArg() > 0 {
+		fs.Usage()
+		return cliutil.ErrUsage
+	}
+	group := list.TemplateName != "" || list.LabelSelector != "" || list.ProjectID != ""
+	if (*sandboxID == "") == !group {
+		return errors.New("exactly one of --sandbox or --template, --selector and --project is required")
+	}
+	if *output != "text" && *output != "json" && *output != "request" {
+		return fmt.Errorf("unknown output format %q, want text, json or request", *output)
+	}
+	p, err := recommend.ParsePolicy(*policy)
+	if err != nil {
+		return err
+	}
+	opts.Policy = &p
+	if err := opts.Validate(); err != nil {
+		return err
+	}
+
+	c, err := e.newClient()
+	if err != nil {
+		return err
+	}
+	var rec *recommend.Recommendation
+	if *sandboxID != "" {
+		rec, err = recommend.ForSandbox(ctx, c, *sandboxID, opts)
+	} else {
+		rec, err = recommend.ForSandboxes(ctx, c, list, opts)
+	}
+	if err != nil {
+		return err
+	}
+	switch *output {
+	case "json":
+		return rec.WriteJSON(e.stdout)
+	case "request":
+		enc := json.NewEncoder(e.stdout)
+		enc.SetIndent("", "  ")
+		// Printed requests carry no secrets; fill in env var values before creating from it
+		return enc.Encode(rec.Request.Redacted())
+	}
+	return rec.WriteText(e.stdout)
+}
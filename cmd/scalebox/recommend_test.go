@@ -0,0 +1,71 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/scalebox/scalebox-sdk-golang/api/sandboxes"
+	"github.com/scalebox/scalebox-sdk-golang/client"
+	"github.com/scalebox/scalebox-sdk-golang/models"
+)
+
+func TestRecommend(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Second)
+	sb := models.Sandbox{SandboxID: "sbx-1", Name: "worker", TemplateID: "tpl-base", CPUCount: 4, MemoryMB: 8192, StorageGB: 20, CreatedAt: now.Add(-30 * 24 * time.Hour), EnvVars: map[string]string{"API_TOKEN": "tok-123"}}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/metrics"):
+			// A point every 15 minutes over the last week, at half a core and 1 GB of memory
+			var points []models.MetricsDataPoint
+			for ts := now.Add(-7 * 24 * time.Hour); !ts.After(now); ts = ts.Add(15 * time.Minute) {
+				points = append(points, models.MetricsDataPoint{Timestamp: ts, CPUCount: 4, CPUUsedPct: 12.5, MemTotal: 8 << 30, MemUsed: 1 << 30, DiskUsed: 1 << 30})
+			}
+			json.NewEncoder(w).Encode(models.SandboxMetricsResponse{SandboxID: "sbx-1", Metrics: points})
+		case strings.HasSuffix(r.URL.Path, "/sbx-1"):
+			json.NewEncoder(w).Encode(sb)
+		default:
+			json.NewEncoder(w).Encode(models.SandboxListResponse{Sandboxes: []models.Sandbox{sb}})
+		}
+	}))
+	defer server.Close()
+
+	var stdout, stderr bytes.Buffer
+	e := &env{stdout: &stdout, stderr: &stderr, newClient: func() (*sandboxes.Client, error) {
+		return sandboxes.NewClient(client.NewClient(server.URL, "test-api-key")), nil
+	}}
+	if err := run(context.Background(), e, []string{"recommend", "--sandbox", "sbx-1"}); err != nil {
+		t.Fatalf("recommend failed: %v\n%s", err, stderr.String())
+	}
+	if !strings.Contains(stdout.String(), "balanced policy") || !strings.Contains(stdout.String(), "3 vCPU (75%)") {
+		t.Errorf("Unexpected text report:\n%s", stdout.String())
+	}
+
+	stdout.Reset()
+	if err := run(context.Background(), e, []string{"recommend", "--template", "base", "--policy", "conservative", "--output", "request"}); err != nil {
+		t.Fatalf("recommend failed: %v\n%s", err, stderr.String())
+	}
+	var req models.CreateSandboxRequest
+	if err := json.Unmarshal(stdout.Bytes(), &req); err != nil {
+		t.Fatalf("Output is not a create request: %v\n%s", err, stdout.String())
+	}
+	if req.Template != "tpl-base" || req.CPUCount != 1 || req.MemoryMB != 1536 || req.StorageGB != 2 || req.EnvVars["API_TOKEN"] != models.RedactedValue {
+		t.Errorf("Unexpected patched request %+v", req)
+	}
+
+	for _, args := range [][]string{
+		{"recommend"},
+		{"recommend", "--sandbox", "sbx-1", "--selector", "team=ml"},
+		{"recommend", "--sandbox", "sbx-1", "--policy", "yolo"},
+	} {
+		if err := run(context.Background(), e, args); err == nil {
+			t.Errorf("Expected %v to be rejected", args)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/scalebox/scalebox-sdk-golang/internal/cliutil"
+	usagereport "github.com/scalebox/scalebox-sdk-golang/usage"
+)
+
+func runUsage(ctx context.Context, e *env, args []string) error {
+	fs := flag.NewFlagSet("usage", flag.ContinueOnError)
+	fs.SetOutput(e.stderr)
+	var opts usagereport.Options
+	from := fs.String("from", "", "start of the window, YYYY-MM-DD or RFC 3339 (default 30 days ago)")
+	to := fs.String("to", "", "end of the window, YYYY-MM-DD or RFC 3339 (default now)")
+	fs.StringVar(&opts.GroupBy, "group-by", usagereport.GroupByProject, "group by project, owner, template or label:<key>")
+	fs.StringVar(&opts.List.LabelSelector, "selector", "", "only sandboxes matching this label selector, e.g. team=ml")
+	fs.StringVar(&opts.List.ProjectID, "project", "", "only sandboxes of this project")
+	pricesFile := fs.String("prices", "", "JSON price sheet with currency, vcpu_hour, memory_gb_hour and storage_gb_day")
+	var prices usagereport.PriceSheet
+	fs.Float64Var(&prices.VCPUHour, "vcpu-hour", 0, "price per vCPU-hour, overrides the price sheet")
+	fs.Float64Var(&prices.MemoryGBHour, "memory-gb-hour", 0, "price per memory GB-hour, overrides the price sheet")
+	fs.Float64Var(&prices.StorageGBDay, "storage-gb-day", 0, "price per storage GB-day, overrides the price sheet")
+	fs.StringVar(&prices.Currency, "currency", "", "currency shown next to costs, overrides the price sheet")
+	output := fs.String("output", "table", "output format: table, csv or json")
+	fs.Usage = func() {
+		fmt.Fprintln(e.stderr, "Usage: scalebox usage [--from DATE] [--to DATE] [--group-by G] [--prices prices.json] [--output table|csv|json]")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return cliutil.ErrUsage
+	}
+	if fs.NArg() > 0 {
+		fs.Usage()
+		return cliutil.ErrUsage
+	}
+	if *output != "table" && *output != "csv" && *output != "json" {
+		return fmt.Errorf("unknown output format %q, want table, csv or json", *output)
+	}
+
+	var err error
+	if *to != "" {
+		if opts.To, err = parseDate(*to); err != nil {
+			return fmt.Errorf("--to: %w", err)
+		}
+	}
+	if *from != "" {
+		if opts.From, err = parseDate(*from); err != nil {
+			return fmt.Errorf("--from: %w", err)
+		}
+	} else {
+		end := opts.To
+		if end.IsZero() {
+			end = time.Now()
+		}
+		opts.From = end.AddDate(0, 0, -30)
+	}
+	if *pricesFile != "" {
+		if opts.Prices, err = usagereport.LoadPriceSheet(*pricesFile); err != nil {
+			return err
+		}
+	}
+	fs.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "vcpu-hour":
+			opts.Prices.VCPUHour = prices.VCPUHour
+		case "memory-gb-hour":
+			opts.Prices.MemoryGBHour = prices.MemoryGBHour
+		case "storage-gb-day":
+			opts.Prices.StorageGBDay = prices.StorageGBDay
+		case "currency":
+			opts.Prices.Currency = prices.Currency
+		}
+	})
+	if err := opts.Validate(); err != nil {
+		return err
+	}
+
+	c, err := e.newClient()
+	if err != nil {
+		return err
+	}
+	report, err := usagereport.Collect(ctx, c, opts)
+	if err != nil {
+		return err
+	}
+	switch *output {
+	case "csv":
+		return report.WriteCSV(e.stdout)
+	case "json":
+		return report.WriteJSON(e.stdout)
+	}
+	return report.WriteTable(e.stdout)
+}
+
+// parseDate parses a UTC date such as 2024-05-01 or an RFC 3339 time
+func parseDate(s string) (time.Time, error) {
+	if t, err := time.Parse(time.DateOnly, s); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("want YYYY-MM-DD or RFC 3339, got %q", s)
+	}
+	return t, nil
+}
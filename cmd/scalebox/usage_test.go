@@ -0,0 +1,86 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/scalebox/scalebox-sdk-golang/api/sandboxes"
+	"github.com/scalebox/scalebox-sdk-golang/client"
+	"github.com/scalebox/scalebox-sdk-golang/models"
+	usagereport "github.com/scalebox/scalebox-sdk-golang/usage"
+)
+
+func TestUsage(t *testing.T) {
+	created := time.Date(2024, 5, 10, 0, 0, 0, 0, time.UTC)
+	ended := created.Add(10 * time.Hour)
+	var query string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.RawQuery
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(models.SandboxListResponse{Sandboxes: []models.Sandbox{
+			{SandboxID: "sbx-1", ProjectID: "prj-1", TemplateID: "tpl-base", Status: models.StatusTerminated, CPUCount: 2, MemoryMB: 2048, StorageGB: 24,
+				CreatedAt: created, EndedAt: &ended, TotalRunningSeconds: 10 * 3600, Metadata: map[string]string{"team": "ml"}},
+		}})
+	}))
+	defer server.Close()
+
+	var stdout, stderr bytes.Buffer
+	e := &env{stdout: &stdout, stderr: &stderr, newClient: func() (*sandboxes.Client, error) {
+		return sandboxes.NewClient(client.NewClient(server.URL, "test-api-key")), nil
+	}}
+	prices := filepath.Join(t.TempDir(), "prices.json")
+	if err := os.WriteFile(prices, []byte(`{"currency": "USD", "vcpu_hour": 0.5, "memory_gb_hour": 0.1}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	args := []string{"usage", "--from", "2024-05-01", "--to", "2024-06-01", "--prices", prices, "--storage-gb-day", "1", "--project", "prj-1"}
+	if err := run(context.Background(), e, args); err != nil {
+		t.Fatalf("usage failed: %v\n%s", err, stderr.String())
+	}
+	if !strings.Contains(stdout.String(), "cost (USD)") || !strings.Contains(stdout.String(), "prj-1") {
+		t.Errorf("Unexpected table:\n%s", stdout.String())
+	}
+	if !strings.Contains(query, "project_id=prj-1") {
+		t.Errorf("Unexpected list query %s", query)
+	}
+
+	stdout.Reset()
+	if err := run(context.Background(), e, append(args, "--group-by", "label:team", "--output", "csv")); err != nil {
+		t.Fatalf("usage failed: %v\n%s", err, stderr.String())
+	}
+	records, err := csv.NewReader(&stdout).ReadAll()
+	// 20 vCPU-hours, 20 GB-hours and 10 GB-days
+	if err != nil || len(records) != 3 || records[1][0] != "ml" || records[1][7] != "22.00" {
+		t.Errorf("Unexpected CSV %v, %v", records, err)
+	}
+
+	stdout.Reset()
+	if err := run(context.Background(), e, []string{"usage", "--from", "2024-05-01T00:00:00Z", "--to", "2024-05-10T05:00:00Z", "--output", "json"}); err != nil {
+		t.Fatalf("usage failed: %v\n%s", err, stderr.String())
+	}
+	var report usagereport.Report
+	if err := json.Unmarshal(stdout.Bytes(), &report); err != nil || report.Total.RunningHours != 5 {
+		t.Errorf("Unexpected JSON report %+v, %v", report.Total, err)
+	}
+
+	for _, bad := range [][]string{
+		{"usage", "--from", "May 1st"},
+		{"usage", "--from", "2024-06-01", "--to", "2024-05-01"},
+		{"usage", "--group-by", "colour"},
+		{"usage", "--vcpu-hour", "-1"},
+		{"usage", "--output", "xml"},
+	} {
+		if err := run(context.Background(), e, bad); err == nil {
+			t.Errorf("Expected %v to fail", bad)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package declarative
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/scalebox/scalebox-sdk-golang/api/sandboxes"
+	"github.com/scalebox/scalebox-sdk-golang/models"
+)
+
+// DefaultConcurrency is the number of actions Apply runs at once when none is given
+const DefaultConcurrency = 4
+
+// ApplyOptions configures Apply
+type ApplyOptions struct {
+	Concurrency int          // Actions run at once, defaults to DefaultConcurrency
+	OnResult    func(Result) // Called as each action finishes; calls are serialized
+}
+
+// Result is the outcome of one action
+type Result struct {
+	Action    Action `json:"action"`
+	SandboxID string `json:"sandbox_id,omitempty"` // The sandbox acted on, including newly created ones
+	Error     string `json:"error,omitempty"`
+
+	err error
+}
+
+// Err returns the error of a failed action
+func (r Result) Err() error {
+	return r.err
+}
+
+// Apply carries out the plan, running up to Concurrency actions at once.
+// Every action is attempted; the results are returned in plan order together with
+// the joined errors of the actions that failed.
+func Apply(ctx context.Context, c *sandboxes.Client, p *Plan, opts ApplyOptions) ([]Result, error) {
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = DefaultConcurrency
+	}
+
+	results := make([]Result, len(p.Actions))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	for i, action := range p.Actions {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, action Action) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			id, err := apply(ctx, c, action)
+			results[i] = Result{Action: action, SandboxID: id, err: err}
+			if err != nil {
+				results[i].Error = err.Error()
+			}
+			if opts.OnResult != nil {
+				mu.Lock()
+				opts.OnResult(results[i])
+				mu.Unlock()
+			}
+		}(i, action)
+	}
+	wg.Wait()
+
+	var errs []error
+	for _, r := range results {
+		if r.err != nil {
+			errs = append(errs, fmt.Errorf("%s %s: %w", r.Action.Type, r.Action.target(), r.err))
+		}
+	}
+	return results, errors.Join(errs...)
+}
+
+// apply runs a single action and returns the ID of the sandbox it acted on
+func apply(ctx context.Context, c *sandboxes.Client, a Action) (string, error) {
+	switch a.Type {
+	case ActionCreate:
+		if a.Request == nil {
+			return "", errors.New("create action has no request")
+		}
+		sb, err := c.Create(ctx, *a.Request)
+		if err != nil {
+			return "", err
+		}
+		if a.Pause {
+			if _, err := c.WaitForStatus(ctx, sb.SandboxID, models.StatusRunning, 0); err != nil {
+				return sb.SandboxID, err
+			}
+			if _, err := c.Pause(ctx, sb.SandboxID); err != nil {
+				return sb.SandboxID, err
+			}
+		}
+		return sb.SandboxID, nil
+	case ActionUpdateTimeout:
+		_, err := c.SetTimeout(ctx, a.SandboxID, models.SandboxTimeoutRequest{Timeout: a.Timeout})
+		return a.SandboxID, err
+	case ActionPause:
+		_, err := c.Pause(ctx, a.SandboxID)
+		return a.SandboxID, err
+	case ActionResume:
+		_, err := c.Resume(ctx, a.SandboxID)
+		return a.SandboxID, err
+	case ActionDelete:
+		_, err := c.Delete(ctx, a.SandboxID, nil)
+		return a.SandboxID, err
+	}
+	return a.SandboxID, fmt.Errorf("unknown action %q", a.Type)
+}
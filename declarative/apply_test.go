@@ -0,0 +1,103 @@
+package declarative
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/scalebox/scalebox-sdk-golang/api/sandboxes"
+	"github.com/scalebox/scalebox-sdk-golang/client"
+	"github.com/scalebox/scalebox-sdk-golang/models"
+)
+
+// fleetServer is a fake API recording the calls Apply makes
+type fleetServer struct {
+	mu       sync.Mutex
+	calls    []string
+	inFlight int
+	maxTotal int
+}
+
+func (s *fleetServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	s.calls = append(s.calls, r.Method+" "+r.URL.Path)
+	s.inFlight++
+	if s.inFlight > s.maxTotal {
+		s.maxTotal = s.inFlight
+	}
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		s.inFlight--
+		s.mu.Unlock()
+	}()
+	time.Sleep(10 * time.Millisecond)
+
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/v1/sandboxes"), "/")
+	w.Header().Set("Content-Type", "application/json")
+	switch {
+	case r.Method == "POST" && len(parts) == 1:
+		json.NewEncoder(w).Encode(models.Sandbox{SandboxID: "sbx-new", Status: models.StatusStarting})
+	case r.Method == "GET" && len(parts) == 3 && parts[2] == "status":
+		json.NewEncoder(w).Encode(models.SandboxStatus{SandboxID: parts[1], Status: models.StatusRunning})
+	case parts[1] == "sbx-broken":
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"error": "boom"})
+	default:
+		json.NewEncoder(w).Encode(models.Sandbox{SandboxID: parts[1]})
+	}
+}
+
+func TestApply(t *testing.T) {
+	fake := &fleetServer{}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+	c := sandboxes.NewClient(client.NewClient(server.URL, "test-api-key"))
+
+	req := models.CreateSandboxRequest{Name: "bob-dev", CPUCount: 1, MemoryMB: 512}
+	plan := &Plan{Fleet: "dev", Actions: []Action{
+		{Type: ActionCreate, Member: "owner=bob", Request: &req, Pause: true},
+		{Type: ActionUpdateTimeout, SandboxID: "sbx-1", Timeout: 3600},
+		{Type: ActionPause, SandboxID: "sbx-2"},
+		{Type: ActionResume, SandboxID: "sbx-3"},
+		{Type: ActionDelete, SandboxID: "sbx-4"},
+		{Type: ActionDelete, SandboxID: "sbx-broken"},
+	}}
+
+	var reported int
+	results, err := Apply(context.Background(), c, plan, ApplyOptions{
+		Concurrency: 2,
+		OnResult:    func(Result) { reported++ },
+	})
+	if err == nil || !strings.Contains(err.Error(), "delete sbx-broken") {
+		t.Errorf("Expected the failed delete to be reported, got %v", err)
+	}
+	if len(results) != 6 || reported != 6 {
+		t.Fatalf("Expected 6 results and callbacks, got %d and %d", len(results), reported)
+	}
+	if results[0].SandboxID != "sbx-new" || results[0].Err() != nil {
+		t.Errorf("Unexpected create result %+v", results[0])
+	}
+	if results[5].Error == "" || results[4].Error != "" {
+		t.Errorf("Expected only the broken delete to fail, got %+v", results)
+	}
+
+	calls := strings.Join(fake.calls, "\n")
+	for _, want := range []string{
+		"POST /v1/sandboxes\n", "GET /v1/sandboxes/sbx-new/status", "POST /v1/sandboxes/sbx-new/pause",
+		"POST /v1/sandboxes/sbx-1/timeout", "POST /v1/sandboxes/sbx-2/pause", "POST /v1/sandboxes/sbx-3/resume",
+		"DELETE /v1/sandboxes/sbx-4",
+	} {
+		if !strings.Contains(calls+"\n", want) {
+			t.Errorf("Expected call %q, got:\n%s", want, calls)
+		}
+	}
+	if fake.maxTotal > 2 {
+		t.Errorf("Expected at most 2 concurrent requests, got %d", fake.maxTotal)
+	}
+}
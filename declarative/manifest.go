@@ -0,0 +1,167 @@
+// Package declarative reconciles a fleet of long-lived sandboxes with a manifest.
+//
+// A manifest lists the desired sandboxes of one fleet. Each entry is identified by its
+// identity labels, which are stored in the sandbox metadata together with the fleet name:
+//
+//	api_version: scalebox/v1
+//	fleet: dev-sandboxes
+//	sandboxes:
+//	  - labels: {owner: alice}
+//	    spec: {name: alice-dev, template: base, cpu_count: 2, memory_mb: 2048, timeout: 28800}
+//	  - labels: {owner: bob}
+//	    state: paused
+//	    spec: {name: bob-dev, template: base, cpu_count: 4, memory_mb: 8192, timeout: 28800}
+//
+// NewPlan compares the manifest with the sandboxes of the fleet returned by List and
+// Apply carries the plan out.
+package declarative
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/scalebox/scalebox-sdk-golang/labels"
+	"github.com/scalebox/scalebox-sdk-golang/models"
+	"github.com/scalebox/scalebox-sdk-golang/models/specfile"
+)
+
+// MetadataFleet is the metadata label holding the fleet a sandbox belongs to
+const MetadataFleet = "scalebox.fleet"
+
+// Desired states of a fleet member
+const (
+	StateRunning = "running"
+	StatePaused  = "paused"
+)
+
+// Manifest is the desired state of a fleet, usually loaded from YAML or JSON
+type Manifest struct {
+	APIVersion string    `json:"api_version"` // Must be models.SpecVersion
+	Fleet      string    `json:"fleet"`       // Fleet name, scopes planning and pruning
+	Sandboxes  []Desired `json:"sandboxes"`
+}
+
+// Desired is one sandbox of the fleet
+type Desired struct {
+	Labels map[string]string           `json:"labels"`          // Identity labels, unique within the fleet
+	State  string                      `json:"state,omitempty"` // StateRunning (default) or StatePaused
+	Spec   models.CreateSandboxRequest `json:"spec"`
+}
+
+// Selector returns the selector matching the fleet member
+func (d Desired) Selector() labels.Selector {
+	return labels.SelectorFromSet(d.Labels)
+}
+
+// state returns the desired state, defaulting to StateRunning
+func (d Desired) state() string {
+	if d.State == "" {
+		return StateRunning
+	}
+	return d.State
+}
+
+// createRequest returns the spec with the fleet and identity labels added to its metadata
+func (d Desired) createRequest(fleet string) models.CreateSandboxRequest {
+	req := d.Spec.Clone()
+	if req.Metadata == nil {
+		req.Metadata = make(map[string]string)
+	}
+	for k, v := range d.Labels {
+		req.Metadata[k] = v
+	}
+	req.Metadata[MetadataFleet] = fleet
+	return req
+}
+
+// LoadManifest reads and validates a manifest, choosing YAML or JSON by extension
+func LoadManifest(path string) (*Manifest, error) {
+	var m Manifest
+	if err := specfile.LoadFile(path, &m); err != nil {
+		return nil, err
+	}
+	if err := m.Validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &m, nil
+}
+
+// ParseManifest decodes and validates a manifest from data
+func ParseManifest(data []byte, format specfile.Format) (*Manifest, error) {
+	var m Manifest
+	if err := specfile.Unmarshal(data, format, &m); err != nil {
+		return nil, err
+	}
+	if err := m.Validate(); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// Validate checks the manifest and every sandbox spec in it.
+// It returns a *models.ValidationError listing every problem, or nil.
+func (m *Manifest) Validate() error {
+	var errs []models.FieldError
+	add := func(field, format string, args ...interface{}) {
+		errs = append(errs, models.FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if m.APIVersion != models.SpecVersion {
+		add("api_version", "must be %q, got %q", models.SpecVersion, m.APIVersion)
+	}
+	if m.Fleet == "" {
+		add("fleet", "must not be empty")
+	} else if err := labels.ValidateValue(m.Fleet); err != nil {
+		add("fleet", "%v", err)
+	}
+
+	seen := make(map[string]int, len(m.Sandboxes))
+	for i, d := range m.Sandboxes {
+		field := fmt.Sprintf("sandboxes[%d]", i)
+		if len(d.Labels) == 0 {
+			add(field+".labels", "at least one identity label is required")
+		}
+		for _, k := range sortedKeys(d.Labels) {
+			if err := labels.ValidateKey(k); err != nil {
+				add(field+".labels", "%v", err)
+			} else if err := labels.ValidateValue(d.Labels[k]); err != nil {
+				add(field+".labels."+k, "%v", err)
+			}
+			if k == MetadataFleet {
+				add(field+".labels."+k, "is reserved for the fleet name")
+			}
+		}
+		identity := d.Selector().String()
+		if j, dup := seen[identity]; dup {
+			add(field+".labels", "duplicates the identity of sandboxes[%d]", j)
+		} else {
+			seen[identity] = i
+		}
+		if s := d.state(); s != StateRunning && s != StatePaused {
+			add(field+".state", "must be %q or %q, got %q", StateRunning, StatePaused, d.State)
+		}
+		if err := d.Spec.Validate(); err != nil {
+			if verr, ok := err.(*models.ValidationError); ok {
+				for _, fe := range verr.Errors {
+					add(field+".spec."+fe.Field, "%s", fe.Message)
+				}
+			} else {
+				add(field+".spec", "%v", err)
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return &models.ValidationError{Errors: errs}
+	}
+	return nil
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,69 @@
+package declarative
+
+import (
+	"testing"
+
+	"github.com/scalebox/scalebox-sdk-golang/models"
+	"github.com/scalebox/scalebox-sdk-golang/models/specfile"
+)
+
+const fleetYAML = `
+api_version: scalebox/v1
+fleet: dev
+sandboxes:
+  - labels: {owner: alice}
+    spec: {name: alice-dev, template: base, cpu_count: 2, memory_mb: 2048, timeout: 3600}
+  - labels: {owner: bob}
+    state: paused
+    spec: {name: bob-dev, template: base, cpu_count: 2, memory_mb: 2048, timeout: 3600}
+  - labels: {owner: carol}
+    spec: {name: carol-dev, template: base, cpu_count: 2, memory_mb: 2048}
+`
+
+func TestParseManifest(t *testing.T) {
+	m, err := ParseManifest([]byte(fleetYAML), specfile.FormatYAML)
+	if err != nil {
+		t.Fatalf("ParseManifest failed: %v", err)
+	}
+	if m.Fleet != "dev" || len(m.Sandboxes) != 3 || m.Sandboxes[1].state() != StatePaused || m.Sandboxes[0].state() != StateRunning {
+		t.Errorf("Unexpected manifest %+v", m)
+	}
+
+	req := m.Sandboxes[0].createRequest(m.Fleet)
+	if req.Metadata["owner"] != "alice" || req.Metadata[MetadataFleet] != "dev" {
+		t.Errorf("Expected identity and fleet labels in metadata, got %v", req.Metadata)
+	}
+	if m.Sandboxes[0].Spec.Metadata != nil {
+		t.Error("createRequest must not modify the manifest")
+	}
+}
+
+func TestManifestValidate(t *testing.T) {
+	bad := `
+api_version: scalebox/v0
+fleet: "dev fleet"
+sandboxes:
+  - labels: {}
+    spec: {name: a, cpu_count: 1, memory_mb: 512}
+  - labels: {owner: bob}
+    state: stopped
+    spec: {name: b, cpu_count: -1, memory_mb: 512}
+  - labels: {owner: bob, scalebox.fleet: x}
+    spec: {name: c, cpu_count: 1, memory_mb: 512}
+  - labels: {owner: bob}
+    spec: {name: d, cpu_count: 1, memory_mb: 512}
+`
+	_, err := ParseManifest([]byte(bad), specfile.FormatYAML)
+	verr, ok := err.(*models.ValidationError)
+	if !ok {
+		t.Fatalf("Expected *models.ValidationError, got %v", err)
+	}
+	for _, field := range []string{
+		"api_version", "fleet", "sandboxes[0].labels", "sandboxes[1].state", "sandboxes[1].spec.cpu_count",
+		"sandboxes[2].labels.scalebox.fleet", "sandboxes[3].labels",
+	} {
+		if !verr.HasField(field) {
+			t.Errorf("Expected an error for %s, got %v", field, verr)
+		}
+	}
+}
@@ -0,0 +1,246 @@
+package declarative
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/scalebox/scalebox-sdk-golang/api/sandboxes"
+	"github.com/scalebox/scalebox-sdk-golang/models"
+)
+
+// ActionType is a step of a plan
+type ActionType string
+
+// Plan actions
+const (
+	ActionCreate        ActionType = "create"
+	ActionUpdateTimeout ActionType = "update_timeout"
+	ActionPause         ActionType = "pause"
+	ActionResume        ActionType = "resume"
+	ActionDelete        ActionType = "delete"
+)
+
+// actionOrder is the order actions are counted and printed in
+var actionOrder = []ActionType{ActionCreate, ActionUpdateTimeout, ActionPause, ActionResume, ActionDelete}
+
+// Action is one change Apply makes
+type Action struct {
+	Type      ActionType                   `json:"type"`
+	Member    string                       `json:"member,omitempty"`     // Identity labels of the fleet member, empty for pruned sandboxes
+	SandboxID string                       `json:"sandbox_id,omitempty"` // Empty for create
+	Name      string                       `json:"name,omitempty"`
+	Reason    string                       `json:"reason"`
+	Timeout   int                          `json:"timeout,omitempty"` // New timeout in seconds, for update_timeout
+	Request   *models.CreateSandboxRequest `json:"request,omitempty"` // Sandbox to create, for create; redacted when encoded as JSON
+	Pause     bool                         `json:"pause,omitempty"`   // Pause the sandbox once created, for create
+}
+
+// MarshalJSON encodes the action with env var values and credentials in Request redacted,
+// so plans and apply results can be printed and stored safely
+func (a Action) MarshalJSON() ([]byte, error) {
+	type action Action
+	out := action(a)
+	if a.Request != nil {
+		redacted := a.Request.Redacted()
+		out.Request = &redacted
+	}
+	return json.Marshal(out)
+}
+
+// Plan is the list of actions that brings a fleet to its manifest
+type Plan struct {
+	Fleet     string   `json:"fleet"`
+	Actions   []Action `json:"actions"`
+	Unchanged int      `json:"unchanged"`          // Fleet members already as desired
+	Orphans   []string `json:"orphans,omitempty"`  // IDs of fleet sandboxes not in the manifest, deleted only when pruning
+	Warnings  []string `json:"warnings,omitempty"` // Differences that cannot be reconciled in place
+}
+
+// PlanOptions configures NewPlan and Diff
+type PlanOptions struct {
+	// Prune deletes sandboxes of the fleet that are not in the manifest
+	Prune bool
+}
+
+// NewPlan lists the sandboxes of the manifest's fleet and compares them with the manifest
+func NewPlan(ctx context.Context, c *sandboxes.Client, m *Manifest, opts PlanOptions) (*Plan, error) {
+	observed, err := c.ListAll(ctx, models.ListSandboxesOptions{LabelSelector: MetadataFleet + "=" + m.Fleet})
+	if err != nil {
+		return nil, err
+	}
+	return Diff(m, observed, opts)
+}
+
+// Diff compares the manifest with observed sandboxes and returns the plan.
+// A timeout update is planned only when the manifest timeout exceeds the current one.
+// Sandboxes of other fleets and terminated sandboxes are ignored. When several live sandboxes
+// match one fleet member, the oldest is kept and the others are treated as orphans.
+func Diff(m *Manifest, observed []models.Sandbox, opts PlanOptions) (*Plan, error) {
+	plan := &Plan{Fleet: m.Fleet, Actions: []Action{}}
+	members := make([][]models.Sandbox, len(m.Sandboxes))
+	var orphans []models.Sandbox
+
+	for _, sb := range observed {
+		if sb.Metadata[MetadataFleet] != m.Fleet || models.IsTerminalStatus(sb.Status) || sb.Status == models.StatusTerminating {
+			continue
+		}
+		match := -1
+		for i, d := range m.Sandboxes {
+			if !d.Selector().Matches(sb.Metadata) {
+				continue
+			}
+			if match >= 0 {
+				return nil, fmt.Errorf("sandbox %s matches both sandboxes[%d] (%s) and sandboxes[%d] (%s); make their identity labels distinct",
+					sb.SandboxID, match, m.Sandboxes[match].Selector(), i, d.Selector())
+			}
+			match = i
+		}
+		if match < 0 {
+			orphans = append(orphans, sb)
+			continue
+		}
+		members[match] = append(members[match], sb)
+	}
+
+	for i, d := range m.Sandboxes {
+		member := d.Selector().String()
+		found := members[i]
+		if len(found) == 0 {
+			req := d.createRequest(m.Fleet)
+			action := Action{Type: ActionCreate, Member: member, Name: req.Name, Reason: "no sandbox in the fleet", Request: &req}
+			if d.state() == StatePaused {
+				action.Pause = true
+				action.Reason += ", pause once running"
+			}
+			plan.Actions = append(plan.Actions, action)
+			continue
+		}
+
+		sort.SliceStable(found, func(a, b int) bool { return found[a].CreatedAt.Before(found[b].CreatedAt) })
+		sb := found[0]
+		for _, dup := range found[1:] {
+			plan.Warnings = append(plan.Warnings, fmt.Sprintf("%s: sandbox %s duplicates %s and is treated as an orphan", member, dup.SandboxID, sb.SandboxID))
+			orphans = append(orphans, dup)
+		}
+
+		before := len(plan.Actions)
+		// Timeouts only grow: KeepAlive and the server extend them, and SetTimeout cannot shorten a sandbox's lifetime
+		if d.Spec.Timeout > sb.Timeout {
+			plan.Actions = append(plan.Actions, Action{
+				Type:      ActionUpdateTimeout,
+				Member:    member,
+				SandboxID: sb.SandboxID,
+				Name:      sb.Name,
+				Reason:    fmt.Sprintf("timeout %ds -> %ds", sb.Timeout, d.Spec.Timeout),
+				Timeout:   d.Spec.Timeout,
+			})
+		}
+		switch want := d.state(); {
+		case want == StatePaused && (sb.Status == models.StatusRunning || sb.Status == models.StatusStarting):
+			plan.Actions = append(plan.Actions, Action{Type: ActionPause, Member: member, SandboxID: sb.SandboxID, Name: sb.Name, Reason: "status " + sb.Status + " -> paused"})
+		case want == StateRunning && (sb.Status == models.StatusPaused || sb.Status == models.StatusPausing):
+			plan.Actions = append(plan.Actions, Action{Type: ActionResume, Member: member, SandboxID: sb.SandboxID, Name: sb.Name, Reason: "status " + sb.Status + " -> running"})
+		}
+		if len(plan.Actions) == before {
+			plan.Unchanged++
+		}
+		for _, drift := range specDrift(d.Spec, sb) {
+			plan.Warnings = append(plan.Warnings, fmt.Sprintf("%s: sandbox %s has %s; delete it to recreate from the manifest", member, sb.SandboxID, drift))
+		}
+	}
+
+	for _, sb := range orphans {
+		plan.Orphans = append(plan.Orphans, sb.SandboxID)
+		if opts.Prune {
+			plan.Actions = append(plan.Actions, Action{Type: ActionDelete, SandboxID: sb.SandboxID, Name: sb.Name, Reason: "not in the manifest"})
+		}
+	}
+	return plan, nil
+}
+
+// specDrift describes create-time settings of sb that differ from spec and cannot be changed in place
+func specDrift(spec models.CreateSandboxRequest, sb models.Sandbox) []string {
+	var drift []string
+	check := func(field string, want, got int) {
+		if want != 0 && want != got {
+			drift = append(drift, fmt.Sprintf("%s %d, want %d", field, got, want))
+		}
+	}
+	check("cpu_count", spec.CPUCount, sb.CPUCount)
+	check("memory_mb", spec.MemoryMB, sb.MemoryMB)
+	check("storage_gb", spec.StorageGB, sb.StorageGB)
+	if spec.Template != "" && spec.Template != sb.TemplateID && (sb.TemplateName == nil || spec.Template != *sb.TemplateName) {
+		drift = append(drift, fmt.Sprintf("template %s, want %s", sb.TemplateID, spec.Template))
+	}
+	return drift
+}
+
+// Empty reports whether the plan has no actions
+func (p *Plan) Empty() bool {
+	return len(p.Actions) == 0
+}
+
+// Counts returns the number of actions of each type
+func (p *Plan) Counts() map[ActionType]int {
+	counts := make(map[ActionType]int, len(actionOrder))
+	for _, a := range p.Actions {
+		counts[a.Type]++
+	}
+	return counts
+}
+
+// WriteJSON writes the plan as indented JSON, for machine consumption
+func (p *Plan) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(p)
+}
+
+// WriteText writes the plan for humans
+func (p *Plan) WriteText(w io.Writer) error {
+	counts := p.Counts()
+	summary := make([]string, 0, len(actionOrder)+1)
+	for _, t := range actionOrder {
+		summary = append(summary, fmt.Sprintf("%d %s", counts[t], t))
+	}
+	summary = append(summary, fmt.Sprintf("%d unchanged", p.Unchanged))
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Plan for fleet %s: %s\n", p.Fleet, strings.Join(summary, ", "))
+	for _, a := range p.Actions {
+		fmt.Fprintf(&b, "  %s %-14s %s: %s\n", actionSymbol(a.Type), a.Type, a.target(), a.Reason)
+	}
+	if pruned := counts[ActionDelete]; len(p.Orphans) > pruned {
+		fmt.Fprintf(&b, "  %d sandbox(es) not in the manifest would be deleted with --prune: %s\n", len(p.Orphans), strings.Join(p.Orphans, ", "))
+	}
+	for _, warning := range p.Warnings {
+		fmt.Fprintf(&b, "warning: %s\n", warning)
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// target names the sandbox an action applies to
+func (a Action) target() string {
+	parts := make([]string, 0, 3)
+	for _, s := range []string{a.Member, a.SandboxID, a.Name} {
+		if s != "" {
+			parts = append(parts, s)
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+func actionSymbol(t ActionType) string {
+	switch t {
+	case ActionCreate:
+		return "+"
+	case ActionDelete:
+		return "-"
+	}
+	return "~"
+}
@@ -0,0 +1,143 @@
+package declarative
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/scalebox/scalebox-sdk-golang/models"
+	"github.com/scalebox/scalebox-sdk-golang/models/specfile"
+)
+
+func fleetMember(id, owner, status string, timeout int, created time.Time) models.Sandbox {
+	return models.Sandbox{
+		SandboxID:  id,
+		Name:       owner + "-dev-x1y2",
+		TemplateID: "base",
+		CPUCount:   2,
+		MemoryMB:   2048,
+		Timeout:    timeout,
+		Status:     status,
+		CreatedAt:  created,
+		Metadata:   map[string]string{MetadataFleet: "dev", "owner": owner},
+	}
+}
+
+func TestDiff(t *testing.T) {
+	m, err := ParseManifest([]byte(fleetYAML), specfile.FormatYAML)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t0 := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	observed := []models.Sandbox{
+		fleetMember("sbx-bob", "bob", models.StatusRunning, 600, t0),
+		fleetMember("sbx-carol", "carol", models.StatusPaused, 300, t0),
+		fleetMember("sbx-carol-dup", "carol", models.StatusRunning, 300, t0.Add(time.Hour)),
+		fleetMember("sbx-dave", "dave", models.StatusRunning, 300, t0),
+		fleetMember("sbx-alice-old", "alice", models.StatusTerminated, 3600, t0),
+		{SandboxID: "sbx-other", Status: models.StatusRunning, Metadata: map[string]string{MetadataFleet: "prod", "owner": "alice"}},
+	}
+	observed[1].CPUCount = 4
+
+	plan, err := Diff(m, observed, PlanOptions{})
+	if err != nil {
+		t.Fatalf("Diff failed: %v", err)
+	}
+	var got []string
+	for _, a := range plan.Actions {
+		got = append(got, string(a.Type)+" "+a.SandboxID)
+	}
+	want := []string{"create ", "update_timeout sbx-bob", "pause sbx-bob", "resume sbx-carol"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("Actions = %v, want %v", got, want)
+	}
+	if plan.Actions[0].Request.Metadata["owner"] != "alice" || plan.Actions[0].Pause {
+		t.Errorf("Unexpected create action %+v", plan.Actions[0])
+	}
+	if plan.Actions[1].Timeout != 3600 {
+		t.Errorf("Expected timeout 3600, got %d", plan.Actions[1].Timeout)
+	}
+	// A timeout extended past the manifest, e.g. by KeepAlive, is not shortened
+	observed[0].Timeout = 7200
+	if extended, err := Diff(m, observed, PlanOptions{}); err != nil || extended.Counts()[ActionUpdateTimeout] != 0 {
+		t.Errorf("Expected no timeout update for an extended sandbox, got %+v, %v", extended, err)
+	}
+	observed[0].Timeout = 600
+
+	if strings.Join(plan.Orphans, ",") != "sbx-dave,sbx-carol-dup" {
+		t.Errorf("Orphans = %v", plan.Orphans)
+	}
+	if len(plan.Warnings) != 2 || !strings.Contains(plan.Warnings[1], "cpu_count 4, want 2") {
+		t.Errorf("Warnings = %v", plan.Warnings)
+	}
+
+	pruned, err := Diff(m, observed, PlanOptions{Prune: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if counts := pruned.Counts(); counts[ActionDelete] != 2 {
+		t.Errorf("Expected 2 deletes with prune, got %v", counts)
+	}
+
+	// Overlapping identities cannot be resolved
+	m.Sandboxes = append(m.Sandboxes, Desired{Labels: map[string]string{"owner": "dave", "team": "ml"}})
+	m.Sandboxes = append(m.Sandboxes, Desired{Labels: map[string]string{"team": "ml"}})
+	observed[3].Metadata["team"] = "ml"
+	if _, err := Diff(m, observed, PlanOptions{}); err == nil {
+		t.Error("Expected a sandbox matching two members to be rejected")
+	}
+}
+
+func TestPlanOutput(t *testing.T) {
+	m, err := ParseManifest([]byte(fleetYAML), specfile.FormatYAML)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t0 := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	plan, err := Diff(m, []models.Sandbox{
+		fleetMember("sbx-carol", "carol", models.StatusRunning, 300, t0),
+		fleetMember("sbx-dave", "dave", models.StatusRunning, 300, t0),
+	}, PlanOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var text bytes.Buffer
+	if err := plan.WriteText(&text); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"Plan for fleet dev: 2 create, 0 update_timeout, 0 pause, 0 resume, 0 delete, 1 unchanged",
+		"+ create         owner=alice alice-dev: no sandbox in the fleet",
+		"owner=bob bob-dev: no sandbox in the fleet, pause once running",
+		"would be deleted with --prune: sbx-dave",
+	} {
+		if !strings.Contains(text.String(), want) {
+			t.Errorf("Text output missing %q:\n%s", want, text.String())
+		}
+	}
+
+	plan.Actions[0].Request.EnvVars = map[string]string{"API_TOKEN": "tok-123"}
+	plan.Actions[0].Request.ObjectStorage = &models.ObjectStorageConfig{URI: "s3://b/p", MountPoint: "/mnt", AccessKey: "ak-123", SecretKey: "sk-123"}
+	var out bytes.Buffer
+	if err := plan.WriteJSON(&out); err != nil {
+		t.Fatal(err)
+	}
+	for _, secret := range []string{"tok-123", "ak-123", "sk-123"} {
+		if strings.Contains(out.String(), secret) {
+			t.Errorf("JSON output leaks %q:\n%s", secret, out.String())
+		}
+	}
+	if plan.Actions[0].Request.EnvVars["API_TOKEN"] != "tok-123" || plan.Actions[0].Request.ObjectStorage.SecretKey != "sk-123" {
+		t.Error("Redacting the output must not modify the plan")
+	}
+	var decoded Plan
+	if err := json.Unmarshal(out.Bytes(), &decoded); err != nil {
+		t.Fatalf("JSON output does not decode: %v", err)
+	}
+	if len(decoded.Actions) != 2 || decoded.Actions[1].Request == nil || !decoded.Actions[1].Pause || decoded.Unchanged != 1 {
+		t.Errorf("Unexpected decoded plan %+v", decoded)
+	}
+}
@@ -8,6 +8,7 @@ import (
 
 	"github.com/scalebox/scalebox-sdk-golang/api/sandboxes"
 	"github.com/scalebox/scalebox-sdk-golang/client"
+	"github.com/scalebox/scalebox-sdk-golang/metrics"
 	"github.com/scalebox/scalebox-sdk-golang/models"
 )
 
@@ -90,15 +91,18 @@ func main() {
 		Step:  &step,
 	}
 
-	metrics, err := sandboxClient.GetMetrics(ctx, sandbox.SandboxID, metricsOpts)
+	metricsResp, err := sandboxClient.GetMetrics(ctx, sandbox.SandboxID, metricsOpts)
 	if err != nil {
 		log.Printf("获取指标失败: %v (可能沙箱尚未运行)", err)
 	} else {
-		fmt.Printf("指标数据点数量: %d\n", len(metrics.Metrics))
-		if len(metrics.Metrics) > 0 {
-			latest := metrics.Metrics[len(metrics.Metrics)-1]
+		series := metrics.FromResponse(metricsResp)
+		fmt.Printf("指标数据点数量: %d\n", len(series))
+		if len(series) > 0 {
+			latest := series[len(series)-1]
 			fmt.Printf("最新 CPU 使用率: %.2f%%\n", latest.CPUUsedPct)
-			fmt.Printf("最新内存使用: %d / %d MB\n", latest.MemUsed/1024/1024, latest.MemTotal/1024/1024)
+			fmt.Printf("最新内存使用: %s\n", metrics.FormatUsage(latest.MemUsed, latest.MemTotal))
+			cpu := series.Summarize(metrics.CPUPercent)
+			fmt.Printf("CPU 使用率 p50/p95/max: %.1f%% / %.1f%% / %.1f%%\n", cpu.P50, cpu.P95, cpu.Max)
 		}
 	}
 
@@ -122,14 +126,12 @@ func main() {
 
 	// 示例 8: 设置超时
 	fmt.Println("\n=== 设置超时 ===")
-	timeoutReq := models.SandboxTimeoutRequest{
-		Timeout: 600, // 10 分钟
-	}
+	timeoutReq := models.NewSandboxTimeoutRequest(10 * time.Minute)
 	updatedSandbox, err := sandboxClient.SetTimeout(ctx, sandbox.SandboxID, timeoutReq)
 	if err != nil {
 		log.Printf("设置超时失败: %v", err)
 	} else {
-		fmt.Printf("超时时间已更新: %d 秒\n", updatedSandbox.Timeout)
+		fmt.Printf("超时时间已更新: %s\n", updatedSandbox.TimeoutDuration())
 	}
 
 	// 示例 9: 错误处理
@@ -0,0 +1,267 @@
+// Package idle pauses sandboxes that sit idle, so they stop costing running time.
+//
+// Run lists the running sandboxes matching a selector, samples their metrics over Window and
+// treats a sandbox as idle when its CPU stays below CPUPercent and its memory use barely moves.
+// Idle sandboxes with AutoPause are paused and the others terminated, except those carrying
+// the opt-out label or started or resumed within the grace period. Every action is recorded
+// in the sandbox's metadata. With DryRun set nothing is changed and the report shows what
+// would have been. Watch repeats Run every Interval.
+package idle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/scalebox/scalebox-sdk-golang/api/sandboxes"
+	"github.com/scalebox/scalebox-sdk-golang/labels"
+	"github.com/scalebox/scalebox-sdk-golang/metrics"
+	"github.com/scalebox/scalebox-sdk-golang/models"
+)
+
+// Idle controller defaults
+const (
+	DefaultOptOutLabel   = "scalebox.idle.opt-out"
+	DefaultWindow        = 15 * time.Minute
+	DefaultGracePeriod   = 10 * time.Minute
+	DefaultInterval      = 5 * time.Minute
+	DefaultCPUPercent    = 2.0 // p95 CPU usage below which a sandbox is idle
+	DefaultMemoryPercent = 1.0 // Spread of memory use, in percent of MemTotal, below which a sandbox is idle
+)
+
+// Metadata labels recorded on the sandboxes the controller paused or terminated
+const (
+	LabelAction = "scalebox.idle.action" // "paused" or "terminated"
+	LabelAt     = "scalebox.idle.at"     // RFC3339 time of the decision
+)
+
+// Options configures Run and Watch
+type Options struct {
+	// Selector picks the sandboxes to watch, e.g. "team=ml"; required so a run is always scoped
+	Selector string
+	// Window is how long a sandbox must have been idle, defaults to DefaultWindow
+	Window time.Duration
+	// CPUPercent is the p95 CPU usage over the window below which a sandbox is idle.
+	// Zero means DefaultCPUPercent; a threshold of 0 would never find a sandbox idle.
+	CPUPercent float64
+	// MemoryPercent is the spread between the lowest and highest memory use over the window,
+	// in percent of MemTotal, below which a sandbox is idle. Zero means DefaultMemoryPercent;
+	// use a small positive value such as 0.01 to require memory use that does not move at all.
+	MemoryPercent float64
+	// GracePeriod leaves sandboxes alone for this long after they are created, started or
+	// resumed, defaults to DefaultGracePeriod
+	GracePeriod time.Duration
+	// OptOutLabel marks sandboxes that are never paused, whatever its value other than "false".
+	// Defaults to DefaultOptOutLabel.
+	OptOutLabel string
+	// DryRun reports what would be paused or terminated without changing anything
+	DryRun bool
+	// Interval is how often Watch runs, defaults to DefaultInterval
+	Interval time.Duration
+	// Now returns the current time, defaults to time.Now
+	Now func() time.Time
+}
+
+// Validate checks that the options are scoped and consistent
+func (o Options) Validate() error {
+	var errs []models.FieldError
+	if o.Selector == "" {
+		errs = append(errs, models.FieldError{Field: "selector", Message: "is required"})
+	} else if _, err := labels.Parse(o.Selector); err != nil {
+		errs = append(errs, models.FieldError{Field: "selector", Message: err.Error()})
+	}
+	if o.Window < 0 || o.GracePeriod < 0 || o.Interval < 0 {
+		errs = append(errs, models.FieldError{Field: "window", Message: "durations must not be negative"})
+	}
+	if o.CPUPercent < 0 || o.CPUPercent > 100 {
+		errs = append(errs, models.FieldError{Field: "cpu_percent", Message: "must be between 0 and 100"})
+	}
+	if o.MemoryPercent < 0 || o.MemoryPercent > 100 {
+		errs = append(errs, models.FieldError{Field: "memory_percent", Message: "must be between 0 and 100"})
+	}
+	if len(errs) > 0 {
+		return &models.ValidationError{Errors: errs}
+	}
+	return nil
+}
+
+// withDefaults fills in the zero options
+func (o Options) withDefaults() Options {
+	if o.Window == 0 {
+		o.Window = DefaultWindow
+	}
+	if o.CPUPercent == 0 {
+		o.CPUPercent = DefaultCPUPercent
+	}
+	if o.MemoryPercent == 0 {
+		o.MemoryPercent = DefaultMemoryPercent
+	}
+	if o.GracePeriod == 0 {
+		o.GracePeriod = DefaultGracePeriod
+	}
+	if o.OptOutLabel == "" {
+		o.OptOutLabel = DefaultOptOutLabel
+	}
+	if o.Interval == 0 {
+		o.Interval = DefaultInterval
+	}
+	if o.Now == nil {
+		o.Now = time.Now
+	}
+	return o
+}
+
+// Run evaluates the running sandboxes matching the selector once and pauses or terminates
+// the idle ones, unless DryRun is set. Failed actions are recorded in the report and returned
+// joined as the error.
+func Run(ctx context.Context, c *sandboxes.Client, opts Options) (*Report, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	opts = opts.withDefaults()
+
+	report := &Report{StartedAt: opts.Now(), DryRun: opts.DryRun, Window: opts.Window, Entries: []Entry{}}
+	list, err := c.ListAll(ctx, models.ListSandboxesOptions{LabelSelector: opts.Selector, Status: models.StatusRunning})
+	if err != nil {
+		return nil, err
+	}
+	report.Scanned = len(list)
+
+	// The list is already filtered; evaluate again in case the server ignored the selector
+	selector, _ := labels.Parse(opts.Selector)
+	var errs []error
+	for i := range list {
+		sb := &list[i]
+		if sb.Status != models.StatusRunning || !selector.Matches(sb.Metadata) {
+			continue
+		}
+		entry := evaluate(ctx, c, sb, opts, report.StartedAt)
+		if entry.Outcome == OutcomeFailed {
+			errs = append(errs, fmt.Errorf("%s: %s", sb.SandboxID, entry.Error))
+		}
+		report.Entries = append(report.Entries, entry)
+	}
+	return report, errors.Join(errs...)
+}
+
+// Watch calls Run every Interval until ctx ends, passing each report and error to onReport.
+// A failed run does not stop Watch; it returns the context error.
+func Watch(ctx context.Context, c *sandboxes.Client, opts Options, onReport func(*Report, error)) error {
+	if err := opts.Validate(); err != nil {
+		return err
+	}
+	opts = opts.withDefaults()
+	ticker := time.NewTicker(opts.Interval)
+	defer ticker.Stop()
+	for {
+		report, err := Run(ctx, c, opts)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if onReport != nil {
+			onReport(report, err)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// evaluate decides on one running sandbox and carries the decision out
+func evaluate(ctx context.Context, c *sandboxes.Client, sb *models.Sandbox, opts Options, now time.Time) Entry {
+	entry := Entry{SandboxID: sb.SandboxID, Name: sb.Name, AutoPause: sb.AutoPause}
+	if value, ok := sb.Metadata[opts.OptOutLabel]; ok && value != "false" {
+		entry.Outcome, entry.Reason = OutcomeOptedOut, "labelled "+opts.OptOutLabel
+		return entry
+	}
+	if active := activeSince(sb); now.Sub(active) < opts.GracePeriod {
+		entry.Outcome = OutcomeGrace
+		entry.Reason = fmt.Sprintf("active for %s (grace period %s)", now.Sub(active).Round(time.Second), opts.GracePeriod)
+		return entry
+	}
+
+	start := now.Add(-opts.Window)
+	resp, err := c.GetMetrics(ctx, sb.SandboxID, &models.GetSandboxMetricsOptions{Start: &start, End: &now})
+	if err != nil {
+		entry.Outcome, entry.Error = OutcomeFailed, fmt.Sprintf("get metrics: %v", err)
+		return entry
+	}
+	series := metrics.FromResponse(resp).Between(start, now.Add(time.Nanosecond))
+	// The points must cover the window, allowing a tenth of it for the first sample to arrive
+	if len(series) < 2 || series.Start().Sub(start) > opts.Window/10 {
+		entry.Outcome = OutcomeInsufficientData
+		entry.Reason = fmt.Sprintf("%d points do not cover the last %s", len(series), opts.Window)
+		return entry
+	}
+	cpu := series.Summarize(metrics.CPUPercent)
+	mem := series.Summarize(metrics.MemoryPercent)
+	entry.CPUP95, entry.MemorySpread = cpu.P95, mem.Max-mem.Min
+	if entry.CPUP95 >= opts.CPUPercent {
+		entry.Outcome = OutcomeActive
+		entry.Reason = fmt.Sprintf("cpu p95 %.1f%% (threshold %.1f%%)", entry.CPUP95, opts.CPUPercent)
+		return entry
+	}
+	if entry.MemorySpread >= opts.MemoryPercent {
+		entry.Outcome = OutcomeActive
+		entry.Reason = fmt.Sprintf("memory use moved %.1f%% of total (threshold %.1f%%)", entry.MemorySpread, opts.MemoryPercent)
+		return entry
+	}
+
+	entry.Reason = fmt.Sprintf("idle for %s: cpu p95 %.1f%%, memory moved %.1f%%", opts.Window, entry.CPUP95, entry.MemorySpread)
+	switch {
+	case opts.DryRun && sb.AutoPause:
+		entry.Outcome = OutcomeWouldPause
+		return entry
+	case opts.DryRun:
+		entry.Outcome = OutcomeWouldTerminate
+		return entry
+	}
+
+	// The decision is recorded before acting, since stopped sandboxes may reject label updates
+	outcome := OutcomeTerminated
+	if sb.AutoPause {
+		outcome = OutcomePaused
+	}
+	if _, err := c.AddLabels(ctx, sb.SandboxID, map[string]string{
+		LabelAction: string(outcome),
+		LabelAt:     now.UTC().Format(time.RFC3339),
+	}); err != nil {
+		entry.Outcome, entry.Error = OutcomeFailed, fmt.Sprintf("record decision: %v", err)
+		return entry
+	}
+	if outcome == OutcomePaused {
+		_, err = c.Pause(ctx, sb.SandboxID)
+	} else {
+		_, err = c.Terminate(ctx, sb.SandboxID, nil)
+	}
+	if err != nil {
+		entry.Outcome, entry.Error = OutcomeFailed, fmt.Sprintf("%s: %v", actionName(outcome), err)
+		// Withdraw the decision so the still running sandbox is not reported as stopped; best effort
+		_, _ = c.RemoveLabels(ctx, sb.SandboxID, LabelAction, LabelAt)
+		return entry
+	}
+	entry.Outcome = outcome
+	return entry
+}
+
+// actionName names the API call behind an outcome in error messages
+func actionName(outcome Outcome) string {
+	if outcome == OutcomePaused {
+		return "pause"
+	}
+	return "terminate"
+}
+
+// activeSince returns when the sandbox was last created, started or resumed
+func activeSince(sb *models.Sandbox) time.Time {
+	active := sb.CreatedAt
+	for _, t := range []*time.Time{sb.StartedAt, sb.ResumedAt} {
+		if t != nil && t.After(active) {
+			active = *t
+		}
+	}
+	return active
+}
@@ -0,0 +1,245 @@
+package idle
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/scalebox/scalebox-sdk-golang/api/sandboxes"
+	"github.com/scalebox/scalebox-sdk-golang/client"
+	"github.com/scalebox/scalebox-sdk-golang/models"
+)
+
+var now = time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+// idleServer is a fake API listing fixed sandboxes with per-sandbox metrics and recording actions
+type idleServer struct {
+	mu        sync.Mutex
+	sandboxes []models.Sandbox
+	metrics   map[string][]models.MetricsDataPoint
+	failures  map[string]bool // Sandboxes whose pause or terminate fails
+	actions   []string        // "<id>:<action>"
+	labels    map[string]map[string]string
+}
+
+func (s *idleServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	w.Header().Set("Content-Type", "application/json")
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/v1/sandboxes"), "/")
+	if len(parts) < 3 {
+		json.NewEncoder(w).Encode(models.SandboxListResponse{Sandboxes: s.sandboxes})
+		return
+	}
+	id, action := parts[1], parts[2]
+	switch action {
+	case "metrics":
+		json.NewEncoder(w).Encode(models.SandboxMetricsResponse{SandboxID: id, Status: models.StatusRunning, Metrics: s.metrics[id]})
+	case "labels":
+		// Like the real API, stopped sandboxes reject label updates
+		for _, done := range s.actions {
+			if strings.HasPrefix(done, id+":") {
+				w.WriteHeader(http.StatusConflict)
+				json.NewEncoder(w).Encode(map[string]string{"error": "sandbox is not running"})
+				return
+			}
+		}
+		if r.Method == http.MethodDelete {
+			for _, k := range strings.Split(r.URL.Query().Get("keys"), ",") {
+				delete(s.labels[id], k)
+			}
+		} else {
+			var req models.AddLabelsRequest
+			json.NewDecoder(r.Body).Decode(&req)
+			s.labels[id] = req.Labels
+		}
+		json.NewEncoder(w).Encode(models.Sandbox{SandboxID: id, Metadata: s.labels[id]})
+	case "pause", "terminate":
+		if s.failures[id] {
+			w.WriteHeader(http.StatusConflict)
+			json.NewEncoder(w).Encode(map[string]string{"error": "busy"})
+			return
+		}
+		s.actions = append(s.actions, id+":"+action)
+		json.NewEncoder(w).Encode(models.Sandbox{SandboxID: id})
+	}
+}
+
+func running(id string, autoPause bool, age time.Duration) models.Sandbox {
+	return models.Sandbox{
+		SandboxID: id,
+		Name:      "dev-" + id,
+		Status:    models.StatusRunning,
+		AutoPause: autoPause,
+		CreatedAt: now.Add(-age),
+		Metadata:  map[string]string{"team": "ml"},
+	}
+}
+
+// samples returns a point every 30 seconds over the last span with the given CPU and memory use
+func samples(span time.Duration, cpu func(i int) float64, memPct func(i int) float64) []models.MetricsDataPoint {
+	var out []models.MetricsDataPoint
+	for i := 0; time.Duration(i)*30*time.Second <= span; i++ {
+		out = append(out, models.MetricsDataPoint{
+			Timestamp:  now.Add(-span + time.Duration(i)*30*time.Second),
+			CPUUsedPct: cpu(i),
+			MemTotal:   1 << 30,
+			MemUsed:    int64(memPct(i) / 100 * (1 << 30)),
+		})
+	}
+	return out
+}
+
+func constant(v float64) func(int) float64 { return func(int) float64 { return v } }
+
+func TestRun(t *testing.T) {
+	resumed := now.Add(-5 * time.Minute)
+	fake := &idleServer{
+		sandboxes: []models.Sandbox{
+			running("sleepy", true, 2*time.Hour),
+			running("doomed", false, 2*time.Hour),
+			running("busy", true, 2*time.Hour),
+			running("leaky", true, 2*time.Hour),
+			running("keep", true, 2*time.Hour),
+			running("fresh", true, 3*time.Minute),
+			running("woken", true, 2*time.Hour),
+			running("new-metrics", true, 2*time.Hour),
+			{SandboxID: "other", Status: models.StatusRunning, CreatedAt: now.Add(-9 * time.Hour), Metadata: map[string]string{"team": "web"}},
+		},
+		metrics: map[string][]models.MetricsDataPoint{
+			"sleepy":      samples(15*time.Minute, constant(0.5), constant(40)),
+			"doomed":      samples(15*time.Minute, constant(0.1), constant(10)),
+			"busy":        samples(15*time.Minute, func(i int) float64 { return float64(i % 3 * 20) }, constant(40)),
+			"leaky":       samples(15*time.Minute, constant(0.5), func(i int) float64 { return 40 + float64(i)/10 }),
+			"keep":        samples(15*time.Minute, constant(0), constant(40)),
+			"new-metrics": samples(5*time.Minute, constant(0), constant(40)),
+		},
+		labels: make(map[string]map[string]string),
+	}
+	fake.sandboxes[4].Metadata[DefaultOptOutLabel] = "true"
+	fake.sandboxes[6].ResumedAt = &resumed
+	// One spike does not make a sandbox busy, a sustained load does
+	fake.metrics["sleepy"][10].CPUUsedPct = 80
+	server := httptest.NewServer(fake)
+	defer server.Close()
+	c := sandboxes.NewClient(client.NewClient(server.URL, "test-api-key"))
+	ctx := context.Background()
+	clock := func() time.Time { return now }
+
+	outcomes := func(r *Report) string {
+		var got []string
+		for _, e := range r.Entries {
+			got = append(got, e.SandboxID+":"+string(e.Outcome))
+		}
+		return strings.Join(got, ",")
+	}
+
+	// Dry run: nothing changes
+	report, err := Run(ctx, c, Options{Selector: "team=ml", DryRun: true, Now: clock})
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	want := "sleepy:would_pause,doomed:would_terminate,busy:active,leaky:active,keep:opted_out,fresh:grace,woken:grace,new-metrics:insufficient_data"
+	if got := outcomes(report); got != want {
+		t.Errorf("Outcomes = %s\nwant %s", got, want)
+	}
+	if len(fake.actions) != 0 || len(fake.labels) != 0 {
+		t.Fatalf("Dry run changed sandboxes: %v %v", fake.actions, fake.labels)
+	}
+	if report.Scanned != 8 || report.Entries[0].CPUP95 >= DefaultCPUPercent || report.Entries[3].MemorySpread < DefaultMemoryPercent {
+		t.Errorf("Unexpected report %+v", report.Entries)
+	}
+
+	// Idle sandboxes are paused or terminated and the decision is recorded; failures are reported
+	fake.failures = map[string]bool{"doomed": true}
+	report, err = Run(ctx, c, Options{Selector: "team=ml", Now: clock})
+	if err == nil || !strings.Contains(err.Error(), "doomed: terminate") {
+		t.Errorf("Expected the failed termination to be returned, got %v", err)
+	}
+	if strings.Join(fake.actions, ",") != "sleepy:pause" || report.Count(OutcomePaused) != 1 || report.Count(OutcomeFailed) != 1 {
+		t.Errorf("Unexpected actions %v, outcomes %s", fake.actions, outcomes(report))
+	}
+	if l := fake.labels["sleepy"]; l[LabelAction] != "paused" || l[LabelAt] != "2024-06-01T12:00:00Z" {
+		t.Errorf("Decision not recorded: %v", fake.labels)
+	}
+	if l := fake.labels["doomed"]; len(l) != 0 {
+		t.Errorf("Expected the failed decision to be withdrawn, got %v", l)
+	}
+
+	fake.failures = nil
+	fake.actions = nil
+	report, err = Run(ctx, c, Options{Selector: "team=ml", Now: clock})
+	if err != nil || report.Count(OutcomeTerminated) != 1 || fake.labels["doomed"][LabelAction] != "terminated" {
+		t.Errorf("Expected doomed to be terminated, got %v %s", err, outcomes(report))
+	}
+
+	// Raised thresholds make the leaky sandbox idle too
+	report, err = Run(ctx, c, Options{Selector: "team=ml", MemoryPercent: 5, DryRun: true, Now: clock})
+	if err != nil || report.Entries[3].Outcome != OutcomeWouldPause {
+		t.Errorf("Expected leaky to be idle with a 5%% memory threshold, got %v %s", err, outcomes(report))
+	}
+
+	var text, js bytes.Buffer
+	if err := report.WriteText(&text); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(text.String(), "(dry run) over 15m0s: scanned 8, evaluated 8, 0 paused, 0 terminated, 2 would pause, 1 would terminate, 0 failed") {
+		t.Errorf("Unexpected text report:\n%s", text.String())
+	}
+	if err := report.WriteJSON(&js); err != nil {
+		t.Fatal(err)
+	}
+	var decoded Report
+	if err := json.Unmarshal(js.Bytes(), &decoded); err != nil || len(decoded.Entries) != 8 {
+		t.Errorf("JSON report does not round trip: %v\n%s", err, js.String())
+	}
+}
+
+func TestWatch(t *testing.T) {
+	fake := &idleServer{
+		sandboxes: []models.Sandbox{running("sleepy", true, 2*time.Hour)},
+		metrics:   map[string][]models.MetricsDataPoint{"sleepy": samples(15*time.Minute, constant(0), constant(40))},
+		labels:    make(map[string]map[string]string),
+	}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+	c := sandboxes.NewClient(client.NewClient(server.URL, "test-api-key"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	runs := 0
+	err := Watch(ctx, c, Options{Selector: "team=ml", DryRun: true, Interval: 10 * time.Millisecond, Now: func() time.Time { return now }},
+		func(r *Report, err error) {
+			if err != nil || r.Count(OutcomeWouldPause) != 1 {
+				t.Errorf("Unexpected run: %v %+v", err, r)
+			}
+			if runs++; runs == 3 {
+				cancel()
+			}
+		})
+	if err != context.Canceled || runs != 3 {
+		t.Errorf("Watch = %v after %d runs, want context.Canceled after 3", err, runs)
+	}
+}
+
+func TestOptionsValidate(t *testing.T) {
+	err := Options{Selector: "a in", Window: -time.Minute, CPUPercent: 101, MemoryPercent: -1}.Validate()
+	verr, ok := err.(*models.ValidationError)
+	if !ok {
+		t.Fatalf("Expected *models.ValidationError, got %v", err)
+	}
+	for _, field := range []string{"selector", "window", "cpu_percent", "memory_percent"} {
+		if !verr.HasField(field) {
+			t.Errorf("Expected an error for %s, got %v", field, verr)
+		}
+	}
+	if err := (Options{}).Validate(); err == nil {
+		t.Error("Expected unscoped options to be rejected")
+	}
+}
@@ -0,0 +1,84 @@
+package idle
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// Outcome is what a run did with a running sandbox
+type Outcome string
+
+// Idle controller outcomes
+const (
+	OutcomePaused           Outcome = "paused"
+	OutcomeTerminated       Outcome = "terminated"
+	OutcomeWouldPause       Outcome = "would_pause"       // Dry run
+	OutcomeWouldTerminate   Outcome = "would_terminate"   // Dry run
+	OutcomeActive           Outcome = "active"            // Above a threshold
+	OutcomeOptedOut         Outcome = "opted_out"         // Carries the opt-out label
+	OutcomeGrace            Outcome = "grace"             // Within the grace period
+	OutcomeInsufficientData Outcome = "insufficient_data" // Metrics do not cover the window
+	OutcomeFailed           Outcome = "failed"
+)
+
+// Report describes an idle controller run
+type Report struct {
+	StartedAt time.Time     `json:"started_at"`
+	DryRun    bool          `json:"dry_run"`
+	Window    time.Duration `json:"window"`
+	Scanned   int           `json:"scanned"` // Sandboxes returned by List
+	Entries   []Entry       `json:"entries"` // Running sandboxes matching the selector
+}
+
+// Entry is the decision on one running sandbox
+type Entry struct {
+	SandboxID    string  `json:"sandbox_id"`
+	Name         string  `json:"name"`
+	AutoPause    bool    `json:"auto_pause"`
+	CPUP95       float64 `json:"cpu_p95"`       // Over the window, when sampled
+	MemorySpread float64 `json:"memory_spread"` // Highest minus lowest memory use in percent of MemTotal, when sampled
+	Outcome      Outcome `json:"outcome"`
+	Reason       string  `json:"reason"`
+	Error        string  `json:"error,omitempty"`
+}
+
+// Count returns the number of entries with the given outcome
+func (r *Report) Count(outcome Outcome) int {
+	n := 0
+	for _, e := range r.Entries {
+		if e.Outcome == outcome {
+			n++
+		}
+	}
+	return n
+}
+
+// WriteJSON writes the report as indented JSON, for machine consumption
+func (r *Report) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+// WriteText writes the report for humans
+func (r *Report) WriteText(w io.Writer) error {
+	var b strings.Builder
+	mode := ""
+	if r.DryRun {
+		mode = " (dry run)"
+	}
+	fmt.Fprintf(&b, "Idle run at %s%s over %s: scanned %d, evaluated %d, %d paused, %d terminated, %d would pause, %d would terminate, %d failed\n",
+		r.StartedAt.Format(time.RFC3339), mode, r.Window, r.Scanned, len(r.Entries),
+		r.Count(OutcomePaused), r.Count(OutcomeTerminated), r.Count(OutcomeWouldPause), r.Count(OutcomeWouldTerminate), r.Count(OutcomeFailed))
+	for _, e := range r.Entries {
+		fmt.Fprintf(&b, "  %-17s %s %s: %s\n", e.Outcome, e.SandboxID, e.Name, e.Reason)
+		if e.Error != "" {
+			fmt.Fprintf(&b, "    error: %s\n", e.Error)
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
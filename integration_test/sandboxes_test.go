@@ -4,36 +4,18 @@ package integration
 
 import (
 	"context"
-	"os"
 	"strings"
 	"testing"
 	"time"
 
-	"github.com/scalebox/scalebox-sdk-golang/api/sandboxes"
 	"github.com/scalebox/scalebox-sdk-golang/client"
 	"github.com/scalebox/scalebox-sdk-golang/models"
+	"github.com/scalebox/scalebox-sdk-golang/scaleboxtest"
 )
 
-// setupClient 创建测试客户端，从环境变量读取配置
-func setupClient(t *testing.T) *sandboxes.Client {
-	baseURL := os.Getenv("SCALEBOX_BASE_URL")
-	apiKey := os.Getenv("SCALEBOX_API_KEY")
-
-	if baseURL == "" {
-		t.Skip("跳过集成测试: SCALEBOX_BASE_URL 环境变量未设置")
-	}
-	if apiKey == "" {
-		t.Skip("跳过集成测试: SCALEBOX_API_KEY 环境变量未设置")
-	}
-
-	baseClient := client.NewClient(baseURL, apiKey)
-	return sandboxes.NewClient(baseClient)
-}
-
 // TestIntegrationCreateSandbox 测试创建沙箱
 func TestIntegrationCreateSandbox(t *testing.T) {
-	sandboxClient := setupClient(t)
-	ctx := context.Background()
+	sandboxClient := scaleboxtest.NewClient(t)
 
 	createReq := models.CreateSandboxRequest{
 		Name:      "integration-test-sandbox",
@@ -48,10 +30,8 @@ func TestIntegrationCreateSandbox(t *testing.T) {
 		},
 	}
 
-	sandbox, err := sandboxClient.Create(ctx, createReq)
-	if err != nil {
-		t.Fatalf("创建沙箱失败: %v", err)
-	}
+	// 带上测试名和运行 ID 标签，并通过 t.Cleanup 删除，测试失败时也不会遗留沙箱
+	sandbox := scaleboxtest.NewSandboxWith(t, sandboxClient, createReq)
 
 	if sandbox.SandboxID == "" {
 		t.Error("沙箱 ID 不应为空")
@@ -68,16 +48,11 @@ func TestIntegrationCreateSandbox(t *testing.T) {
 	}
 
 	t.Logf("创建成功! Sandbox ID: %s, 状态: %s", sandbox.SandboxID, sandbox.Status)
-
-	// 清理：删除创建的沙箱
-	defer func() {
-		_, _ = sandboxClient.Delete(ctx, sandbox.SandboxID, nil)
-	}()
 }
 
 // TestIntegrationGetSandbox 测试获取沙箱详情
 func TestIntegrationGetSandbox(t *testing.T) {
-	sandboxClient := setupClient(t)
+	sandboxClient := scaleboxtest.NewClient(t)
 	ctx := context.Background()
 
 	// 先创建一个沙箱用于测试
@@ -89,13 +64,7 @@ func TestIntegrationGetSandbox(t *testing.T) {
 		StorageGB: 2,
 	}
 
-	sandbox, err := sandboxClient.Create(ctx, createReq)
-	if err != nil {
-		t.Fatalf("创建测试沙箱失败: %v", err)
-	}
-	defer func() {
-		_, _ = sandboxClient.Delete(ctx, sandbox.SandboxID, nil)
-	}()
+	sandbox := scaleboxtest.NewSandboxWith(t, sandboxClient, createReq)
 
 	// 测试获取详情
 	gotSandbox, err := sandboxClient.Get(ctx, sandbox.SandboxID)
@@ -119,7 +88,7 @@ func TestIntegrationGetSandbox(t *testing.T) {
 
 // TestIntegrationListSandboxes 测试列出沙箱
 func TestIntegrationListSandboxes(t *testing.T) {
-	sandboxClient := setupClient(t)
+	sandboxClient := scaleboxtest.NewClient(t)
 	ctx := context.Background()
 
 	// 先创建一个测试沙箱，确保有数据可测试
@@ -131,13 +100,7 @@ func TestIntegrationListSandboxes(t *testing.T) {
 		StorageGB: 2,
 	}
 
-	testSandbox, err := sandboxClient.Create(ctx, createReq)
-	if err != nil {
-		t.Fatalf("创建测试沙箱失败: %v", err)
-	}
-	defer func() {
-		_, _ = sandboxClient.Delete(ctx, testSandbox.SandboxID, nil)
-	}()
+	testSandbox := scaleboxtest.NewSandboxWith(t, sandboxClient, createReq)
 
 	// 等待一小段时间，确保沙箱状态已更新
 	time.Sleep(2 * time.Second)
@@ -175,7 +138,7 @@ func TestIntegrationListSandboxes(t *testing.T) {
 
 // TestIntegrationGetSandboxStatus 测试获取沙箱状态
 func TestIntegrationGetSandboxStatus(t *testing.T) {
-	sandboxClient := setupClient(t)
+	sandboxClient := scaleboxtest.NewClient(t)
 	ctx := context.Background()
 
 	// 先创建一个沙箱用于测试
@@ -187,13 +150,7 @@ func TestIntegrationGetSandboxStatus(t *testing.T) {
 		StorageGB: 2,
 	}
 
-	sandbox, err := sandboxClient.Create(ctx, createReq)
-	if err != nil {
-		t.Fatalf("创建测试沙箱失败: %v", err)
-	}
-	defer func() {
-		_, _ = sandboxClient.Delete(ctx, sandbox.SandboxID, nil)
-	}()
+	sandbox := scaleboxtest.NewSandboxWith(t, sandboxClient, createReq)
 
 	status, err := sandboxClient.GetStatus(ctx, sandbox.SandboxID)
 	if err != nil {
@@ -219,7 +176,7 @@ func TestIntegrationGetSandboxStatus(t *testing.T) {
 
 // TestIntegrationGetSandboxMetrics 测试获取沙箱指标
 func TestIntegrationGetSandboxMetrics(t *testing.T) {
-	sandboxClient := setupClient(t)
+	sandboxClient := scaleboxtest.NewClient(t)
 	ctx := context.Background()
 
 	// 先创建一个沙箱用于测试
@@ -231,13 +188,7 @@ func TestIntegrationGetSandboxMetrics(t *testing.T) {
 		StorageGB: 2,
 	}
 
-	sandbox, err := sandboxClient.Create(ctx, createReq)
-	if err != nil {
-		t.Fatalf("创建测试沙箱失败: %v", err)
-	}
-	defer func() {
-		_, _ = sandboxClient.Delete(ctx, sandbox.SandboxID, nil)
-	}()
+	sandbox := scaleboxtest.NewSandboxWith(t, sandboxClient, createReq)
 
 	// 等待沙箱启动并生成一些指标数据
 	time.Sleep(5 * time.Second)
@@ -298,7 +249,7 @@ func TestIntegrationGetSandboxMetrics(t *testing.T) {
 
 // TestIntegrationPauseSandbox 测试暂停沙箱
 func TestIntegrationPauseSandbox(t *testing.T) {
-	sandboxClient := setupClient(t)
+	sandboxClient := scaleboxtest.NewClient(t)
 	ctx := context.Background()
 
 	// 先创建一个沙箱用于测试
@@ -310,49 +261,10 @@ func TestIntegrationPauseSandbox(t *testing.T) {
 		StorageGB: 2,
 	}
 
-	sandbox, err := sandboxClient.Create(ctx, createReq)
-	if err != nil {
-		t.Fatalf("创建测试沙箱失败: %v", err)
-	}
-	defer func() {
-		_, _ = sandboxClient.Delete(ctx, sandbox.SandboxID, nil)
-	}()
+	sandbox := scaleboxtest.NewSandboxWith(t, sandboxClient, createReq)
 
-	// 定时检查沙箱状态，直到状态变为 running
+	// NewSandboxWith 已等待沙箱进入 running
 	// 暂停操作需要 DaemonSet 保护可写层，只有 running 状态的沙箱才能暂停
-	maxWaitTime := 30 * time.Second
-	checkInterval := 2 * time.Second
-	deadline := time.Now().Add(maxWaitTime)
-
-	t.Logf("等待沙箱状态变为 running...")
-	for time.Now().Before(deadline) {
-		sandboxStatus, err := sandboxClient.GetStatus(ctx, sandbox.SandboxID)
-		if err != nil {
-			t.Fatalf("获取沙箱状态失败: %v", err)
-		}
-
-		if sandboxStatus.Status == "running" {
-			t.Logf("沙箱状态已变为 running，可以执行暂停操作")
-			break
-		}
-
-		if sandboxStatus.Status == "failed" || sandboxStatus.Status == "terminated" {
-			t.Fatalf("沙箱状态异常（%s），无法执行暂停操作", sandboxStatus.Status)
-		}
-
-		// 等待一段时间后再次检查
-		time.Sleep(checkInterval)
-		t.Logf("当前状态: %s，继续等待...", sandboxStatus.Status)
-	}
-
-	// 最终检查：确保状态是 running
-	finalStatus, err := sandboxClient.GetStatus(ctx, sandbox.SandboxID)
-	if err != nil {
-		t.Fatalf("获取沙箱状态失败: %v", err)
-	}
-	if finalStatus.Status != "running" {
-		t.Fatalf("沙箱在 %v 内未达到 running 状态（当前: %s），测试失败", maxWaitTime, finalStatus.Status)
-	}
 
 	// 调用暂停 API（异步操作，立即返回）
 	pausedSandbox, err := sandboxClient.Pause(ctx, sandbox.SandboxID)
@@ -385,7 +297,7 @@ func TestIntegrationPauseSandbox(t *testing.T) {
 
 // TestIntegrationResumeSandbox 测试恢复沙箱
 func TestIntegrationResumeSandbox(t *testing.T) {
-	sandboxClient := setupClient(t)
+	sandboxClient := scaleboxtest.NewClient(t)
 	ctx := context.Background()
 
 	// 先创建一个沙箱用于测试
@@ -397,48 +309,10 @@ func TestIntegrationResumeSandbox(t *testing.T) {
 		StorageGB: 2,
 	}
 
-	sandbox, err := sandboxClient.Create(ctx, createReq)
-	if err != nil {
-		t.Fatalf("创建测试沙箱失败: %v", err)
-	}
-	defer func() {
-		_, _ = sandboxClient.Delete(ctx, sandbox.SandboxID, nil)
-	}()
-
-	// 定时检查沙箱状态，直到状态变为 running
-	maxWaitTime := 30 * time.Second
-	checkInterval := 2 * time.Second
-	deadline := time.Now().Add(maxWaitTime)
-
-	t.Logf("等待沙箱状态变为 running...")
-	for time.Now().Before(deadline) {
-		sandboxStatus, err := sandboxClient.GetStatus(ctx, sandbox.SandboxID)
-		if err != nil {
-			t.Fatalf("获取沙箱状态失败: %v", err)
-		}
-
-		if sandboxStatus.Status == "running" {
-			t.Logf("沙箱状态已变为 running，可以执行暂停操作")
-			break
-		}
+	sandbox := scaleboxtest.NewSandboxWith(t, sandboxClient, createReq)
 
-		if sandboxStatus.Status == "failed" || sandboxStatus.Status == "terminated" {
-			t.Fatalf("沙箱状态异常（%s），无法执行暂停操作", sandboxStatus.Status)
-		}
-
-		// 等待一段时间后再次检查
-		time.Sleep(checkInterval)
-		t.Logf("当前状态: %s，继续等待...", sandboxStatus.Status)
-	}
-
-	// 最终检查：确保状态是 running
-	finalStatus, err := sandboxClient.GetStatus(ctx, sandbox.SandboxID)
-	if err != nil {
-		t.Fatalf("获取沙箱状态失败: %v", err)
-	}
-	if finalStatus.Status != "running" {
-		t.Fatalf("沙箱在 %v 内未达到 running 状态（当前: %s），测试失败", maxWaitTime, finalStatus.Status)
-	}
+	// NewSandboxWith 已等待沙箱进入 running
+	// 暂停操作需要 DaemonSet 保护可写层，只有 running 状态的沙箱才能暂停
 
 	// 先暂停沙箱
 	pausedSandbox, err := sandboxClient.Pause(ctx, sandbox.SandboxID)
@@ -460,36 +334,7 @@ func TestIntegrationResumeSandbox(t *testing.T) {
 	t.Logf("暂停请求已提交，当前沙箱状态: %s", pausedSandbox.Status)
 
 	// 等待沙箱状态变为 paused
-	deadline = time.Now().Add(maxWaitTime)
-	t.Logf("等待沙箱状态变为 paused...")
-	for time.Now().Before(deadline) {
-		sandboxStatus, err := sandboxClient.GetStatus(ctx, sandbox.SandboxID)
-		if err != nil {
-			t.Fatalf("获取沙箱状态失败: %v", err)
-		}
-
-		if sandboxStatus.Status == "paused" {
-			t.Logf("沙箱状态已变为 paused，可以执行恢复操作")
-			break
-		}
-
-		if sandboxStatus.Status == "failed" || sandboxStatus.Status == "terminated" {
-			t.Fatalf("沙箱状态异常（%s），无法执行恢复操作", sandboxStatus.Status)
-		}
-
-		// 等待一段时间后再次检查
-		time.Sleep(checkInterval)
-		t.Logf("当前状态: %s，继续等待...", sandboxStatus.Status)
-	}
-
-	// 最终检查：确保状态是 paused
-	pausedStatus, err := sandboxClient.GetStatus(ctx, sandbox.SandboxID)
-	if err != nil {
-		t.Fatalf("获取沙箱状态失败: %v", err)
-	}
-	if pausedStatus.Status != "paused" {
-		t.Fatalf("沙箱在 %v 内未达到 paused 状态（当前: %s），测试失败", maxWaitTime, pausedStatus.Status)
-	}
+	scaleboxtest.RequireEventualStatus(t, sandboxClient, sandbox.SandboxID, models.StatusPaused, 30*time.Second)
 
 	// 调用恢复 API（异步操作，立即返回）
 	resumedSandbox, err := sandboxClient.Resume(ctx, sandbox.SandboxID)
@@ -519,7 +364,7 @@ func TestIntegrationResumeSandbox(t *testing.T) {
 
 // TestIntegrationSetTimeout 测试设置超时
 func TestIntegrationSetTimeout(t *testing.T) {
-	sandboxClient := setupClient(t)
+	sandboxClient := scaleboxtest.NewClient(t)
 	ctx := context.Background()
 
 	// 先创建一个沙箱用于测试
@@ -532,13 +377,7 @@ func TestIntegrationSetTimeout(t *testing.T) {
 		Timeout:   300,
 	}
 
-	sandbox, err := sandboxClient.Create(ctx, createReq)
-	if err != nil {
-		t.Fatalf("创建测试沙箱失败: %v", err)
-	}
-	defer func() {
-		_, _ = sandboxClient.Delete(ctx, sandbox.SandboxID, nil)
-	}()
+	sandbox := scaleboxtest.NewSandboxWith(t, sandboxClient, createReq)
 
 	timeoutReq := models.SandboxTimeoutRequest{
 		Timeout: 600, // 10 分钟
@@ -571,7 +410,7 @@ func TestIntegrationSetTimeout(t *testing.T) {
 
 // TestIntegrationErrorHandling 测试错误处理
 func TestIntegrationErrorHandling(t *testing.T) {
-	sandboxClient := setupClient(t)
+	sandboxClient := scaleboxtest.NewClient(t)
 	ctx := context.Background()
 
 	// 测试获取不存在的沙箱
@@ -0,0 +1,29 @@
+// Package cliutil holds the setup shared by the scalebox commands
+package cliutil
+
+import (
+	"errors"
+	"os"
+
+	"github.com/scalebox/scalebox-sdk-golang/api/sandboxes"
+	"github.com/scalebox/scalebox-sdk-golang/client"
+)
+
+// DefaultBaseURL is used when SCALEBOX_BASE_URL is not set
+const DefaultBaseURL = "https://api.scalebox.com"
+
+// ErrUsage reports that usage was already printed
+var ErrUsage = errors.New("usage")
+
+// ClientFromEnv builds a sandboxes client from SCALEBOX_BASE_URL and SCALEBOX_API_KEY
+func ClientFromEnv() (*sandboxes.Client, error) {
+	apiKey := os.Getenv("SCALEBOX_API_KEY")
+	if apiKey == "" {
+		return nil, errors.New("SCALEBOX_API_KEY is not set")
+	}
+	baseURL := os.Getenv("SCALEBOX_BASE_URL")
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+	return sandboxes.NewClient(client.NewClient(baseURL, apiKey)), nil
+}
@@ -0,0 +1,490 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"sort"
+	"strings"
+)
+
+// generate renders the models and client files for an OpenAPI document
+func generate(data []byte, pkg, source string) (map[string][]byte, error) {
+	doc, err := parseDocument(data)
+	if err != nil {
+		return nil, err
+	}
+
+	models, err := genModels(doc, pkg, source)
+	if err != nil {
+		return nil, err
+	}
+	client, err := genClient(doc, pkg, source)
+	if err != nil {
+		return nil, err
+	}
+	return map[string][]byte{
+		"models.gen.go": models,
+		"client.gen.go": client,
+	}, nil
+}
+
+func header(buf *bytes.Buffer, pkg, source string, imports ...string) {
+	fmt.Fprintf(buf, "// Code generated by openapigen from %s. DO NOT EDIT.\n\n", source)
+	fmt.Fprintf(buf, "package %s\n\n", pkg)
+	if len(imports) > 0 {
+		buf.WriteString("import (\n")
+		for _, imp := range imports {
+			fmt.Fprintf(buf, "\t%q\n", imp)
+		}
+		buf.WriteString(")\n\n")
+	}
+}
+
+func gofmt(buf *bytes.Buffer) ([]byte, error) {
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("formatting generated code: %w\n%s", err, buf.Bytes())
+	}
+	return src, nil
+}
+
+func genModels(doc *document, pkg, source string) ([]byte, error) {
+	var body bytes.Buffer
+	usesTime, usesJSON := false, false
+
+	for _, name := range doc.Components.Schemas.Keys {
+		s := doc.Components.Schemas.Values[name]
+		if s.Type != "object" {
+			return nil, fmt.Errorf("schema %s: only object schemas are supported at the top level", name)
+		}
+		required := make(map[string]bool, len(s.Required))
+		for _, r := range s.Required {
+			if _, ok := s.Properties.Values[r]; !ok {
+				return nil, fmt.Errorf("schema %s: required property %q is not defined", name, r)
+			}
+			required[r] = true
+		}
+
+		fmt.Fprintf(&body, "// %s is generated from #/components/schemas/%s\n", name, name)
+		fmt.Fprintf(&body, "type %s struct {\n", name)
+		for _, prop := range s.Properties.Keys {
+			ps := s.Properties.Values[prop]
+			typ, err := goType(ps, required[prop])
+			if err != nil {
+				return nil, fmt.Errorf("schema %s, property %s: %w", name, prop, err)
+			}
+			usesTime = usesTime || strings.Contains(typ, "time.")
+			usesJSON = usesJSON || strings.Contains(typ, "json.")
+			tag := prop
+			if !required[prop] {
+				tag += ",omitempty"
+			}
+			fmt.Fprintf(&body, "\t%s %s `json:%q`", goName(prop), typ, tag)
+			if ps.Description != "" {
+				fmt.Fprintf(&body, " // %s", oneLine(ps.Description))
+			}
+			body.WriteString("\n")
+		}
+		body.WriteString("}\n\n")
+	}
+
+	var imports []string
+	if usesJSON {
+		imports = append(imports, "encoding/json")
+	}
+	if usesTime {
+		imports = append(imports, "time")
+	}
+	var buf bytes.Buffer
+	header(&buf, pkg, source, imports...)
+	buf.Write(body.Bytes())
+	return gofmt(&buf)
+}
+
+// goType maps a schema to a Go type. Optional scalars and references become pointers.
+func goType(s *schema, required bool) (string, error) {
+	ptr := ""
+	if !required {
+		ptr = "*"
+	}
+	if s.Ref != "" {
+		name, err := refName(s.Ref, "schemas")
+		if err != nil {
+			return "", err
+		}
+		return ptr + name, nil
+	}
+
+	switch s.Type {
+	case "string":
+		if s.Format == "date-time" {
+			return ptr + "time.Time", nil
+		}
+		return ptr + "string", nil
+	case "integer":
+		switch s.Format {
+		case "int32":
+			return ptr + "int32", nil
+		case "int64":
+			return ptr + "int64", nil
+		}
+		return ptr + "int", nil
+	case "number":
+		if s.Format == "float" {
+			return ptr + "float32", nil
+		}
+		return ptr + "float64", nil
+	case "boolean":
+		return ptr + "bool", nil
+	case "array":
+		if s.Items == nil {
+			return "", fmt.Errorf("array without items")
+		}
+		elem, err := goType(s.Items, true)
+		if err != nil {
+			return "", err
+		}
+		return "[]" + elem, nil
+	case "object":
+		if len(s.Properties.Keys) > 0 {
+			return "", fmt.Errorf("inline object schemas are not supported, use a $ref")
+		}
+		ap := s.AdditionalProperties
+		if ap.Kind == 0 || ap.Tag == "!!bool" {
+			return "map[string]interface{}", nil
+		}
+		var elem schema
+		if err := ap.Decode(&elem); err != nil {
+			return "", err
+		}
+		if elem.Type == "" && elem.Ref == "" {
+			return "map[string]interface{}", nil
+		}
+		typ, err := goType(&elem, true)
+		if err != nil {
+			return "", err
+		}
+		return "map[string]" + typ, nil
+	case "":
+		return "json.RawMessage", nil
+	}
+	return "", fmt.Errorf("unsupported schema type %q", s.Type)
+}
+
+var methodOrder = []string{"get", "put", "post", "delete", "patch"}
+
+type genOperation struct {
+	id, method, path string
+	op               *operation
+	pathParams       []*parameter
+	queryParams      []*parameter
+	headerParams     []*parameter
+	bodyType         string
+	bodyRequired     bool
+	resultType       string
+}
+
+func collectOperations(doc *document) ([]genOperation, error) {
+	var ops []genOperation
+	for _, path := range doc.Paths.Keys {
+		item := doc.Paths.Values[path]
+		for _, method := range item.Keys {
+			if !contains(methodOrder, method) {
+				return nil, fmt.Errorf("%s: unsupported path item key %q", path, method)
+			}
+		}
+		for _, method := range methodOrder {
+			op, ok := item.Values[method]
+			if !ok {
+				continue
+			}
+			g := genOperation{id: goName(op.OperationID), method: strings.ToUpper(method), path: path, op: op}
+			if op.OperationID == "" {
+				return nil, fmt.Errorf("%s %s: missing operationId", g.method, path)
+			}
+			for _, p := range op.Parameters {
+				p, err := doc.resolveParameter(p)
+				if err != nil {
+					return nil, fmt.Errorf("%s: %w", op.OperationID, err)
+				}
+				switch p.In {
+				case "path":
+					if !strings.Contains(path, "{"+p.Name+"}") {
+						return nil, fmt.Errorf("%s: path parameter %q does not appear in %s", op.OperationID, p.Name, path)
+					}
+					g.pathParams = append(g.pathParams, p)
+				case "query":
+					g.queryParams = append(g.queryParams, p)
+				case "header":
+					g.headerParams = append(g.headerParams, p)
+				default:
+					return nil, fmt.Errorf("%s: unsupported parameter location %q", op.OperationID, p.In)
+				}
+			}
+			if op.RequestBody != nil {
+				s := jsonSchema(op.RequestBody.Content)
+				if s == nil || s.Ref == "" {
+					return nil, fmt.Errorf("%s: request body must reference a component schema", op.OperationID)
+				}
+				name, err := refName(s.Ref, "schemas")
+				if err != nil {
+					return nil, err
+				}
+				g.bodyType = name
+				g.bodyRequired = op.RequestBody.Required
+			}
+			codes := append([]string(nil), op.Responses.Keys...)
+			sort.Strings(codes)
+			for _, code := range codes {
+				if !strings.HasPrefix(code, "2") {
+					continue
+				}
+				if s := jsonSchema(op.Responses.Values[code].Content); s != nil {
+					name, err := refName(s.Ref, "schemas")
+					if err != nil {
+						return nil, fmt.Errorf("%s: response %s: %w", op.OperationID, code, err)
+					}
+					g.resultType = name
+					break
+				}
+			}
+			ops = append(ops, g)
+		}
+	}
+	return ops, nil
+}
+
+func genClient(doc *document, pkg, source string) ([]byte, error) {
+	ops, err := collectOperations(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	var body bytes.Buffer
+	uses := map[string]bool{"context": true}
+
+	body.WriteString("// Operations lists the operations described by the spec, in document order\n")
+	body.WriteString("var Operations = []Operation{\n")
+	for _, g := range ops {
+		fmt.Fprintf(&body, "\t{ID: %q, Method: %q, Path: %q", g.op.OperationID, g.method, g.path)
+		if len(g.queryParams) > 0 {
+			names := make([]string, len(g.queryParams))
+			for i, p := range g.queryParams {
+				names[i] = fmt.Sprintf("%q", p.Name)
+			}
+			fmt.Fprintf(&body, ", QueryParams: []string{%s}", strings.Join(names, ", "))
+		}
+		body.WriteString("},\n")
+	}
+	body.WriteString("}\n\n")
+
+	for _, g := range ops {
+		params := append(append([]*parameter(nil), g.queryParams...), g.headerParams...)
+		if len(params) > 0 {
+			fmt.Fprintf(&body, "// %sParams holds the query and header parameters of %s\n", g.id, g.id)
+			fmt.Fprintf(&body, "type %sParams struct {\n", g.id)
+			for _, p := range params {
+				typ, err := goType(p.Schema, false)
+				if err != nil {
+					return nil, fmt.Errorf("%s: parameter %s: %w", g.op.OperationID, p.Name, err)
+				}
+				fmt.Fprintf(&body, "\t%s %s", goName(p.Name), typ)
+				if p.Description != "" {
+					fmt.Fprintf(&body, " // %s", oneLine(p.Description))
+				}
+				body.WriteString("\n")
+			}
+			body.WriteString("}\n\n")
+		}
+
+		args := []string{"ctx context.Context"}
+		for _, p := range g.pathParams {
+			args = append(args, varName(p.Name)+" string")
+		}
+		if g.bodyType != "" {
+			if g.bodyRequired {
+				args = append(args, "body "+g.bodyType)
+			} else {
+				args = append(args, "body *"+g.bodyType)
+			}
+		}
+		if len(params) > 0 {
+			args = append(args, "params *"+g.id+"Params")
+		}
+		result := "error"
+		if g.resultType != "" {
+			result = "(*" + g.resultType + ", error)"
+		}
+
+		summary := g.op.Summary
+		if summary == "" {
+			summary = g.op.OperationID
+		}
+		fmt.Fprintf(&body, "// %s calls %s %s: %s\n", g.id, g.method, g.path, lowerFirst(oneLine(summary)))
+		fmt.Fprintf(&body, "func (c *Client) %s(%s) %s {\n", g.id, strings.Join(args, ", "), result)
+
+		if len(g.pathParams) > 0 {
+			uses["fmt"] = true
+			format := g.path
+			var pathArgs []string
+			for _, p := range g.pathParams {
+				format = strings.ReplaceAll(format, "{"+p.Name+"}", "%s")
+				pathArgs = append(pathArgs, varName(p.Name))
+			}
+			fmt.Fprintf(&body, "\tpath := fmt.Sprintf(%q, %s)\n", format, strings.Join(pathArgs, ", "))
+		} else {
+			fmt.Fprintf(&body, "\tpath := %q\n", g.path)
+		}
+
+		query, err := genParamMap(&body, g, "query", g.queryParams, uses)
+		if err != nil {
+			return nil, err
+		}
+		headers, err := genParamMap(&body, g, "headers", g.headerParams, uses)
+		if err != nil {
+			return nil, err
+		}
+
+		reqBody := "nil"
+		if g.bodyType != "" {
+			reqBody = "body"
+			if !g.bodyRequired {
+				body.WriteString("\tvar reqBody interface{}\n\tif body != nil {\n\t\treqBody = body\n\t}\n")
+				reqBody = "reqBody"
+			}
+		}
+
+		if g.resultType != "" {
+			fmt.Fprintf(&body, "\tvar result %s\n", g.resultType)
+			fmt.Fprintf(&body, "\tif err := c.do(ctx, %q, path, %s, %s, %s, &result); err != nil {\n\t\treturn nil, err\n\t}\n", g.method, reqBody, query, headers)
+			body.WriteString("\treturn &result, nil\n}\n\n")
+		} else {
+			fmt.Fprintf(&body, "\treturn c.do(ctx, %q, path, %s, %s, %s, nil)\n}\n\n", g.method, reqBody, query, headers)
+		}
+	}
+
+	var imports []string
+	for _, imp := range []string{"context", "fmt", "net/url", "strconv", "time"} {
+		if uses[imp] {
+			imports = append(imports, imp)
+		}
+	}
+	var buf bytes.Buffer
+	header(&buf, pkg, source, imports...)
+	buf.Write(body.Bytes())
+	return gofmt(&buf)
+}
+
+// genParamMap emits code collecting params into a variable named name, and returns the
+// expression to pass to Client.do. Query parameters are collected into url.Values, with
+// array parameters repeated once per value; headers into a map[string]string.
+func genParamMap(body *bytes.Buffer, g genOperation, name string, params []*parameter, uses map[string]bool) (string, error) {
+	if len(params) == 0 {
+		return "nil", nil
+	}
+	values := name == "query"
+	if values {
+		uses["net/url"] = true
+		fmt.Fprintf(body, "\t%s := make(url.Values)\n\tif params != nil {\n", name)
+	} else {
+		fmt.Fprintf(body, "\t%s := make(map[string]string)\n\tif params != nil {\n", name)
+	}
+	for _, p := range params {
+		field := "params." + goName(p.Name)
+		typ, _ := goType(p.Schema, true)
+		if typ == "[]string" && values {
+			fmt.Fprintf(body, "\t\tfor _, v := range %s {\n\t\t\t%s.Add(%q, v)\n\t\t}\n", field, name, p.Name)
+			continue
+		}
+		var value string
+		switch typ {
+		case "string":
+			value = "*" + field
+		case "int":
+			uses["strconv"] = true
+			value = "strconv.Itoa(*" + field + ")"
+		case "int32", "int64":
+			uses["strconv"] = true
+			value = "strconv.FormatInt(int64(*" + field + "), 10)"
+		case "bool":
+			uses["strconv"] = true
+			value = "strconv.FormatBool(*" + field + ")"
+		case "time.Time":
+			uses["time"] = true
+			value = field + ".Format(time.RFC3339)"
+		default:
+			return "", fmt.Errorf("%s: unsupported %s parameter type %s", g.op.OperationID, p.In, typ)
+		}
+		if values {
+			fmt.Fprintf(body, "\t\tif %s != nil {\n\t\t\t%s.Set(%q, %s)\n\t\t}\n", field, name, p.Name, value)
+		} else {
+			fmt.Fprintf(body, "\t\tif %s != nil {\n\t\t\t%s[%q] = %s\n\t\t}\n", field, name, p.Name, value)
+		}
+	}
+	body.WriteString("\t}\n")
+	return name, nil
+}
+
+// initialisms are name parts rendered in upper case, following Go naming conventions
+var initialisms = map[string]bool{
+	"api": true, "cpu": true, "gb": true, "http": true, "id": true, "ip": true,
+	"json": true, "mb": true, "uri": true, "url": true,
+}
+
+// goName converts snake_case or camelCase names to exported Go identifiers
+func goName(name string) string {
+	var b strings.Builder
+	for _, part := range splitName(name) {
+		if initialisms[strings.ToLower(part)] {
+			b.WriteString(strings.ToUpper(part))
+			continue
+		}
+		b.WriteString(strings.ToUpper(part[:1]) + part[1:])
+	}
+	return b.String()
+}
+
+// varName converts a parameter name to an unexported Go identifier
+func varName(name string) string {
+	parts := splitName(name)
+	if len(parts) == 0 {
+		return name
+	}
+	return strings.ToLower(parts[0]) + goName(strings.Join(parts[1:], "_"))
+}
+
+func splitName(name string) []string {
+	var parts []string
+	for _, part := range strings.FieldsFunc(name, func(r rune) bool { return r == '_' || r == '-' }) {
+		start := 0
+		for i := 1; i < len(part); i++ {
+			if part[i] >= 'A' && part[i] <= 'Z' {
+				parts = append(parts, part[start:i])
+				start = i
+			}
+		}
+		parts = append(parts, part[start:])
+	}
+	return parts
+}
+
+func lowerFirst(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToLower(s[:1]) + s[1:]
+}
+
+func oneLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
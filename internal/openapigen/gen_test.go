@@ -0,0 +1,66 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestGeneratedFilesUpToDate fails when api/openapi was not regenerated after editing the spec
+func TestGeneratedFilesUpToDate(t *testing.T) {
+	dir := filepath.Join("..", "..", "api", "openapi")
+	data, err := os.ReadFile(filepath.Join(dir, "openapi.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	files, err := generate(data, "openapi", "openapi.yaml")
+	if err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+	for name, want := range files {
+		got, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got, want) {
+			t.Errorf("%s is out of date, run go generate ./api/openapi", name)
+		}
+	}
+}
+
+func TestGoName(t *testing.T) {
+	tests := map[string]string{
+		"sandbox_id":            "SandboxID",
+		"cpu_used_pct":          "CPUUsedPct",
+		"memory_mb":             "MemoryMB",
+		"envd_access_token":     "EnvdAccessToken",
+		"getSandboxStatus":      "GetSandboxStatus",
+		"allow_internet_access": "AllowInternetAccess",
+	}
+	for in, want := range tests {
+		if got := goName(in); got != want {
+			t.Errorf("goName(%q) = %q, want %q", in, got, want)
+		}
+	}
+	if got := varName("sandbox_id"); got != "sandboxID" {
+		t.Errorf("varName(sandbox_id) = %q", got)
+	}
+}
+
+func TestGenerateRejectsUnsupportedSchemas(t *testing.T) {
+	spec := []byte(`
+components:
+  schemas:
+    Bad:
+      type: object
+      properties:
+        nested:
+          type: object
+          properties:
+            x: {type: string}
+`)
+	if _, err := generate(spec, "p", "spec.yaml"); err == nil {
+		t.Error("expected an error for an inline object schema")
+	}
+}
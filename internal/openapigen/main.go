@@ -0,0 +1,44 @@
+// Command openapigen generates Go models and a low-level client from the
+// OpenAPI document in api/openapi. It supports the subset of OpenAPI 3 used
+// by that document: component schemas, $ref, path and query parameters and
+// JSON request and response bodies.
+//
+//	go run ./internal/openapigen -spec api/openapi/openapi.yaml -package openapi -out api/openapi
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+func main() {
+	specPath := flag.String("spec", "openapi.yaml", "OpenAPI document to read")
+	pkg := flag.String("package", "openapi", "Go package name of the generated files")
+	out := flag.String("out", ".", "Directory to write the generated files to")
+	flag.Parse()
+
+	data, err := os.ReadFile(*specPath)
+	if err != nil {
+		log.Fatalf("openapigen: %v", err)
+	}
+	files, err := generate(data, *pkg, filepath.Base(*specPath))
+	if err != nil {
+		log.Fatalf("openapigen: %v", err)
+	}
+
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if err := os.WriteFile(filepath.Join(*out, name), files[name], 0o644); err != nil {
+			log.Fatalf("openapigen: %v", err)
+		}
+		fmt.Println("openapigen: wrote", filepath.Join(*out, name))
+	}
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// document is the subset of an OpenAPI 3 document understood by the generator
+type document struct {
+	Paths      ordered[ordered[*operation]] `yaml:"paths"`
+	Components struct {
+		Schemas    ordered[*schema]    `yaml:"schemas"`
+		Parameters ordered[*parameter] `yaml:"parameters"`
+	} `yaml:"components"`
+}
+
+type operation struct {
+	OperationID string       `yaml:"operationId"`
+	Summary     string       `yaml:"summary"`
+	Parameters  []*parameter `yaml:"parameters"`
+	RequestBody *struct {
+		Required bool                  `yaml:"required"`
+		Content  map[string]*mediaType `yaml:"content"`
+	} `yaml:"requestBody"`
+	Responses ordered[*response] `yaml:"responses"`
+}
+
+type mediaType struct {
+	Schema *schema `yaml:"schema"`
+}
+
+type response struct {
+	Description string                `yaml:"description"`
+	Content     map[string]*mediaType `yaml:"content"`
+}
+
+type parameter struct {
+	Ref         string  `yaml:"$ref"`
+	Name        string  `yaml:"name"`
+	In          string  `yaml:"in"`
+	Required    bool    `yaml:"required"`
+	Description string  `yaml:"description"`
+	Schema      *schema `yaml:"schema"`
+}
+
+type schema struct {
+	Ref                  string           `yaml:"$ref"`
+	Type                 string           `yaml:"type"`
+	Format               string           `yaml:"format"`
+	Description          string           `yaml:"description"`
+	Required             []string         `yaml:"required"`
+	Properties           ordered[*schema] `yaml:"properties"`
+	Items                *schema          `yaml:"items"`
+	AdditionalProperties yaml.Node        `yaml:"additionalProperties"`
+}
+
+// ordered is a YAML mapping that remembers its key order
+type ordered[T any] struct {
+	Keys   []string
+	Values map[string]T
+}
+
+func (o *ordered[T]) UnmarshalYAML(n *yaml.Node) error {
+	if n.Kind != yaml.MappingNode {
+		return fmt.Errorf("line %d: expected a mapping", n.Line)
+	}
+	o.Values = make(map[string]T, len(n.Content)/2)
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		key := n.Content[i].Value
+		var v T
+		if err := n.Content[i+1].Decode(&v); err != nil {
+			return err
+		}
+		o.Keys = append(o.Keys, key)
+		o.Values[key] = v
+	}
+	return nil
+}
+
+func parseDocument(data []byte) (*document, error) {
+	var doc document
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	return &doc, nil
+}
+
+// refName returns the component name of a local reference such as "#/components/schemas/Sandbox"
+func refName(ref, kind string) (string, error) {
+	prefix := "#/components/" + kind + "/"
+	if !strings.HasPrefix(ref, prefix) {
+		return "", fmt.Errorf("unsupported reference %q", ref)
+	}
+	return strings.TrimPrefix(ref, prefix), nil
+}
+
+// resolveParameter follows a parameter $ref
+func (d *document) resolveParameter(p *parameter) (*parameter, error) {
+	if p.Ref == "" {
+		return p, nil
+	}
+	name, err := refName(p.Ref, "parameters")
+	if err != nil {
+		return nil, err
+	}
+	resolved, ok := d.Components.Parameters.Values[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown parameter %q", p.Ref)
+	}
+	return resolved, nil
+}
+
+// jsonSchema returns the application/json schema of a content map, if any
+func jsonSchema(content map[string]*mediaType) *schema {
+	if mt, ok := content["application/json"]; ok && mt != nil {
+		return mt.Schema
+	}
+	return nil
+}
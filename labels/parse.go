@@ -0,0 +1,183 @@
+package labels
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SyntaxError reports an invalid selector
+type SyntaxError struct {
+	Selector string
+	Pos      int // Byte offset of the problem
+	Msg      string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("invalid label selector %q at position %d: %s", e.Selector, e.Pos, e.Msg)
+}
+
+// Parse parses a selector such as "team=ml,env in (ci,dev),!ephemeral".
+// An empty or all-whitespace string yields the empty selector.
+func Parse(selector string) (Selector, error) {
+	p := &parser{input: selector}
+	var s Selector
+	p.skipSpace()
+	if p.pos == len(p.input) {
+		return s, nil
+	}
+	for {
+		r, err := p.requirement()
+		if err != nil {
+			return nil, err
+		}
+		s = append(s, r)
+
+		p.skipSpace()
+		if p.pos == len(p.input) {
+			return s, nil
+		}
+		if !p.consume(",") {
+			return nil, p.errorf("expected ',' between requirements")
+		}
+	}
+}
+
+// MustParse is like Parse but panics on error, for selectors known at compile time
+func MustParse(selector string) Selector {
+	s, err := Parse(selector)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+type parser struct {
+	input string
+	pos   int
+}
+
+func (p *parser) errorf(format string, args ...interface{}) error {
+	return &SyntaxError{Selector: p.input, Pos: p.pos, Msg: fmt.Sprintf(format, args...)}
+}
+
+func (p *parser) skipSpace() {
+	for p.pos < len(p.input) && (p.input[p.pos] == ' ' || p.input[p.pos] == '\t') {
+		p.pos++
+	}
+}
+
+func (p *parser) consume(token string) bool {
+	if strings.HasPrefix(p.input[p.pos:], token) {
+		p.pos += len(token)
+		return true
+	}
+	return false
+}
+
+// word reads a run of key or value characters
+func (p *parser) word() string {
+	start := p.pos
+	for p.pos < len(p.input) && isValueChar(p.input[p.pos]) {
+		p.pos++
+	}
+	return p.input[start:p.pos]
+}
+
+func (p *parser) key() (string, error) {
+	p.skipSpace()
+	start := p.pos
+	key := p.word()
+	if key == "" {
+		return "", p.errorf("expected a label key")
+	}
+	if err := ValidateKey(key); err != nil {
+		return "", &SyntaxError{Selector: p.input, Pos: start, Msg: err.Error()}
+	}
+	return key, nil
+}
+
+func (p *parser) value() (string, error) {
+	p.skipSpace()
+	start := p.pos
+	value := p.word()
+	if err := ValidateValue(value); err != nil {
+		return "", &SyntaxError{Selector: p.input, Pos: start, Msg: err.Error()}
+	}
+	return value, nil
+}
+
+func (p *parser) requirement() (Requirement, error) {
+	p.skipSpace()
+	if p.consume("!") {
+		key, err := p.key()
+		if err != nil {
+			return Requirement{}, err
+		}
+		return Requirement{Key: key, Operator: DoesNotExist}, nil
+	}
+
+	key, err := p.key()
+	if err != nil {
+		return Requirement{}, err
+	}
+
+	p.skipSpace()
+	switch {
+	case p.consume("!="):
+		return p.single(key, NotEquals)
+	case p.consume("=="), p.consume("="):
+		return p.single(key, Equals)
+	}
+
+	if p.pos == len(p.input) || p.input[p.pos] == ',' {
+		return Requirement{Key: key, Operator: Exists}, nil
+	}
+
+	opStart := p.pos
+	var op Operator
+	switch word := p.word(); word {
+	case "in":
+		op = In
+	case "notin":
+		op = NotIn
+	default:
+		p.pos = opStart
+		return Requirement{}, p.errorf("expected an operator (=, ==, !=, in, notin) after %q", key)
+	}
+	values, err := p.set()
+	if err != nil {
+		return Requirement{}, err
+	}
+	return Requirement{Key: key, Operator: op, Values: values}, nil
+}
+
+func (p *parser) single(key string, op Operator) (Requirement, error) {
+	value, err := p.value()
+	if err != nil {
+		return Requirement{}, err
+	}
+	return Requirement{Key: key, Operator: op, Values: []string{value}}, nil
+}
+
+// set parses "(a, b, c)"
+func (p *parser) set() ([]string, error) {
+	p.skipSpace()
+	if !p.consume("(") {
+		return nil, p.errorf("expected '(' to start a value set")
+	}
+	var values []string
+	for {
+		value, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+		p.skipSpace()
+		if p.consume(")") {
+			return values, nil
+		}
+		if !p.consume(",") {
+			return nil, p.errorf("expected ',' or ')' in value set")
+		}
+	}
+}
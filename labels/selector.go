@@ -0,0 +1,176 @@
+// Package labels parses and evaluates Kubernetes-style label selectors against
+// sandbox metadata, e.g. "team=ml,env in (ci,dev),!ephemeral".
+//
+// A selector is a comma-separated list of requirements that must all match:
+//
+//	key              the label is present
+//	!key             the label is absent
+//	key=value        the label equals value (== is accepted too)
+//	key!=value       the label is absent or differs from value
+//	key in (a,b)     the label equals one of the values
+//	key notin (a,b)  the label is absent or equals none of the values
+package labels
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Label syntax limits, matching the metadata limits enforced by models.Validate
+const (
+	MaxKeyLength   = 128
+	MaxValueLength = 1024
+)
+
+// Operator is the comparison of a Requirement
+type Operator string
+
+// Requirement operators
+const (
+	Exists       Operator = "exists"
+	DoesNotExist Operator = "!"
+	Equals       Operator = "="
+	NotEquals    Operator = "!="
+	In           Operator = "in"
+	NotIn        Operator = "notin"
+)
+
+// Requirement is a single condition on one label
+type Requirement struct {
+	Key      string
+	Operator Operator
+	Values   []string // One value for Equals and NotEquals, one or more for In and NotIn
+}
+
+// Matches reports whether the labels satisfy the requirement
+func (r Requirement) Matches(labels map[string]string) bool {
+	value, ok := labels[r.Key]
+	switch r.Operator {
+	case Exists:
+		return ok
+	case DoesNotExist:
+		return !ok
+	case Equals, In:
+		return ok && contains(r.Values, value)
+	case NotEquals, NotIn:
+		return !ok || !contains(r.Values, value)
+	}
+	return false
+}
+
+func (r Requirement) String() string {
+	switch r.Operator {
+	case Exists:
+		return r.Key
+	case DoesNotExist:
+		return "!" + r.Key
+	case Equals, NotEquals:
+		return r.Key + string(r.Operator) + strings.Join(r.Values, "")
+	default:
+		return r.Key + " " + string(r.Operator) + " (" + strings.Join(r.Values, ",") + ")"
+	}
+}
+
+// Selector is a conjunction of requirements. The empty selector matches everything.
+type Selector []Requirement
+
+// Everything returns the empty selector
+func Everything() Selector {
+	return nil
+}
+
+// SelectorFromSet returns a selector requiring every label in set to have the given value
+func SelectorFromSet(set map[string]string) Selector {
+	keys := make([]string, 0, len(set))
+	for k := range set {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	s := make(Selector, len(keys))
+	for i, k := range keys {
+		s[i] = Requirement{Key: k, Operator: Equals, Values: []string{set[k]}}
+	}
+	return s
+}
+
+// Empty reports whether the selector has no requirements
+func (s Selector) Empty() bool {
+	return len(s) == 0
+}
+
+// Matches reports whether the labels satisfy every requirement
+func (s Selector) Matches(labels map[string]string) bool {
+	for _, r := range s {
+		if !r.Matches(labels) {
+			return false
+		}
+	}
+	return true
+}
+
+// Add returns a copy of the selector with additional requirements
+func (s Selector) Add(reqs ...Requirement) Selector {
+	out := make(Selector, 0, len(s)+len(reqs))
+	return append(append(out, s...), reqs...)
+}
+
+// String returns the selector in the syntax accepted by Parse
+func (s Selector) String() string {
+	parts := make([]string, len(s))
+	for i, r := range s {
+		parts[i] = r.String()
+	}
+	return strings.Join(parts, ",")
+}
+
+// ValidateKey checks that key can be used as a label and in selectors.
+// Keys are at most MaxKeyLength bytes of letters, digits, '.', '_', '-' and '/',
+// and start and end with a letter or digit.
+func ValidateKey(key string) error {
+	switch {
+	case key == "":
+		return fmt.Errorf("label key must not be empty")
+	case len(key) > MaxKeyLength:
+		return fmt.Errorf("label key %q must be at most %d bytes", key, MaxKeyLength)
+	case !isAlnum(key[0]) || !isAlnum(key[len(key)-1]):
+		return fmt.Errorf("label key %q must start and end with a letter or digit", key)
+	}
+	for i := 0; i < len(key); i++ {
+		if c := key[i]; !isAlnum(c) && c != '.' && c != '_' && c != '-' && c != '/' {
+			return fmt.Errorf("label key %q contains invalid character %q", key, c)
+		}
+	}
+	return nil
+}
+
+// ValidateValue checks that value can be used as a label value and in selectors.
+// Values are at most MaxValueLength bytes of letters, digits, '.', '_', '-', '/' and ':'; they may be empty.
+func ValidateValue(value string) error {
+	if len(value) > MaxValueLength {
+		return fmt.Errorf("label value must be at most %d bytes", MaxValueLength)
+	}
+	for i := 0; i < len(value); i++ {
+		if !isValueChar(value[i]) {
+			return fmt.Errorf("label value %q contains invalid character %q", value, value[i])
+		}
+	}
+	return nil
+}
+
+func isAlnum(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
+
+func isValueChar(c byte) bool {
+	return isAlnum(c) || c == '.' || c == '_' || c == '-' || c == '/' || c == ':'
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,137 @@
+package labels
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		input string
+		want  Selector
+	}{
+		{"", nil},
+		{"  ", nil},
+		{"team=ml", Selector{{Key: "team", Operator: Equals, Values: []string{"ml"}}}},
+		{"team==ml", Selector{{Key: "team", Operator: Equals, Values: []string{"ml"}}}},
+		{"team != ml", Selector{{Key: "team", Operator: NotEquals, Values: []string{"ml"}}}},
+		{"pr=", Selector{{Key: "pr", Operator: Equals, Values: []string{""}}}},
+		{"scalebox.pool/state=idle", Selector{{Key: "scalebox.pool/state", Operator: Equals, Values: []string{"idle"}}}},
+		{
+			"team=ml, env in (ci, dev),!ephemeral,job,tier notin (gpu)",
+			Selector{
+				{Key: "team", Operator: Equals, Values: []string{"ml"}},
+				{Key: "env", Operator: In, Values: []string{"ci", "dev"}},
+				{Key: "ephemeral", Operator: DoesNotExist},
+				{Key: "job", Operator: Exists},
+				{Key: "tier", Operator: NotIn, Values: []string{"gpu"}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		got, err := Parse(tt.input)
+		if err != nil {
+			t.Errorf("Parse(%q) failed: %v", tt.input, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Parse(%q) = %#v, want %#v", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, input := range []string{
+		"team=ml,",
+		",team=ml",
+		"team ml",
+		"env in ci",
+		"env in (ci",
+		"env in (ci dev)",
+		"!",
+		"-team=ml",
+		"team=m l",
+		"team=(ml)",
+		"team=ml;env=ci",
+	} {
+		_, err := Parse(input)
+		if err == nil {
+			t.Errorf("Parse(%q) succeeded, expected an error", input)
+			continue
+		}
+		if _, ok := err.(*SyntaxError); !ok {
+			t.Errorf("Parse(%q) returned %T, expected *SyntaxError", input, err)
+		}
+	}
+}
+
+func TestSelectorMatches(t *testing.T) {
+	labels := map[string]string{"team": "ml", "env": "ci", "job": "train"}
+	tests := map[string]bool{
+		"":                                   true,
+		"team=ml":                            true,
+		"team=web":                           false,
+		"team!=web":                          true,
+		"owner!=bob":                         true,
+		"env in (ci,dev)":                    true,
+		"env in (prod)":                      false,
+		"env notin (prod)":                   true,
+		"owner notin (bob)":                  true,
+		"job":                                true,
+		"owner":                              false,
+		"!ephemeral":                         true,
+		"!job":                               false,
+		"team=ml,env in (ci,dev),!ephemeral": true,
+		"team=ml,env=prod":                   false,
+	}
+	for input, want := range tests {
+		if got := MustParse(input).Matches(labels); got != want {
+			t.Errorf("%q.Matches(%v) = %v, want %v", input, labels, got, want)
+		}
+	}
+}
+
+func TestSelectorStringRoundTrip(t *testing.T) {
+	for _, input := range []string{
+		"team=ml",
+		"team!=ml",
+		"env in (ci,dev)",
+		"env notin (prod)",
+		"job",
+		"!ephemeral",
+		"team=ml,env in (ci,dev),!ephemeral",
+	} {
+		s := MustParse(input)
+		if s.String() != input {
+			t.Errorf("String() = %q, want %q", s.String(), input)
+		}
+		again, err := Parse(s.String())
+		if err != nil || !reflect.DeepEqual(again, s) {
+			t.Errorf("Round trip of %q failed: %v %v", input, again, err)
+		}
+	}
+
+	s := SelectorFromSet(map[string]string{"team": "ml", "env": "ci"})
+	if s.String() != "env=ci,team=ml" {
+		t.Errorf("SelectorFromSet String() = %q", s.String())
+	}
+}
+
+func TestValidateKey(t *testing.T) {
+	for _, key := range []string{"team", "scalebox.pool.state", "example.com/owner", "a_b-c"} {
+		if err := ValidateKey(key); err != nil {
+			t.Errorf("ValidateKey(%q) failed: %v", key, err)
+		}
+	}
+	for _, key := range []string{"", "-team", "team.", "te am", "a,b", "a=b"} {
+		if err := ValidateKey(key); err == nil {
+			t.Errorf("ValidateKey(%q) succeeded, expected an error", key)
+		}
+	}
+	if err := ValidateValue("2026-01-02T03:04:05Z"); err != nil {
+		t.Errorf("Expected timestamps to be valid values: %v", err)
+	}
+	if err := ValidateValue("a,b"); err == nil {
+		t.Error("Expected comma to be rejected in values")
+	}
+}
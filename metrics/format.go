@@ -0,0 +1,38 @@
+package metrics
+
+import (
+	"fmt"
+	"strings"
+)
+
+var byteUnits = []string{"B", "KiB", "MiB", "GiB", "TiB", "PiB", "EiB"}
+
+// FormatBytes formats a byte count with binary units, e.g. "512 B", "1.5 MiB" or "2.0 GiB"
+func FormatBytes(n int64) string {
+	if n > -1024 && n < 1024 {
+		return fmt.Sprintf("%d B", n)
+	}
+	sign := ""
+	value := float64(n)
+	if value < 0 {
+		sign, value = "-", -value
+	}
+	unit := 0
+	for value >= 1024 && unit < len(byteUnits)-1 {
+		value /= 1024
+		unit++
+	}
+	return fmt.Sprintf("%s%.1f %s", sign, value, byteUnits[unit])
+}
+
+// FormatUsage formats used and total bytes with the utilisation, e.g. "1.5 GiB / 4.0 GiB (37.5%)"
+func FormatUsage(used, total int64) string {
+	var b strings.Builder
+	b.WriteString(FormatBytes(used))
+	b.WriteString(" / ")
+	b.WriteString(FormatBytes(total))
+	if pct, ok := percent(used, total); ok {
+		fmt.Fprintf(&b, " (%.1f%%)", pct)
+	}
+	return b.String()
+}
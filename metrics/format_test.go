@@ -0,0 +1,34 @@
+package metrics
+
+import (
+	"math"
+	"testing"
+)
+
+func TestFormatBytes(t *testing.T) {
+	tests := []struct {
+		n    int64
+		want string
+	}{
+		{0, "0 B"},
+		{512, "512 B"},
+		{1536, "1.5 KiB"},
+		{512 * 1024 * 1024, "512.0 MiB"},
+		{2 << 30, "2.0 GiB"},
+		{-3 << 20, "-3.0 MiB"},
+		{math.MaxInt64, "8.0 EiB"},
+		{math.MinInt64, "-8.0 EiB"},
+	}
+	for _, tt := range tests {
+		if got := FormatBytes(tt.n); got != tt.want {
+			t.Errorf("FormatBytes(%d) = %q, want %q", tt.n, got, tt.want)
+		}
+	}
+
+	if got := FormatUsage(1536<<20, 4<<30); got != "1.5 GiB / 4.0 GiB (37.5%)" {
+		t.Errorf("Unexpected usage %q", got)
+	}
+	if got := FormatUsage(100, 0); got != "100 B / 0 B" {
+		t.Errorf("Expected no percentage without a total, got %q", got)
+	}
+}
@@ -0,0 +1,197 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/scalebox/scalebox-sdk-golang/models"
+)
+
+// AlertState is the state an alert event reports
+type AlertState string
+
+// Alert states
+const (
+	AlertFiring   AlertState = "firing"
+	AlertResolved AlertState = "resolved"
+)
+
+// Alert is emitted when a rule starts firing for a sandbox and when it resolves
+type Alert struct {
+	SandboxID string     `json:"sandbox_id"`
+	Rule      string     `json:"rule"`
+	State     AlertState `json:"state"`
+	Value     float64    `json:"value"` // Metric value of the point that changed the state
+	Since     time.Time  `json:"since"` // When the condition, or for resolved alerts the clearing, began
+	At        time.Time  `json:"at"`    // Timestamp of the point that changed the state
+}
+
+func (a Alert) String() string {
+	return fmt.Sprintf("%s %s %s: value %.4g since %s", a.SandboxID, a.Rule, a.State, a.Value, a.Since.Format(time.RFC3339))
+}
+
+// MonitorOptions configures a Monitor
+type MonitorOptions struct {
+	// Rules to evaluate, defaults to DefaultRules
+	Rules []Rule
+	// OnAlert, if set, is called with every alert event, one at a time
+	OnAlert func(Alert)
+}
+
+// ruleState tracks one rule for one sandbox
+type ruleState struct {
+	firing      bool
+	firingSince time.Time
+	pending     bool      // The firing condition (or, while firing, the clear condition) holds
+	since       time.Time // When pending started
+}
+
+// Monitor evaluates alert rules over the data points of one or more sandboxes.
+// Rules are evaluated on point timestamps, so polled, streamed and replayed points behave alike.
+// Points older than the last one seen for a sandbox are ignored.
+type Monitor struct {
+	rules   []Rule
+	onAlert func(Alert)
+
+	alertMu sync.Mutex // Serializes observations so OnAlert sees events in order
+	mu      sync.Mutex
+	states  map[string][]ruleState
+	last    map[string]time.Time
+	values  map[string][]float64 // Last value per rule, for Firing
+}
+
+// NewMonitor validates the rules and creates a Monitor
+func NewMonitor(opts MonitorOptions) (*Monitor, error) {
+	rules := opts.Rules
+	if rules == nil {
+		rules = DefaultRules()
+	}
+	var errs []error
+	for i, r := range rules {
+		if err := r.Validate(); err != nil {
+			errs = append(errs, fmt.Errorf("rules[%d] %s: %w", i, r.name(), err))
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return &Monitor{
+		rules:   rules,
+		onAlert: opts.OnAlert,
+		states:  make(map[string][]ruleState),
+		last:    make(map[string]time.Time),
+		values:  make(map[string][]float64),
+	}, nil
+}
+
+// Observe evaluates the rules against a new point and returns the alert events it causes,
+// after passing them to OnAlert
+func (m *Monitor) Observe(sandboxID string, p models.MetricsDataPoint) []Alert {
+	m.alertMu.Lock()
+	defer m.alertMu.Unlock()
+	m.mu.Lock()
+	alerts := m.observe(sandboxID, p)
+	m.mu.Unlock()
+	if m.onAlert != nil {
+		for _, a := range alerts {
+			m.onAlert(a)
+		}
+	}
+	return alerts
+}
+
+func (m *Monitor) observe(sandboxID string, p models.MetricsDataPoint) []Alert {
+	if last, ok := m.last[sandboxID]; ok && !p.Timestamp.After(last) {
+		return nil
+	}
+	m.last[sandboxID] = p.Timestamp
+	states := m.states[sandboxID]
+	if states == nil {
+		states = make([]ruleState, len(m.rules))
+		m.states[sandboxID] = states
+		m.values[sandboxID] = make([]float64, len(m.rules))
+	}
+
+	var alerts []Alert
+	for i, r := range m.rules {
+		v, ok := r.Metric.Value(p)
+		if !ok {
+			continue
+		}
+		m.values[sandboxID][i] = v
+		st := &states[i]
+		// While firing, pending tracks the clear condition instead of the firing one
+		cond, wait := r.Compare.holds(v, r.Threshold), r.For
+		if st.firing {
+			cond, wait = !r.Compare.holds(v, r.clear()), r.clearFor()
+		}
+		if !cond {
+			st.pending = false
+			continue
+		}
+		if !st.pending {
+			st.pending, st.since = true, p.Timestamp
+		}
+		if p.Timestamp.Sub(st.since) < wait {
+			continue
+		}
+		st.firing, st.pending = !st.firing, false
+		state := AlertResolved
+		if st.firing {
+			state = AlertFiring
+			st.firingSince = st.since
+		}
+		alerts = append(alerts, Alert{SandboxID: sandboxID, Rule: r.name(), State: state, Value: v, Since: st.since, At: p.Timestamp})
+	}
+	return alerts
+}
+
+// Run observes the points of a sandbox until the channel closes or ctx ends,
+// e.g. the points of a sandboxes.MetricsStream or a MetricsFanout subscription
+func (m *Monitor) Run(ctx context.Context, sandboxID string, points <-chan models.MetricsDataPoint) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case p, ok := <-points:
+			if !ok {
+				return nil
+			}
+			m.Observe(sandboxID, p)
+		}
+	}
+}
+
+// Firing returns the alerts currently firing, ordered by sandbox and rule
+func (m *Monitor) Firing() []Alert {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	ids := make([]string, 0, len(m.states))
+	for id := range m.states {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	var out []Alert
+	for _, id := range ids {
+		for i, st := range m.states[id] {
+			if st.firing {
+				out = append(out, Alert{SandboxID: id, Rule: m.rules[i].name(), State: AlertFiring, Value: m.values[id][i], Since: st.firingSince, At: m.last[id]})
+			}
+		}
+	}
+	return out
+}
+
+// Forget drops the state of a sandbox, e.g. once it has been deleted
+func (m *Monitor) Forget(sandboxID string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.states, sandboxID)
+	delete(m.last, sandboxID)
+	delete(m.values, sandboxID)
+}
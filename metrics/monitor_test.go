@@ -0,0 +1,107 @@
+package metrics
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/scalebox/scalebox-sdk-golang/models"
+)
+
+// memPoint is a data point at epoch+sec with memory at pct percent
+func memPoint(sec int, pct int64) models.MetricsDataPoint {
+	return models.MetricsDataPoint{Timestamp: epoch.Add(time.Duration(sec) * time.Second), CPUUsedPct: 50, MemUsed: pct, MemTotal: 100}
+}
+
+func TestMonitorHysteresis(t *testing.T) {
+	rule, _ := ParseRule("mem_pct > 90 for 2m clear 80 clear_for 30s")
+	rule.Name = "memory_pressure"
+	var events []Alert
+	m, err := NewMonitor(MonitorOptions{Rules: []Rule{rule}, OnAlert: func(a Alert) { events = append(events, a) }})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	steps := []struct {
+		sec  int
+		pct  int64
+		want AlertState
+	}{
+		{0, 95, ""},
+		{60, 96, ""},
+		{90, 85, ""},  // Dips below the threshold before 2m, so the condition restarts
+		{100, 95, ""}, // Pending again since 100s
+		{219, 97, ""},
+		{220, 97, AlertFiring}, // 2m after 100s
+		{230, 85, ""},          // Below the threshold but above clear: still firing
+		{240, 79, ""},          // Clear, pending for 30s
+		{250, 91, ""},          // Back up, clearing restarts
+		{260, 70, ""},
+		{289, 70, ""},
+		{290, 70, AlertResolved},
+		{300, 70, ""},
+	}
+	for _, step := range steps {
+		got := m.Observe("sbx-1", memPoint(step.sec, step.pct))
+		switch {
+		case step.want == "" && len(got) != 0:
+			t.Errorf("At %ds: unexpected alerts %v", step.sec, got)
+		case step.want != "" && (len(got) != 1 || got[0].State != step.want):
+			t.Errorf("At %ds: expected %s, got %v", step.sec, step.want, got)
+		}
+	}
+	if len(events) != 2 || events[0].Rule != "memory_pressure" || !events[0].Since.Equal(epoch.Add(100*time.Second)) || events[1].Value != 70 {
+		t.Errorf("Unexpected events %v", events)
+	}
+	if len(m.Firing()) != 0 {
+		t.Errorf("Expected nothing firing, got %v", m.Firing())
+	}
+}
+
+func TestMonitorSandboxes(t *testing.T) {
+	m, err := NewMonitor(MonitorOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Disk pressure fires at once; sandboxes are tracked independently
+	full := models.MetricsDataPoint{Timestamp: epoch, CPUUsedPct: 50, DiskUsed: 99, DiskTotal: 100}
+	if got := m.Observe("sbx-2", full); len(got) != 1 || got[0].Rule != "disk_pressure" || got[0].State != AlertFiring {
+		t.Errorf("Expected disk pressure, got %v", got)
+	}
+	if got := m.Observe("sbx-1", memPoint(0, 10)); len(got) != 0 {
+		t.Errorf("Unexpected alerts %v", got)
+	}
+	// Old points and points without totals are ignored
+	if got := m.Observe("sbx-2", models.MetricsDataPoint{Timestamp: epoch.Add(-time.Minute)}); got != nil {
+		t.Errorf("Expected an old point to be ignored, got %v", got)
+	}
+
+	points := make(chan models.MetricsDataPoint)
+	done := make(chan error)
+	go func() { done <- m.Run(context.Background(), "sbx-1", points) }()
+	for sec := 60; sec <= 16*60; sec += 60 {
+		p := memPoint(sec, 10)
+		p.CPUUsedPct = 0.5
+		points <- p
+	}
+	close(points)
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+
+	firing := m.Firing()
+	if len(firing) != 2 || firing[0].SandboxID != "sbx-1" || firing[0].Rule != "cpu_idle" || firing[1].Rule != "disk_pressure" {
+		t.Errorf("Unexpected firing alerts %v", firing)
+	}
+	if !firing[0].Since.Equal(epoch.Add(time.Minute)) {
+		t.Errorf("Expected idle since the first idle point, got %s", firing[0].Since)
+	}
+	m.Forget("sbx-1")
+	if len(m.Firing()) != 1 {
+		t.Errorf("Expected forgotten sandboxes to be dropped, got %v", m.Firing())
+	}
+
+	if _, err := NewMonitor(MonitorOptions{Rules: []Rule{{Metric: CPUPercent, Compare: "=="}}}); err == nil {
+		t.Error("Expected invalid rules to be rejected")
+	}
+}
@@ -0,0 +1,182 @@
+package metrics
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/scalebox/scalebox-sdk-golang/models"
+)
+
+// Comparison is how a rule compares a metric with its threshold
+type Comparison string
+
+// Comparisons
+const (
+	Above   Comparison = ">"
+	AtLeast Comparison = ">="
+	Below   Comparison = "<"
+	AtMost  Comparison = "<="
+)
+
+func (c Comparison) holds(value, threshold float64) bool {
+	switch c {
+	case Above:
+		return value > threshold
+	case AtLeast:
+		return value >= threshold
+	case Below:
+		return value < threshold
+	case AtMost:
+		return value <= threshold
+	}
+	return false
+}
+
+func (c Comparison) valid() bool {
+	return c == Above || c == AtLeast || c == Below || c == AtMost
+}
+
+func (c Comparison) upward() bool {
+	return c == Above || c == AtLeast
+}
+
+// Rule raises an alert when a metric crosses a threshold for long enough.
+//
+// To avoid flapping, a firing alert resolves only once the comparison no longer holds
+// against Clear for ClearFor; e.g. "mem_pct > 90 for 2m clear 80" fires after two minutes
+// above 90% and resolves after two minutes at or below 80%.
+type Rule struct {
+	Name      string // Defaults to the rule's text, see String
+	Metric    Metric
+	Compare   Comparison
+	Threshold float64        // In the metric's unit, e.g. percent for MemoryPercent
+	For       time.Duration  // How long the comparison must hold before the alert fires
+	Clear     *float64       // Threshold for resolving, defaults to Threshold
+	ClearFor  *time.Duration // How long the alert must be clear before it resolves, defaults to For
+}
+
+// String returns the rule in the syntax accepted by ParseRule
+func (r Rule) String() string {
+	s := fmt.Sprintf("%s %s %s", r.Metric, r.Compare, strconv.FormatFloat(r.Threshold, 'f', -1, 64))
+	if r.For > 0 {
+		s += " for " + r.For.String()
+	}
+	if r.Clear != nil {
+		s += " clear " + strconv.FormatFloat(*r.Clear, 'f', -1, 64)
+	}
+	if r.ClearFor != nil {
+		s += " clear_for " + r.ClearFor.String()
+	}
+	return s
+}
+
+func (r Rule) name() string {
+	if r.Name != "" {
+		return r.Name
+	}
+	return r.String()
+}
+
+func (r Rule) clear() float64 {
+	if r.Clear != nil {
+		return *r.Clear
+	}
+	return r.Threshold
+}
+
+func (r Rule) clearFor() time.Duration {
+	if r.ClearFor != nil {
+		return *r.ClearFor
+	}
+	return r.For
+}
+
+// Validate checks that the rule can be evaluated
+func (r Rule) Validate() error {
+	var errs []models.FieldError
+	if r.Metric.String() == "unknown" {
+		errs = append(errs, models.FieldError{Field: "metric", Message: fmt.Sprintf("unknown metric %d", r.Metric)})
+	}
+	if !r.Compare.valid() {
+		errs = append(errs, models.FieldError{Field: "compare", Message: fmt.Sprintf("must be >, >=, < or <=, got %q", r.Compare)})
+	}
+	if r.For < 0 || r.ClearFor != nil && *r.ClearFor < 0 {
+		errs = append(errs, models.FieldError{Field: "for", Message: "durations must not be negative"})
+	}
+	if r.Clear != nil && (r.Compare.upward() && *r.Clear > r.Threshold || !r.Compare.upward() && *r.Clear < r.Threshold) {
+		errs = append(errs, models.FieldError{Field: "clear", Message: "must be on the safe side of the threshold"})
+	}
+	if len(errs) > 0 {
+		return &models.ValidationError{Errors: errs}
+	}
+	return nil
+}
+
+// ParseRule parses a rule written as "<metric> <comparison> <threshold> [for <duration>]
+// [clear <threshold>] [clear_for <duration>]", e.g. "mem_pct > 90 for 2m clear 80".
+// Metrics are named as by Metric.String.
+func ParseRule(s string) (Rule, error) {
+	fields := strings.Fields(s)
+	if len(fields) < 3 || len(fields)%2 == 0 {
+		return Rule{}, fmt.Errorf("rule %q: want \"<metric> <comparison> <threshold> [for <duration>] [clear <threshold>] [clear_for <duration>]\"", s)
+	}
+	metric, err := ParseMetric(fields[0])
+	if err != nil {
+		return Rule{}, fmt.Errorf("rule %q: %w", s, err)
+	}
+	r := Rule{Metric: metric, Compare: Comparison(fields[1])}
+	if r.Threshold, err = strconv.ParseFloat(fields[2], 64); err != nil {
+		return Rule{}, fmt.Errorf("rule %q: invalid threshold %q", s, fields[2])
+	}
+	for i := 3; i < len(fields); i += 2 {
+		key, value := fields[i], fields[i+1]
+		switch key {
+		case "for", "clear_for":
+			d, err := time.ParseDuration(value)
+			if err != nil {
+				return Rule{}, fmt.Errorf("rule %q: invalid %s duration %q", s, key, value)
+			}
+			if key == "for" {
+				r.For = d
+			} else {
+				r.ClearFor = &d
+			}
+		case "clear":
+			v, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return Rule{}, fmt.Errorf("rule %q: invalid clear threshold %q", s, value)
+			}
+			r.Clear = &v
+		default:
+			return Rule{}, fmt.Errorf("rule %q: unknown clause %q", s, key)
+		}
+	}
+	if err := r.Validate(); err != nil {
+		return Rule{}, fmt.Errorf("rule %q: %w", s, err)
+	}
+	return r, nil
+}
+
+// DefaultRules warns about memory and disk pressure before the sandbox runs out,
+// CPU pegged at 100% and CPU left idle
+func DefaultRules() []Rule {
+	rules := []string{
+		"mem_pct > 90 for 2m clear 80",
+		"disk_pct > 95 clear 90 clear_for 1m",
+		"cpu_pct >= 99 for 5m clear 90",
+		"cpu_pct < 1 for 15m clear 5 clear_for 1m",
+	}
+	names := []string{"memory_pressure", "disk_pressure", "cpu_pegged", "cpu_idle"}
+	out := make([]Rule, len(rules))
+	for i, s := range rules {
+		r, err := ParseRule(s)
+		if err != nil {
+			panic(err)
+		}
+		r.Name = names[i]
+		out[i] = r
+	}
+	return out
+}
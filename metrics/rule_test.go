@@ -0,0 +1,53 @@
+package metrics
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseRule(t *testing.T) {
+	r, err := ParseRule("mem_pct > 90 for 2m clear 80 clear_for 30s")
+	if err != nil {
+		t.Fatalf("ParseRule failed: %v", err)
+	}
+	if r.Metric != MemoryPercent || r.Compare != Above || r.Threshold != 90 || r.For != 2*time.Minute || *r.Clear != 80 || *r.ClearFor != 30*time.Second {
+		t.Errorf("Unexpected rule %+v", r)
+	}
+	if r.String() != "mem_pct > 90 for 2m0s clear 80 clear_for 30s" {
+		t.Errorf("Unexpected text %q", r.String())
+	}
+	if again, err := ParseRule(r.String()); err != nil || again.String() != r.String() {
+		t.Errorf("Expected String to round trip, got %v (%v)", again, err)
+	}
+	if r.clearFor() != 30*time.Second {
+		t.Error("Expected the explicit clear duration")
+	}
+	if r, _ := ParseRule("disk_pct >= 95"); r.clear() != 95 || r.clearFor() != 0 {
+		t.Errorf("Expected clearing to default to the threshold, got %v", r)
+	}
+
+	invalid := map[string]string{
+		"mem_pct > 90 for":          "want",
+		"load > 1":                  "unknown metric",
+		"cpu_pct == 1":              "compare",
+		"cpu_pct > high":            "invalid threshold",
+		"cpu_pct > 90 for soon":     "invalid for duration",
+		"cpu_pct > 90 until 2m":     "unknown clause",
+		"cpu_pct > 90 clear 95":     "safe side",
+		"cpu_pct < 5 clear 1":       "safe side",
+		"cpu_pct < 5 clear_for -1s": "negative",
+	}
+	for text, want := range invalid {
+		if _, err := ParseRule(text); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("ParseRule(%q) = %v, want an error containing %q", text, err, want)
+		}
+	}
+}
+
+func TestDefaultRules(t *testing.T) {
+	rules := DefaultRules()
+	if len(rules) != 4 || rules[0].name() != "memory_pressure" || rules[3].Metric != CPUPercent || rules[3].Compare != Below {
+		t.Errorf("Unexpected default rules %v", rules)
+	}
+}
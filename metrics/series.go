@@ -0,0 +1,137 @@
+// Package metrics analyses sandbox metrics returned by GetMetrics.
+//
+// A Series is a time-ordered list of data points, built from one or more responses.
+// It can be summarised (min, max, mean and percentiles per Metric), differentiated into
+// rates of change, resampled to a fixed step and searched for gaps in reporting.
+package metrics
+
+import (
+	"sort"
+	"time"
+
+	"github.com/scalebox/scalebox-sdk-golang/models"
+)
+
+// Series is a list of data points ordered by timestamp, with at most one point per timestamp
+type Series []models.MetricsDataPoint
+
+// FromResponse builds a series from the data points of one or more GetMetrics responses.
+// Overlapping responses are merged; see Merge.
+func FromResponse(responses ...*models.SandboxMetricsResponse) Series {
+	var points []models.MetricsDataPoint
+	for _, resp := range responses {
+		if resp != nil {
+			points = append(points, resp.Metrics...)
+		}
+	}
+	return Merge(points)
+}
+
+// Merge combines data points in any order into a series. When several points share a
+// timestamp, the one given last wins, so later responses override earlier ones.
+func Merge(points ...[]models.MetricsDataPoint) Series {
+	return Series(models.MergeMetrics(points...))
+}
+
+// Start returns the timestamp of the first point, or the zero time for an empty series
+func (s Series) Start() time.Time {
+	if len(s) == 0 {
+		return time.Time{}
+	}
+	return s[0].Timestamp
+}
+
+// End returns the timestamp of the last point, or the zero time for an empty series
+func (s Series) End() time.Time {
+	if len(s) == 0 {
+		return time.Time{}
+	}
+	return s[len(s)-1].Timestamp
+}
+
+// Between returns the points with start <= timestamp < end
+func (s Series) Between(start, end time.Time) Series {
+	i := sort.Search(len(s), func(i int) bool { return !s[i].Timestamp.Before(start) })
+	j := sort.Search(len(s), func(i int) bool { return !s[i].Timestamp.Before(end) })
+	return s[i:j]
+}
+
+// Resample groups the points into buckets of step, aligned as by time.Time.Truncate,
+// and returns one point per non-empty bucket stamped with the bucket start.
+// CPU usage and used bytes are averaged; counts and totals take the bucket's last value.
+// Empty buckets are left out rather than filled, so they still show up in Gaps.
+func (s Series) Resample(step time.Duration) Series {
+	if step <= 0 || len(s) == 0 {
+		return s
+	}
+	var out Series
+	var cpu float64
+	var mem, disk int64
+	n := 0
+	flush := func() {
+		last := &out[len(out)-1]
+		last.CPUUsedPct = cpu / float64(n)
+		last.MemUsed = mem / int64(n)
+		last.DiskUsed = disk / int64(n)
+	}
+	for _, p := range s {
+		bucket := p.Timestamp.Truncate(step)
+		if len(out) == 0 || !out[len(out)-1].Timestamp.Equal(bucket) {
+			if len(out) > 0 {
+				flush()
+			}
+			out = append(out, models.MetricsDataPoint{Timestamp: bucket})
+			cpu, mem, disk, n = 0, 0, 0, 0
+		}
+		last := &out[len(out)-1]
+		last.CPUCount, last.MemTotal, last.DiskTotal = p.CPUCount, p.MemTotal, p.DiskTotal
+		cpu += p.CPUUsedPct
+		mem += p.MemUsed
+		disk += p.DiskUsed
+		n++
+	}
+	flush()
+	return out
+}
+
+// Downsample resamples the series to at most maxPoints points, widening the
+// step in whole seconds until it fits. Series that already fit are returned unchanged.
+func (s Series) Downsample(maxPoints int) Series {
+	if maxPoints <= 0 || len(s) <= maxPoints {
+		return s
+	}
+	step := (s.End().Sub(s.Start()) / time.Duration(maxPoints)).Truncate(time.Second)
+	if step < time.Second {
+		step = time.Second
+	}
+	out := s.Resample(step)
+	// Bucket alignment can add a bucket at either edge, so widen the step until it fits
+	for len(out) > maxPoints {
+		step += (step / 8).Truncate(time.Second) + time.Second
+		out = s.Resample(step)
+	}
+	return out
+}
+
+// Gap is a period with no data points, e.g. while the sandbox was paused
+type Gap struct {
+	Start time.Time // Timestamp of the last point before the gap
+	End   time.Time // Timestamp of the first point after the gap
+}
+
+// Duration returns the length of the gap
+func (g Gap) Duration() time.Duration {
+	return g.End.Sub(g.Start)
+}
+
+// Gaps returns the periods where consecutive points are more than maxInterval apart.
+// Pass about twice the expected step to tolerate jitter.
+func (s Series) Gaps(maxInterval time.Duration) []Gap {
+	var gaps []Gap
+	for i := 1; i < len(s); i++ {
+		if s[i].Timestamp.Sub(s[i-1].Timestamp) > maxInterval {
+			gaps = append(gaps, Gap{Start: s[i-1].Timestamp, End: s[i].Timestamp})
+		}
+	}
+	return gaps
+}
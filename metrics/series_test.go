@@ -0,0 +1,96 @@
+package metrics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/scalebox/scalebox-sdk-golang/models"
+)
+
+var epoch = time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// synthetic returns n points every step from epoch, with CPU usage i and memory use i MiB of 100 MiB
+func synthetic(n int, step time.Duration) Series {
+	s := make(Series, n)
+	for i := range s {
+		s[i] = models.MetricsDataPoint{
+			Timestamp:  epoch.Add(time.Duration(i) * step),
+			CPUCount:   2,
+			CPUUsedPct: float64(i),
+			MemUsed:    int64(i) << 20,
+			MemTotal:   100 << 20,
+			DiskUsed:   1 << 30,
+			DiskTotal:  4 << 30,
+		}
+	}
+	return s
+}
+
+func TestMerge(t *testing.T) {
+	all := synthetic(6, 10*time.Second)
+	first := &models.SandboxMetricsResponse{Metrics: []models.MetricsDataPoint{all[3], all[0], all[1], all[2]}}
+	updated := all[3]
+	updated.CPUUsedPct = 99
+	second := &models.SandboxMetricsResponse{Metrics: []models.MetricsDataPoint{updated, all[4], all[5]}}
+
+	s := FromResponse(first, nil, second)
+	if len(s) != 6 {
+		t.Fatalf("Expected 6 merged points, got %d", len(s))
+	}
+	for i := range s {
+		if !s[i].Timestamp.Equal(all[i].Timestamp) {
+			t.Errorf("Point %d out of order: %s", i, s[i].Timestamp)
+		}
+	}
+	if s[3].CPUUsedPct != 99 {
+		t.Errorf("Expected the later response to win, got %v", s[3].CPUUsedPct)
+	}
+	if !s.Start().Equal(epoch) || !s.End().Equal(epoch.Add(50*time.Second)) {
+		t.Errorf("Unexpected range %s - %s", s.Start(), s.End())
+	}
+	if got := s.Between(epoch.Add(10*time.Second), epoch.Add(30*time.Second)); len(got) != 2 || got[0].CPUUsedPct != 1 {
+		t.Errorf("Unexpected window %v", got)
+	}
+	if len(Merge()) != 0 || !Series(nil).Start().IsZero() {
+		t.Error("Expected an empty series")
+	}
+}
+
+func TestResample(t *testing.T) {
+	s := synthetic(12, 10*time.Second)
+	out := s.Resample(time.Minute)
+	if len(out) != 2 {
+		t.Fatalf("Expected 2 buckets, got %d", len(out))
+	}
+	// First minute averages points 0-5, second points 6-11
+	if out[0].CPUUsedPct != 2.5 || out[1].CPUUsedPct != 8.5 || !out[1].Timestamp.Equal(epoch.Add(time.Minute)) {
+		t.Errorf("Unexpected buckets %+v", out)
+	}
+	if out[1].MemUsed != (17<<20)/2 || out[1].MemTotal != 100<<20 || out[1].CPUCount != 2 {
+		t.Errorf("Unexpected memory %+v", out[1])
+	}
+
+	if got := s.Downsample(5); len(got) > 5 || len(got) < 3 {
+		t.Errorf("Expected at most 5 points, got %d", len(got))
+	}
+	if got := s.Downsample(100); len(got) != 12 {
+		t.Errorf("Expected a short series unchanged, got %d", len(got))
+	}
+	if got := synthetic(10000, time.Second).Downsample(1); len(got) != 1 {
+		t.Errorf("Expected a single point, got %d", len(got))
+	}
+}
+
+func TestGaps(t *testing.T) {
+	s := synthetic(3, 10*time.Second)
+	late := synthetic(6, 10*time.Second)[5]
+	s = Merge(s, Series{late})
+
+	gaps := s.Gaps(20 * time.Second)
+	if len(gaps) != 1 || !gaps[0].Start.Equal(epoch.Add(20*time.Second)) || gaps[0].Duration() != 30*time.Second {
+		t.Errorf("Unexpected gaps %+v", gaps)
+	}
+	if gaps := s.Resample(10 * time.Second).Gaps(20 * time.Second); len(gaps) != 1 {
+		t.Errorf("Expected resampling to keep the gap, got %+v", gaps)
+	}
+}
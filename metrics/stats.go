@@ -0,0 +1,183 @@
+package metrics
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/scalebox/scalebox-sdk-golang/models"
+)
+
+// Metric selects a value derived from each data point
+type Metric int
+
+// Metrics that can be summarised
+const (
+	CPUPercent    Metric = iota // CPU usage, 0-100
+	MemoryPercent               // MemUsed as a percentage of MemTotal
+	DiskPercent                 // DiskUsed as a percentage of DiskTotal
+	MemoryUsed                  // MemUsed in bytes
+	DiskUsed                    // DiskUsed in bytes
+)
+
+func (m Metric) String() string {
+	switch m {
+	case CPUPercent:
+		return "cpu_pct"
+	case MemoryPercent:
+		return "mem_pct"
+	case DiskPercent:
+		return "disk_pct"
+	case MemoryUsed:
+		return "mem_used"
+	case DiskUsed:
+		return "disk_used"
+	}
+	return "unknown"
+}
+
+// ParseMetric returns the metric named as by String, e.g. "mem_pct"
+func ParseMetric(name string) (Metric, error) {
+	for m := CPUPercent; m <= DiskUsed; m++ {
+		if m.String() == name {
+			return m, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown metric %q, want cpu_pct, mem_pct, disk_pct, mem_used or disk_used", name)
+}
+
+// Value returns the metric for p. Percentages of a zero total are not defined and report false.
+func (m Metric) Value(p models.MetricsDataPoint) (float64, bool) {
+	switch m {
+	case CPUPercent:
+		return p.CPUUsedPct, true
+	case MemoryPercent:
+		return percent(p.MemUsed, p.MemTotal)
+	case DiskPercent:
+		return percent(p.DiskUsed, p.DiskTotal)
+	case MemoryUsed:
+		return float64(p.MemUsed), true
+	case DiskUsed:
+		return float64(p.DiskUsed), true
+	}
+	return 0, false
+}
+
+func percent(used, total int64) (float64, bool) {
+	if total <= 0 {
+		return 0, false
+	}
+	return float64(used) / float64(total) * 100, true
+}
+
+// Point is one value of a metric
+type Point struct {
+	Timestamp time.Time
+	Value     float64
+}
+
+// Values returns the metric at each point where it is defined
+func (s Series) Values(m Metric) []Point {
+	out := make([]Point, 0, len(s))
+	for _, p := range s {
+		if v, ok := m.Value(p); ok {
+			out = append(out, Point{Timestamp: p.Timestamp, Value: v})
+		}
+	}
+	return out
+}
+
+// Rate returns the rate of change of the metric per second between consecutive points,
+// stamped with the later point's timestamp, e.g. bytes per second for MemoryUsed
+func (s Series) Rate(m Metric) []Point {
+	values := s.Values(m)
+	if len(values) < 2 {
+		return nil
+	}
+	out := make([]Point, 0, len(values)-1)
+	for i := 1; i < len(values); i++ {
+		elapsed := values[i].Timestamp.Sub(values[i-1].Timestamp).Seconds()
+		out = append(out, Point{Timestamp: values[i].Timestamp, Value: (values[i].Value - values[i-1].Value) / elapsed})
+	}
+	return out
+}
+
+// Summary describes the distribution of a metric over a series
+type Summary struct {
+	Count int     `json:"count"` // Points where the metric is defined
+	Min   float64 `json:"min"`
+	Max   float64 `json:"max"`
+	Mean  float64 `json:"mean"`
+	P50   float64 `json:"p50"`
+	P95   float64 `json:"p95"`
+	P99   float64 `json:"p99"`
+}
+
+// Summarize returns the summary of a metric; it is zero when the metric is never defined
+func (s Series) Summarize(m Metric) Summary {
+	values := make([]float64, 0, len(s))
+	for _, p := range s {
+		if v, ok := m.Value(p); ok {
+			values = append(values, v)
+		}
+	}
+	if len(values) == 0 {
+		return Summary{}
+	}
+	sort.Float64s(values)
+
+	sum := 0.0
+	for _, v := range values {
+		sum += v
+	}
+	return Summary{
+		Count: len(values),
+		Min:   values[0],
+		Max:   values[len(values)-1],
+		Mean:  sum / float64(len(values)),
+		P50:   Percentile(values, 50),
+		P95:   Percentile(values, 95),
+		P99:   Percentile(values, 99),
+	}
+}
+
+// Report summarises CPU, memory and disk utilisation over a series
+type Report struct {
+	Start  time.Time `json:"start"`
+	End    time.Time `json:"end"`
+	Points int       `json:"points"`
+	CPU    Summary   `json:"cpu_pct"`
+	Memory Summary   `json:"mem_pct"`
+	Disk   Summary   `json:"disk_pct"`
+}
+
+// Report summarises the utilisation metrics of the series
+func (s Series) Report() Report {
+	return Report{
+		Start:  s.Start(),
+		End:    s.End(),
+		Points: len(s),
+		CPU:    s.Summarize(CPUPercent),
+		Memory: s.Summarize(MemoryPercent),
+		Disk:   s.Summarize(DiskPercent),
+	}
+}
+
+// Percentile returns the p-th percentile (0-100) of sorted values, interpolating linearly
+// between the closest ranks. It returns NaN for no values.
+func Percentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 0 {
+		return math.NaN()
+	}
+	rank := p / 100 * float64(len(sorted)-1)
+	lower := int(math.Floor(rank))
+	if lower < 0 {
+		return sorted[0]
+	}
+	if lower >= len(sorted)-1 {
+		return sorted[len(sorted)-1]
+	}
+	frac := rank - float64(lower)
+	return sorted[lower] + frac*(sorted[lower+1]-sorted[lower])
+}
@@ -0,0 +1,73 @@
+package metrics
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/scalebox/scalebox-sdk-golang/models"
+)
+
+func TestSummarize(t *testing.T) {
+	s := synthetic(101, time.Second)
+	cpu := s.Summarize(CPUPercent)
+	want := Summary{Count: 101, Min: 0, Max: 100, Mean: 50, P50: 50, P95: 95, P99: 99}
+	if cpu != want {
+		t.Errorf("Summarize(CPUPercent) = %+v, want %+v", cpu, want)
+	}
+	if mem := s.Summarize(MemoryPercent); mem.Max != 100 || mem.P95 != 95 {
+		t.Errorf("Unexpected memory summary %+v", mem)
+	}
+	if disk := s.Summarize(DiskPercent); disk.Min != 25 || disk.Max != 25 {
+		t.Errorf("Unexpected disk summary %+v", disk)
+	}
+
+	// Points without totals do not count towards utilisation
+	s = append(s, models.MetricsDataPoint{Timestamp: epoch.Add(time.Hour), MemUsed: 1})
+	if mem := s.Summarize(MemoryPercent); mem.Count != 101 {
+		t.Errorf("Expected the point without a total to be skipped, got %d", mem.Count)
+	}
+	if (Series{}).Summarize(CPUPercent) != (Summary{}) {
+		t.Error("Expected a zero summary for an empty series")
+	}
+
+	report := synthetic(3, time.Minute).Report()
+	if report.Points != 3 || report.CPU.Mean != 1 || !report.End.Equal(epoch.Add(2*time.Minute)) {
+		t.Errorf("Unexpected report %+v", report)
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	values := []float64{10, 20, 30, 40}
+	tests := map[float64]float64{0: 10, 50: 25, 100: 40, 150: 40, -5: 10}
+	for p, want := range tests {
+		if got := Percentile(values, p); got != want {
+			t.Errorf("Percentile(%v) = %v, want %v", p, got, want)
+		}
+	}
+	if !math.IsNaN(Percentile(nil, 50)) {
+		t.Error("Expected NaN for no values")
+	}
+}
+
+func TestRate(t *testing.T) {
+	s := synthetic(4, 2*time.Second)
+	rates := s.Rate(MemoryUsed)
+	if len(rates) != 3 {
+		t.Fatalf("Expected 3 rates, got %d", len(rates))
+	}
+	for _, r := range rates {
+		if r.Value != float64(1<<20)/2 {
+			t.Errorf("Expected 0.5 MiB/s, got %v at %s", r.Value, r.Timestamp)
+		}
+	}
+	if !rates[0].Timestamp.Equal(epoch.Add(2 * time.Second)) {
+		t.Errorf("Expected rates stamped with the later point, got %s", rates[0].Timestamp)
+	}
+	if s[:1].Rate(CPUPercent) != nil {
+		t.Error("Expected no rate for a single point")
+	}
+	if CPUPercent.String() != "cpu_pct" || Metric(42).String() != "unknown" {
+		t.Error("Unexpected metric names")
+	}
+}
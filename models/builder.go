@@ -0,0 +1,209 @@
+package models
+
+import "time"
+
+// Ptr returns a pointer to v, for optional fields such as AutoPause, Secure and AllowInternetAccess
+func Ptr[T any](v T) *T {
+	return &v
+}
+
+// SandboxBuilder builds a CreateSandboxRequest fluently
+//
+//	req, err := models.NewSandbox("worker").
+//		Template("base").
+//		Size(models.SizeMedium).
+//		Timeout(10 * time.Minute).
+//		Env("MODE", "ci").
+//		Build()
+type SandboxBuilder struct {
+	req CreateSandboxRequest
+}
+
+// NewSandbox starts building a create request for a sandbox with the given name
+func NewSandbox(name string) *SandboxBuilder {
+	return &SandboxBuilder{req: CreateSandboxRequest{Name: name}}
+}
+
+// NewSandboxFrom starts building from a copy of an existing create request
+func NewSandboxFrom(req CreateSandboxRequest) *SandboxBuilder {
+	return &SandboxBuilder{req: req.Clone()}
+}
+
+// Name sets the sandbox name
+func (b *SandboxBuilder) Name(name string) *SandboxBuilder {
+	b.req.Name = name
+	return b
+}
+
+// Description sets the sandbox description
+func (b *SandboxBuilder) Description(description string) *SandboxBuilder {
+	b.req.Description = description
+	return b
+}
+
+// Template sets the template name or ID
+func (b *SandboxBuilder) Template(template string) *SandboxBuilder {
+	b.req.Template = template
+	return b
+}
+
+// Project sets the project ID
+func (b *SandboxBuilder) Project(projectID string) *SandboxBuilder {
+	b.req.ProjectID = projectID
+	return b
+}
+
+// Size sets CPU, memory and storage from a size preset
+func (b *SandboxBuilder) Size(size SizePreset) *SandboxBuilder {
+	b.req.CPUCount = size.CPUCount
+	b.req.MemoryMB = size.MemoryMB
+	b.req.StorageGB = size.StorageGB
+	return b
+}
+
+// CPU sets the CPU count
+func (b *SandboxBuilder) CPU(count int) *SandboxBuilder {
+	b.req.CPUCount = count
+	return b
+}
+
+// Memory sets the memory in MB
+func (b *SandboxBuilder) Memory(mb int) *SandboxBuilder {
+	b.req.MemoryMB = mb
+	return b
+}
+
+// Storage sets the storage in GB
+func (b *SandboxBuilder) Storage(gb int) *SandboxBuilder {
+	b.req.StorageGB = gb
+	return b
+}
+
+// Timeout sets the sandbox timeout, rounded up to whole seconds
+func (b *SandboxBuilder) Timeout(d time.Duration) *SandboxBuilder {
+	b.req.Timeout = Seconds(d)
+	return b
+}
+
+// AutoPause sets whether the sandbox pauses instead of terminating on timeout
+func (b *SandboxBuilder) AutoPause(enabled bool) *SandboxBuilder {
+	b.req.AutoPause = Ptr(enabled)
+	return b
+}
+
+// Secure sets the security setting
+func (b *SandboxBuilder) Secure(enabled bool) *SandboxBuilder {
+	b.req.Secure = Ptr(enabled)
+	return b
+}
+
+// AllowInternetAccess sets whether the sandbox may reach the internet
+func (b *SandboxBuilder) AllowInternetAccess(enabled bool) *SandboxBuilder {
+	b.req.AllowInternetAccess = Ptr(enabled)
+	return b
+}
+
+// Env adds an environment variable
+func (b *SandboxBuilder) Env(name, value string) *SandboxBuilder {
+	if b.req.EnvVars == nil {
+		b.req.EnvVars = make(map[string]string)
+	}
+	b.req.EnvVars[name] = value
+	return b
+}
+
+// Metadata adds a metadata entry
+func (b *SandboxBuilder) Metadata(key, value string) *SandboxBuilder {
+	if b.req.Metadata == nil {
+		b.req.Metadata = make(map[string]string)
+	}
+	b.req.Metadata[key] = value
+	return b
+}
+
+// Port exposes a custom port with the given protocol
+func (b *SandboxBuilder) Port(port int32, protocol string) *SandboxBuilder {
+	b.req.CustomPorts = append(b.req.CustomPorts, PortConfig{Port: port, Protocol: protocol})
+	return b
+}
+
+// PortConfig exposes a custom port with full configuration
+func (b *SandboxBuilder) PortConfig(port PortConfig) *SandboxBuilder {
+	b.req.CustomPorts = append(b.req.CustomPorts, port)
+	return b
+}
+
+// MountS3 mounts an S3 object path (s3://bucket/path) at an absolute mount point
+func (b *SandboxBuilder) MountS3(uri, mountPoint, accessKey, secretKey string) *SandboxBuilder {
+	b.req.ObjectStorage = &ObjectStorageConfig{
+		URI:        uri,
+		MountPoint: mountPoint,
+		AccessKey:  accessKey,
+		SecretKey:  secretKey,
+	}
+	return b
+}
+
+// ObjectStorage sets the full object storage configuration
+func (b *SandboxBuilder) ObjectStorage(cfg ObjectStorageConfig) *SandboxBuilder {
+	b.req.ObjectStorage = &cfg
+	return b
+}
+
+// ProxyCountry sets the preferred network proxy country (ISO 3166-1 alpha-2)
+func (b *SandboxBuilder) ProxyCountry(code string) *SandboxBuilder {
+	b.req.NetProxyCountry = code
+	return b
+}
+
+// Region requests scheduling in a region; force makes it a hard constraint
+func (b *SandboxBuilder) Region(region string, force bool) *SandboxBuilder {
+	b.req.Locality = &LocalityRequest{Region: region, Force: force}
+	return b
+}
+
+// Build validates and returns the request
+func (b *SandboxBuilder) Build() (CreateSandboxRequest, error) {
+	req := b.req.Clone()
+	if err := req.Validate(); err != nil {
+		return req, err
+	}
+	return req, nil
+}
+
+// Clone returns a deep copy of the request
+func (r CreateSandboxRequest) Clone() CreateSandboxRequest {
+	c := r
+	c.Metadata = cloneStringMap(r.Metadata)
+	c.EnvVars = cloneStringMap(r.EnvVars)
+	if r.AutoPause != nil {
+		c.AutoPause = Ptr(*r.AutoPause)
+	}
+	if r.Secure != nil {
+		c.Secure = Ptr(*r.Secure)
+	}
+	if r.AllowInternetAccess != nil {
+		c.AllowInternetAccess = Ptr(*r.AllowInternetAccess)
+	}
+	if r.ObjectStorage != nil {
+		c.ObjectStorage = Ptr(*r.ObjectStorage)
+	}
+	if r.Locality != nil {
+		c.Locality = Ptr(*r.Locality)
+	}
+	if r.CustomPorts != nil {
+		c.CustomPorts = append([]PortConfig(nil), r.CustomPorts...)
+	}
+	return c
+}
+
+func cloneStringMap(m map[string]string) map[string]string {
+	if m == nil {
+		return nil
+	}
+	c := make(map[string]string, len(m))
+	for k, v := range m {
+		c[k] = v
+	}
+	return c
+}
@@ -0,0 +1,109 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSandboxBuilder(t *testing.T) {
+	req, err := NewSandbox("worker").
+		Template("base").
+		Size(SizeMedium).
+		Timeout(90*time.Second+time.Millisecond).
+		AutoPause(true).
+		AllowInternetAccess(false).
+		Env("MODE", "ci").
+		Metadata("team", "ml").
+		Port(8080, "http").
+		MountS3("s3://bucket/data", "/mnt/data", "ak", "sk").
+		ProxyCountry("DE").
+		Build()
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+
+	if req.Name != "worker" || req.Template != "base" {
+		t.Errorf("Unexpected name/template: %q/%q", req.Name, req.Template)
+	}
+	if req.CPUCount != 2 || req.MemoryMB != 2048 || req.StorageGB != 10 {
+		t.Errorf("Expected medium size, got %d/%d/%d", req.CPUCount, req.MemoryMB, req.StorageGB)
+	}
+	if req.Timeout != 91 {
+		t.Errorf("Expected timeout rounded up to 91s, got %d", req.Timeout)
+	}
+	if req.AutoPause == nil || !*req.AutoPause || req.AllowInternetAccess == nil || *req.AllowInternetAccess {
+		t.Errorf("Unexpected pointer flags: %v %v", req.AutoPause, req.AllowInternetAccess)
+	}
+	if req.Secure != nil {
+		t.Errorf("Expected Secure left unset, got %v", *req.Secure)
+	}
+	if req.EnvVars["MODE"] != "ci" || req.Metadata["team"] != "ml" {
+		t.Errorf("Unexpected env/metadata: %v %v", req.EnvVars, req.Metadata)
+	}
+	if len(req.CustomPorts) != 1 || req.CustomPorts[0].Port != 8080 {
+		t.Errorf("Unexpected ports: %v", req.CustomPorts)
+	}
+	if req.ObjectStorage == nil || req.ObjectStorage.MountPoint != "/mnt/data" {
+		t.Errorf("Unexpected object storage: %v", req.ObjectStorage)
+	}
+
+	if _, err := NewSandbox("bad").CPU(-1).Build(); err == nil {
+		t.Error("Expected Build to validate the request")
+	}
+}
+
+func TestSandboxBuilderFromCopies(t *testing.T) {
+	base := CreateSandboxRequest{Name: "base", EnvVars: map[string]string{"A": "1"}}
+	req, err := NewSandboxFrom(base).Env("B", "2").Build()
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	if len(req.EnvVars) != 2 {
+		t.Errorf("Expected 2 env vars, got %v", req.EnvVars)
+	}
+	if len(base.EnvVars) != 1 {
+		t.Errorf("Expected base request to be untouched, got %v", base.EnvVars)
+	}
+}
+
+func TestParsePresets(t *testing.T) {
+	doc := `{
+  "sizes": {"gpu-box": {"cpu_count": 16, "memory_mb": 65536, "storage_gb": 100}},
+  "presets": {
+    "ci-runner": {"template": "base", "size": "medium", "timeout": 600, "auto_pause": false, "env_vars": {"CI": "true"}},
+    "trainer": {"template": "pytorch", "size": "gpu-box"}
+  }
+}`
+
+	presets, err := ParsePresets([]byte(doc))
+	if err != nil {
+		t.Fatalf("ParsePresets failed: %v", err)
+	}
+	b, err := presets.NewSandbox("ci-runner", "job-42")
+	if err != nil {
+		t.Fatalf("NewSandbox failed: %v", err)
+	}
+	req, err := b.Build()
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	if req.Name != "job-42" || req.Template != "base" || req.Timeout != 600 || req.CPUCount != 2 {
+		t.Errorf("Unexpected request %+v", req)
+	}
+	if req.AutoPause == nil || *req.AutoPause || req.EnvVars["CI"] != "true" {
+		t.Errorf("Unexpected flags/env %+v", req)
+	}
+	if size, ok := presets.Size("gpu-box"); !ok || size.CPUCount != 16 {
+		t.Errorf("Expected custom size gpu-box, got %+v", size)
+	}
+	if names := presets.Names(); len(names) != 2 || names[0] != "ci-runner" {
+		t.Errorf("Unexpected preset names %v", names)
+	}
+
+	if _, err := ParsePresets([]byte(`{"presets": {"x": {"size": "huge"}}}`)); err == nil {
+		t.Error("Expected error for unknown size")
+	}
+	if _, err := ParsePresets([]byte(`{"presets": {"x": {"cpu": 2}}}`)); err == nil {
+		t.Error("Expected error for unknown field")
+	}
+}
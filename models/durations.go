@@ -0,0 +1,160 @@
+package models
+
+import (
+	"math"
+	"time"
+)
+
+// Seconds converts a duration to the whole seconds used on the wire, rounding up
+func Seconds(d time.Duration) int {
+	if d <= 0 {
+		return 0
+	}
+	return int(math.Ceil(d.Seconds()))
+}
+
+// SecondsDuration converts wire seconds to a duration
+func SecondsDuration(seconds int) time.Duration {
+	return time.Duration(seconds) * time.Second
+}
+
+// TimeoutDuration returns the requested timeout
+func (r CreateSandboxRequest) TimeoutDuration() time.Duration {
+	return SecondsDuration(r.Timeout)
+}
+
+// SetTimeoutDuration sets the timeout, rounded up to whole seconds
+func (r *CreateSandboxRequest) SetTimeoutDuration(d time.Duration) {
+	r.Timeout = Seconds(d)
+}
+
+// TimeoutDuration returns the requested timeout and whether one is set
+func (r UpdateSandboxRequest) TimeoutDuration() (time.Duration, bool) {
+	if r.Timeout == nil {
+		return 0, false
+	}
+	return SecondsDuration(*r.Timeout), true
+}
+
+// SetTimeoutDuration sets the timeout, rounded up to whole seconds
+func (r *UpdateSandboxRequest) SetTimeoutDuration(d time.Duration) {
+	r.Timeout = Ptr(Seconds(d))
+}
+
+// NewSandboxTimeoutRequest creates a timeout request from a duration, rounded up to whole seconds
+func NewSandboxTimeoutRequest(d time.Duration) SandboxTimeoutRequest {
+	return SandboxTimeoutRequest{Timeout: Seconds(d)}
+}
+
+// TimeoutDuration returns the requested timeout
+func (r SandboxTimeoutRequest) TimeoutDuration() time.Duration {
+	return SecondsDuration(r.Timeout)
+}
+
+// NewConnectSandboxRequest creates a connect request that also sets the timeout, rounded up to whole seconds
+func NewConnectSandboxRequest(timeout time.Duration) *ConnectSandboxRequest {
+	return &ConnectSandboxRequest{Timeout: Ptr(Seconds(timeout))}
+}
+
+// TimeoutDuration returns the requested timeout and whether one is set
+func (r ConnectSandboxRequest) TimeoutDuration() (time.Duration, bool) {
+	if r.Timeout == nil {
+		return 0, false
+	}
+	return SecondsDuration(*r.Timeout), true
+}
+
+// SetStep sets the metrics step, rounded up to whole seconds
+func (o *GetSandboxMetricsOptions) SetStep(d time.Duration) {
+	o.Step = Ptr(Seconds(d))
+}
+
+// StepDuration returns the metrics step and whether one is set
+func (o GetSandboxMetricsOptions) StepDuration() (time.Duration, bool) {
+	if o.Step == nil {
+		return 0, false
+	}
+	return SecondsDuration(*o.Step), true
+}
+
+// TimeoutDuration returns the sandbox timeout
+func (s *Sandbox) TimeoutDuration() time.Duration {
+	return SecondsDuration(s.Timeout)
+}
+
+// TotalRunningDuration returns TotalRunningSeconds as a duration
+func (s *Sandbox) TotalRunningDuration() time.Duration {
+	return SecondsDuration(s.TotalRunningSeconds)
+}
+
+// TotalPausedDuration returns TotalPausedSeconds as a duration
+func (s *Sandbox) TotalPausedDuration() time.Duration {
+	return SecondsDuration(s.TotalPausedSeconds)
+}
+
+// UptimeDuration returns Uptime as a duration
+func (s *Sandbox) UptimeDuration() time.Duration {
+	return time.Duration(s.Uptime) * time.Second
+}
+
+// RemainingLifetime returns the time left until TimeoutAt.
+// It returns 0 when the sandbox has no deadline or the deadline has passed.
+func (s *Sandbox) RemainingLifetime(now time.Time) time.Duration {
+	if s.TimeoutAt == nil {
+		return 0
+	}
+	return positive(s.TimeoutAt.Sub(now))
+}
+
+// RunningDuration returns the total time the sandbox has spent running.
+// It prefers ActualTotalRunningSeconds; otherwise it adds the current running
+// period (since ResumedAt or StartedAt) to TotalRunningSeconds.
+func (s *Sandbox) RunningDuration(now time.Time) time.Duration {
+	if s.ActualTotalRunningSeconds != nil {
+		return time.Duration(*s.ActualTotalRunningSeconds) * time.Second
+	}
+	d := s.TotalRunningDuration()
+	if s.Status == StatusRunning {
+		since := s.StartedAt
+		if s.ResumedAt != nil && (since == nil || s.ResumedAt.After(*since)) {
+			since = s.ResumedAt
+		}
+		if since != nil {
+			d += positive(now.Sub(*since))
+		}
+	}
+	return d
+}
+
+// PausedDuration returns the total time the sandbox has spent paused.
+// It prefers ActualTotalPausedSeconds; otherwise it adds the current paused
+// period (since PausedAt) to TotalPausedSeconds.
+func (s *Sandbox) PausedDuration(now time.Time) time.Duration {
+	if s.ActualTotalPausedSeconds != nil {
+		return SecondsDuration(*s.ActualTotalPausedSeconds)
+	}
+	d := s.TotalPausedDuration()
+	if s.Status == StatusPaused && s.PausedAt != nil {
+		d += positive(now.Sub(*s.PausedAt))
+	}
+	return d
+}
+
+// PersistenceRemaining returns how long a paused sandbox's state is kept.
+// It uses PersistenceExpiresAt, falling back to PersistenceDaysRemaining, and returns 0 when neither is set.
+func (s *Sandbox) PersistenceRemaining(now time.Time) time.Duration {
+	if s.PersistenceExpiresAt != nil {
+		return positive(s.PersistenceExpiresAt.Sub(now))
+	}
+	if s.PersistenceDaysRemaining != nil {
+		return positive(time.Duration(*s.PersistenceDaysRemaining) * 24 * time.Hour)
+	}
+	return 0
+}
+
+func positive(d time.Duration) time.Duration {
+	if d < 0 {
+		return 0
+	}
+	return d
+}
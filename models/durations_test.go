@@ -0,0 +1,109 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestDurationRequestsMarshalSeconds(t *testing.T) {
+	var create CreateSandboxRequest
+	create.SetTimeoutDuration(10 * time.Minute)
+	if create.Timeout != 600 || create.TimeoutDuration() != 10*time.Minute {
+		t.Errorf("Expected 600s timeout, got %d", create.Timeout)
+	}
+
+	var update UpdateSandboxRequest
+	if _, ok := update.TimeoutDuration(); ok {
+		t.Error("Expected unset update timeout")
+	}
+	update.SetTimeoutDuration(90 * time.Second)
+	if d, ok := update.TimeoutDuration(); !ok || d != 90*time.Second {
+		t.Errorf("Expected 90s update timeout, got %v", d)
+	}
+
+	data, err := json.Marshal(NewSandboxTimeoutRequest(90*time.Second + 500*time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `{"timeout":91}` {
+		t.Errorf("Expected timeout rounded up to whole seconds, got %s", data)
+	}
+
+	data, err = json.Marshal(NewConnectSandboxRequest(2 * time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `{"timeout":120}` {
+		t.Errorf("Unexpected connect request %s", data)
+	}
+	if d, ok := (ConnectSandboxRequest{}).TimeoutDuration(); ok || d != 0 {
+		t.Errorf("Expected no timeout on empty connect request, got %v", d)
+	}
+
+	var opts GetSandboxMetricsOptions
+	opts.SetStep(15 * time.Second)
+	if step, ok := opts.StepDuration(); !ok || *opts.Step != 15 || step != 15*time.Second {
+		t.Errorf("Expected 15s step, got %v", opts.Step)
+	}
+}
+
+func TestSandboxComputedDurations(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	started := now.Add(-30 * time.Minute)
+	resumed := now.Add(-5 * time.Minute)
+	timeoutAt := now.Add(3 * time.Minute)
+
+	running := Sandbox{
+		Status:              StatusRunning,
+		StartedAt:           &started,
+		ResumedAt:           &resumed,
+		TimeoutAt:           &timeoutAt,
+		TotalRunningSeconds: 600,
+		TotalPausedSeconds:  900,
+	}
+	if got := running.RemainingLifetime(now); got != 3*time.Minute {
+		t.Errorf("RemainingLifetime = %v, want 3m", got)
+	}
+	if got := running.RemainingLifetime(now.Add(time.Hour)); got != 0 {
+		t.Errorf("Expected no remaining lifetime after the deadline, got %v", got)
+	}
+	// 10 completed minutes plus 5 minutes since the last resume
+	if got := running.RunningDuration(now); got != 15*time.Minute {
+		t.Errorf("RunningDuration = %v, want 15m", got)
+	}
+	if got := running.PausedDuration(now); got != 15*time.Minute {
+		t.Errorf("PausedDuration = %v, want 15m", got)
+	}
+
+	actual := int64(1234)
+	running.ActualTotalRunningSeconds = &actual
+	if got := running.RunningDuration(now); got != 1234*time.Second {
+		t.Errorf("Expected ActualTotalRunningSeconds to win, got %v", got)
+	}
+
+	pausedAt := now.Add(-2 * time.Hour)
+	expires := now.Add(48 * time.Hour)
+	paused := Sandbox{
+		Status:               StatusPaused,
+		PausedAt:             &pausedAt,
+		TotalPausedSeconds:   60,
+		PersistenceExpiresAt: &expires,
+	}
+	if got := paused.PausedDuration(now); got != 2*time.Hour+time.Minute {
+		t.Errorf("PausedDuration = %v, want 2h1m", got)
+	}
+	if got := paused.RemainingLifetime(now); got != 0 {
+		t.Errorf("Expected no deadline for paused sandbox, got %v", got)
+	}
+	if got := paused.PersistenceRemaining(now); got != 48*time.Hour {
+		t.Errorf("PersistenceRemaining = %v, want 48h", got)
+	}
+
+	days := 3
+	paused.PersistenceExpiresAt = nil
+	paused.PersistenceDaysRemaining = &days
+	if got := paused.PersistenceRemaining(now); got != 72*time.Hour {
+		t.Errorf("PersistenceRemaining = %v, want 72h", got)
+	}
+}
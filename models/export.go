@@ -0,0 +1,219 @@
+package models
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// SpecVersion is the api_version written to and accepted in sandbox spec files
+const SpecVersion = "scalebox/v1"
+
+// SandboxSpec is a reproducible description of a sandbox, usually stored as YAML or JSON
+//
+//	api_version: scalebox/v1
+//	source: {sandbox_id: sbx-7f3c2a91d4e5, name: worker-x7k2p, exported_at: "2025-03-04T08:20:00Z"}
+//	spec:
+//	  name: worker
+//	  template: tpl-base
+//	  cpu_count: 2
+//	  ...
+//	unreproducible:
+//	  - {field: locality, reason: the API does not return the requested locality}
+//	  - {field: object_storage.secret_key, reason: credentials are not returned by the API}
+type SandboxSpec struct {
+	APIVersion     string                `json:"api_version"`
+	Source         *SpecSource           `json:"source,omitempty"`
+	Spec           CreateSandboxRequest  `json:"spec"`
+	Unreproducible []UnreproducibleField `json:"unreproducible,omitempty"`
+}
+
+// SpecSource identifies the sandbox a spec was exported from
+type SpecSource struct {
+	SandboxID  string    `json:"sandbox_id"`
+	Name       string    `json:"name"`
+	ExportedAt time.Time `json:"exported_at"`
+}
+
+// UnreproducibleField is a setting of the source sandbox that the spec cannot carry
+type UnreproducibleField struct {
+	Field  string `json:"field"`
+	Reason string `json:"reason"`
+}
+
+// sdkMetadataPrefixes match the metadata keys SDK helpers write to track a sandbox:
+// pool membership, Ensure client keys, scaleboxtest runs and idle decisions
+var sdkMetadataPrefixes = []string{"scalebox.pool", "scalebox.client-key", "scalebox.test", "scalebox.idle."}
+
+// IsSDKMetadataKey reports whether key is managed by an SDK helper rather than set by the user
+func IsSDKMetadataKey(key string) bool {
+	for _, prefix := range sdkMetadataPrefixes {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// ToCreateRequest returns a request that creates a sandbox configured like s.
+// It maps the name without its backend suffix (see RequestedName), the template, resources,
+// timeout, ports, metadata, env vars, auto-pause and security flags. Metadata keys managed by
+// the SDK (see IsSDKMetadataKey) are left out, so the copy does not join the source's pool or
+// test run. The API returns neither the locality nor the proxy country, and object storage
+// comes back without AccessKey and SecretKey; see Unreproducible.
+func (s *Sandbox) ToCreateRequest() CreateSandboxRequest {
+	req := CreateSandboxRequest{
+		Name:                RequestedName(s.Name),
+		Template:            s.TemplateID,
+		ProjectID:           s.ProjectID,
+		CPUCount:            s.CPUCount,
+		MemoryMB:            s.MemoryMB,
+		StorageGB:           s.StorageGB,
+		Metadata:            userMetadata(s.Metadata),
+		Timeout:             s.Timeout,
+		AutoPause:           Ptr(s.AutoPause),
+		EnvVars:             cloneStringMap(s.EnvVars),
+		Secure:              Ptr(s.Secure),
+		AllowInternetAccess: Ptr(s.AllowInternetAccess),
+	}
+	if s.Description != nil {
+		req.Description = *s.Description
+	}
+	if req.Template == "" && s.TemplateName != nil {
+		req.Template = *s.TemplateName
+	}
+	if s.CustomPorts != nil {
+		req.CustomPorts = append([]PortConfig(nil), s.CustomPorts...)
+	}
+	if len(s.ObjectStorage) > 0 {
+		req.ObjectStorage = &ObjectStorageConfig{
+			URI:        s.ObjectStorage["uri"],
+			MountPoint: s.ObjectStorage["mount_point"],
+			Endpoint:   s.ObjectStorage["endpoint"],
+			Region:     s.ObjectStorage["region"],
+		}
+	}
+	return req
+}
+
+// Unreproducible lists the settings of s that ToCreateRequest cannot carry over
+func (s *Sandbox) Unreproducible() []UnreproducibleField {
+	fields := []UnreproducibleField{{Field: "locality", Reason: "the API does not return the requested locality"}}
+	if enabled, ok := s.NetworkProxy["enabled"].(bool); len(s.NetworkProxy) > 0 && (!ok || enabled) {
+		fields = append(fields, UnreproducibleField{Field: "net_proxy_country", Reason: "the API reports the proxy in use, not the requested country"})
+	}
+	if len(s.ObjectStorage) > 0 {
+		fields = append(fields,
+			UnreproducibleField{Field: "object_storage.access_key", Reason: "credentials are not returned by the API"},
+			UnreproducibleField{Field: "object_storage.secret_key", Reason: "credentials are not returned by the API"},
+		)
+	}
+	keys := make([]string, 0, len(s.Extra))
+	for k := range s.Extra {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fields = append(fields, UnreproducibleField{Field: k, Reason: "not modelled by this SDK version"})
+	}
+	return fields
+}
+
+// RequestedName returns name without the random "-suffix" the backend appends to requested
+// names, e.g. "worker-x7k2p" gives "worker". As with GetByName the suffix is the last segment
+// and has no dashes; it is only stripped when it looks generated, 4 to 8 lower-case letters
+// and digits with at least one of each, so names such as "ci-runner" or "build-2024" are kept.
+func RequestedName(name string) string {
+	i := strings.LastIndex(name, "-")
+	if i <= 0 || !isGeneratedSuffix(name[i+1:]) {
+		return name
+	}
+	return name[:i]
+}
+
+func isGeneratedSuffix(s string) bool {
+	if len(s) < 4 || len(s) > 8 {
+		return false
+	}
+	letters, digits := 0, 0
+	for _, r := range s {
+		switch {
+		case r >= 'a' && r <= 'z':
+			letters++
+		case r >= '0' && r <= '9':
+			digits++
+		default:
+			return false
+		}
+	}
+	return letters > 0 && digits > 0
+}
+
+// userMetadata copies metadata without the keys managed by the SDK
+func userMetadata(metadata map[string]string) map[string]string {
+	if metadata == nil {
+		return nil
+	}
+	out := make(map[string]string, len(metadata))
+	for k, v := range metadata {
+		if !IsSDKMetadataKey(k) {
+			out[k] = v
+		}
+	}
+	return out
+}
+
+// NewSandboxSpec exports s as a spec
+func NewSandboxSpec(s *Sandbox) *SandboxSpec {
+	return &SandboxSpec{
+		APIVersion:     SpecVersion,
+		Source:         &SpecSource{SandboxID: s.SandboxID, Name: s.Name, ExportedAt: time.Now().UTC().Truncate(time.Second)},
+		Spec:           s.ToCreateRequest(),
+		Unreproducible: s.Unreproducible(),
+	}
+}
+
+// Unresolved returns the unreproducible fields that still have no value in the spec.
+// Object storage credentials can be filled in by hand before importing; other entries are informational.
+func (s *SandboxSpec) Unresolved() []UnreproducibleField {
+	var missing []UnreproducibleField
+	for _, f := range s.Unreproducible {
+		var value string
+		switch f.Field {
+		case "object_storage.access_key":
+			if s.Spec.ObjectStorage != nil {
+				value = s.Spec.ObjectStorage.AccessKey
+			}
+		case "object_storage.secret_key":
+			if s.Spec.ObjectStorage != nil {
+				value = s.Spec.ObjectStorage.SecretKey
+			}
+		default:
+			continue
+		}
+		if value == "" {
+			missing = append(missing, f)
+		}
+	}
+	return missing
+}
+
+// ParseSandboxSpec decodes a JSON spec file; YAML files are read with package specfile
+func ParseSandboxSpec(data []byte) (*SandboxSpec, error) {
+	var s SandboxSpec
+	if err := decodeStrict(data, &s); err != nil {
+		return nil, err
+	}
+	if err := s.check(); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+func (s *SandboxSpec) check() error {
+	if s.APIVersion != SpecVersion {
+		return fmt.Errorf("unsupported spec api_version %q, want %q", s.APIVersion, SpecVersion)
+	}
+	return nil
+}
@@ -0,0 +1,160 @@
+package models
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// specSandbox returns the recorded sandbox with every create-time setting populated
+func specSandbox(t *testing.T) *Sandbox {
+	t.Helper()
+	body, err := os.ReadFile(filepath.Join("testdata", "responses", "sandbox_get.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var sb Sandbox
+	if err := parseFixture(t, body, &sb); err != nil {
+		t.Fatal(err)
+	}
+	sb.Description = Ptr("flaky worker")
+	sb.Metadata["scalebox.pool"] = "workers"
+	sb.Metadata["scalebox.test-run"] = "run-1"
+	sb.ObjectStorage = map[string]string{"uri": "s3://bucket/data", "mount_point": "/mnt/data", "endpoint": "https://s3.example.com", "region": "eu-central-1"}
+	sb.Extra = map[string]json.RawMessage{"gpu_count": json.RawMessage(`1`)}
+	sb.NetworkProxy = map[string]interface{}{"enabled": true}
+	return &sb
+}
+
+// runtimeSandboxFields are Sandbox fields describing state or identity rather than configuration.
+// Every other field must be carried over by ToCreateRequest.
+var runtimeSandboxFields = []string{
+	"sandbox_id", "template_name", "template_exists", "owner_user_id", "project_name",
+	"ports", "template_ports", "status", "substatus", "reason", "sandbox_domain", "sandbox_domain_internal",
+	"web_terminal_available", "web_files_available", "envd_access_token",
+	"created_at", "updated_at", "started_at", "stopped_at", "ended_at", "timeout_at", "paused_at", "pausing_at", "resumed_at",
+	"total_paused_seconds", "total_running_seconds", "actual_total_paused_seconds", "actual_total_running_seconds", "uptime",
+	"persistence_days", "persistence_expires_at", "persistence_days_remaining", "owner", "account_owner", "resources",
+	"network_proxy", // Reports the proxy in use, not the requested country
+}
+
+func TestToCreateRequestLosesNoField(t *testing.T) {
+	sb := specSandbox(t)
+	req := sb.ToCreateRequest()
+
+	// Every request field is set, apart from those flagged as unreproducible
+	flagged := map[string]bool{}
+	for _, f := range sb.Unreproducible() {
+		flagged[f.Field] = true
+	}
+	checkSet(t, "", reflect.ValueOf(req), flagged)
+	if !flagged["gpu_count"] {
+		t.Errorf("Expected unmodelled field to be flagged, got %v", sb.Unreproducible())
+	}
+
+	// Every configuration field of Sandbox has a counterpart in the request
+	requestFields := map[string]bool{"template_id": true}
+	for _, name := range jsonFieldNames(reflect.TypeOf(req)) {
+		requestFields[name] = true
+	}
+	for _, name := range jsonFieldNames(reflect.TypeOf(*sb)) {
+		if !requestFields[name] && !containsString(runtimeSandboxFields, name) {
+			t.Errorf("Sandbox field %s is neither exported to the spec nor listed in runtimeSandboxFields", name)
+		}
+	}
+
+	if req.Name != "integration-test-sandbox" || req.Template != "tpl-base" || *req.Secure != true {
+		t.Errorf("Unexpected request %+v", req)
+	}
+	if err := req.Validate(); !strings.Contains(err.Error(), "access_key") {
+		t.Errorf("Expected only missing credentials to fail validation, got %v", err)
+	}
+
+	for k := range req.Metadata {
+		if strings.HasPrefix(k, "scalebox.") {
+			t.Errorf("Expected SDK metadata key %s to be stripped", k)
+		}
+	}
+
+	req.Metadata["environment"] = "changed"
+	if sb.Metadata["environment"] == "changed" {
+		t.Error("ToCreateRequest must copy metadata")
+	}
+}
+
+// checkSet reports zero-valued fields of v that are not flagged.
+// Plain bools are skipped since false is a meaningful value.
+func checkSet(t *testing.T, prefix string, v reflect.Value, flagged map[string]bool) {
+	t.Helper()
+	if v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+	typ := v.Type()
+	for i := 0; i < typ.NumField(); i++ {
+		name := prefix + strings.Split(typ.Field(i).Tag.Get("json"), ",")[0]
+		field := v.Field(i)
+		switch {
+		case flagged[name]:
+			if !field.IsZero() {
+				t.Errorf("Flagged field %s should be empty, got %v", name, field)
+			}
+		case field.Kind() == reflect.Bool:
+		case field.IsZero():
+			t.Errorf("Field %s was not carried over", name)
+		case field.Kind() == reflect.Ptr && field.Elem().Kind() == reflect.Struct:
+			checkSet(t, name+".", field, flagged)
+		}
+	}
+}
+
+func TestSandboxSpecRoundTrip(t *testing.T) {
+	sb := specSandbox(t)
+	spec := NewSandboxSpec(sb)
+	spec.Spec.ObjectStorage.AccessKey = "ak"
+	spec.Spec.ObjectStorage.SecretKey = "sk"
+
+	data, err := json.Marshal(spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := ParseSandboxSpec(data)
+	if err != nil {
+		t.Fatalf("%v\n%s", err, data)
+	}
+	if !reflect.DeepEqual(parsed, spec) {
+		t.Errorf("Round trip changed the spec:\n got %+v\nwant %+v", parsed, spec)
+	}
+	if len(parsed.Unresolved()) != 0 {
+		t.Errorf("Expected credentials to be resolved, got %v", parsed.Unresolved())
+	}
+
+	spec.Spec.ObjectStorage.SecretKey = ""
+	if missing := spec.Unresolved(); len(missing) != 1 || missing[0].Field != "object_storage.secret_key" {
+		t.Errorf("Expected missing secret key, got %v", missing)
+	}
+
+	if _, err := ParseSandboxSpec([]byte(`{"api_version": "scalebox/v0", "spec": {"name": "x"}}`)); err == nil {
+		t.Error("Expected unknown api_version to be rejected")
+	}
+	if _, err := ParseSandboxSpec([]byte(`{"api_version": "scalebox/v1", "spec": {"nmae": "x"}}`)); err == nil {
+		t.Error("Expected unknown spec field to be rejected")
+	}
+}
+
+func TestRequestedName(t *testing.T) {
+	for name, want := range map[string]string{
+		"worker-x7k2p":    "worker",
+		"web-server-ab12": "web-server",
+		"ci-runner":       "ci-runner",
+		"build-2024":      "build-2024",
+		"worker":          "worker",
+		"-x7k2p":          "-x7k2p",
+	} {
+		if got := RequestedName(name); got != want {
+			t.Errorf("RequestedName(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
@@ -0,0 +1,162 @@
+package models
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+)
+
+// unmarshalWithExtra decodes data into v and returns the top-level fields v does not model
+func unmarshalWithExtra(data []byte, v interface{}) (map[string]json.RawMessage, error) {
+	if err := json.Unmarshal(data, v); err != nil {
+		return nil, err
+	}
+	var all map[string]json.RawMessage
+	if err := json.Unmarshal(data, &all); err != nil {
+		return nil, err
+	}
+	for _, name := range jsonFieldNames(reflect.TypeOf(v).Elem()) {
+		delete(all, name)
+	}
+	if len(all) == 0 {
+		return nil, nil
+	}
+	return all, nil
+}
+
+// marshalWithExtra encodes v and adds the extra fields that v does not already contain
+func marshalWithExtra(v interface{}, extra map[string]json.RawMessage) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil || len(extra) == 0 {
+		return data, err
+	}
+	var all map[string]json.RawMessage
+	if err := json.Unmarshal(data, &all); err != nil {
+		return nil, err
+	}
+	for k, raw := range extra {
+		if _, ok := all[k]; !ok {
+			all[k] = raw
+		}
+	}
+	return json.Marshal(all)
+}
+
+func jsonFieldNames(t reflect.Type) []string {
+	var names []string
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" || !f.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+		if name == "" {
+			name = f.Name
+		}
+		names = append(names, name)
+	}
+	return names
+}
+
+// UnmarshalJSON decodes the sandbox and keeps unmodelled fields in Extra
+func (s *Sandbox) UnmarshalJSON(data []byte) error {
+	type plain Sandbox
+	extra, err := unmarshalWithExtra(data, (*plain)(s))
+	if err != nil {
+		return err
+	}
+	s.Extra = extra
+	return nil
+}
+
+// MarshalJSON encodes the sandbox including the fields kept in Extra
+func (s Sandbox) MarshalJSON() ([]byte, error) {
+	type plain Sandbox
+	return marshalWithExtra(plain(s), s.Extra)
+}
+
+// UnmarshalJSON decodes the status and keeps unmodelled fields in Extra
+func (s *SandboxStatus) UnmarshalJSON(data []byte) error {
+	type plain SandboxStatus
+	extra, err := unmarshalWithExtra(data, (*plain)(s))
+	if err != nil {
+		return err
+	}
+	s.Extra = extra
+	return nil
+}
+
+// MarshalJSON encodes the status including the fields kept in Extra
+func (s SandboxStatus) MarshalJSON() ([]byte, error) {
+	type plain SandboxStatus
+	return marshalWithExtra(plain(s), s.Extra)
+}
+
+// UnmarshalJSON decodes the list and keeps unmodelled fields in Extra
+func (r *SandboxListResponse) UnmarshalJSON(data []byte) error {
+	type plain SandboxListResponse
+	extra, err := unmarshalWithExtra(data, (*plain)(r))
+	if err != nil {
+		return err
+	}
+	r.Extra = extra
+	return nil
+}
+
+// MarshalJSON encodes the list including the fields kept in Extra
+func (r SandboxListResponse) MarshalJSON() ([]byte, error) {
+	type plain SandboxListResponse
+	return marshalWithExtra(plain(r), r.Extra)
+}
+
+// UnmarshalJSON decodes the response and keeps unmodelled fields in Extra
+func (r *DeletionResponse) UnmarshalJSON(data []byte) error {
+	type plain DeletionResponse
+	extra, err := unmarshalWithExtra(data, (*plain)(r))
+	if err != nil {
+		return err
+	}
+	r.Extra = extra
+	return nil
+}
+
+// MarshalJSON encodes the response including the fields kept in Extra
+func (r DeletionResponse) MarshalJSON() ([]byte, error) {
+	type plain DeletionResponse
+	return marshalWithExtra(plain(r), r.Extra)
+}
+
+// UnmarshalJSON decodes the response and keeps unmodelled fields in Extra
+func (r *TerminationResponse) UnmarshalJSON(data []byte) error {
+	type plain TerminationResponse
+	extra, err := unmarshalWithExtra(data, (*plain)(r))
+	if err != nil {
+		return err
+	}
+	r.Extra = extra
+	return nil
+}
+
+// MarshalJSON encodes the response including the fields kept in Extra
+func (r TerminationResponse) MarshalJSON() ([]byte, error) {
+	type plain TerminationResponse
+	return marshalWithExtra(plain(r), r.Extra)
+}
+
+// UnmarshalJSON decodes the response and keeps unmodelled fields in Extra
+func (r *SandboxMetricsResponse) UnmarshalJSON(data []byte) error {
+	type plain SandboxMetricsResponse
+	extra, err := unmarshalWithExtra(data, (*plain)(r))
+	if err != nil {
+		return err
+	}
+	r.Extra = extra
+	return nil
+}
+
+// MarshalJSON encodes the response including the fields kept in Extra
+func (r SandboxMetricsResponse) MarshalJSON() ([]byte, error) {
+	type plain SandboxMetricsResponse
+	return marshalWithExtra(plain(r), r.Extra)
+}
@@ -0,0 +1,111 @@
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/scalebox/scalebox-sdk-golang/client"
+)
+
+// responseFixtures maps each recorded response in testdata/responses to the model it decodes into
+var responseFixtures = map[string]func() interface{}{
+	"sandbox_get.json":       func() interface{} { return &Sandbox{} },
+	"sandbox_paused.json":    func() interface{} { return &Sandbox{} },
+	"sandbox_list.json":      func() interface{} { return &SandboxListResponse{} },
+	"sandbox_status.json":    func() interface{} { return &SandboxStatus{} },
+	"sandbox_metrics.json":   func() interface{} { return &SandboxMetricsResponse{} },
+	"sandbox_delete.json":    func() interface{} { return &DeletionResponse{} },
+	"sandbox_terminate.json": func() interface{} { return &TerminationResponse{} },
+}
+
+func parseFixture(t *testing.T, body []byte, target interface{}) error {
+	t.Helper()
+	c := client.NewClient("http://localhost", "test-api-key")
+	c.SchemaMode = client.SchemaStrict
+	resp := &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewReader(body))}
+	return c.ParseResponse(resp, target)
+}
+
+func TestResponseFixturesMatchModels(t *testing.T) {
+	files, err := filepath.Glob(filepath.Join("testdata", "responses", "*.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != len(responseFixtures) {
+		t.Errorf("Expected %d fixtures, found %d; register new fixtures in responseFixtures", len(responseFixtures), len(files))
+	}
+
+	for _, file := range files {
+		newTarget, ok := responseFixtures[filepath.Base(file)]
+		if !ok {
+			t.Errorf("%s: no model registered", file)
+			continue
+		}
+		body, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := parseFixture(t, body, newTarget()); err != nil {
+			t.Errorf("%s: %v", file, err)
+		}
+	}
+}
+
+func TestSchemaDriftAndExtraRoundTrip(t *testing.T) {
+	body, err := os.ReadFile(filepath.Join("testdata", "responses", "sandbox_get.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Simulate the backend adding a field and dropping a required one
+	var doc struct {
+		Success bool                   `json:"success"`
+		Data    map[string]interface{} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &doc); err != nil {
+		t.Fatal(err)
+	}
+	doc.Data["gpu_count"] = 1
+	delete(doc.Data, "cpu_count")
+	drifted, err := json.Marshal(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var sandbox Sandbox
+	err = parseFixture(t, drifted, &sandbox)
+	drift, ok := err.(*client.SchemaDriftError)
+	if !ok {
+		t.Fatalf("Expected *client.SchemaDriftError, got %v", err)
+	}
+	if len(drift.Warnings) != 2 ||
+		drift.Warnings[0] != (client.SchemaWarning{Kind: client.UnknownField, Path: "gpu_count", Type: "models.Sandbox"}) ||
+		drift.Warnings[1] != (client.SchemaWarning{Kind: client.MissingField, Path: "cpu_count", Type: "models.Sandbox"}) {
+		t.Errorf("Unexpected warnings: %v", drift.Warnings)
+	}
+
+	// The target is still decoded and the unknown field is preserved
+	if sandbox.SandboxID != "sbx-7f3c2a91d4e5" {
+		t.Errorf("Expected sandbox to be decoded, got %q", sandbox.SandboxID)
+	}
+	if string(sandbox.Extra["gpu_count"]) != "1" {
+		t.Fatalf("Expected gpu_count in Extra, got %v", sandbox.Extra)
+	}
+
+	encoded, err := json.Marshal(sandbox)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var again Sandbox
+	if err := json.Unmarshal(encoded, &again); err != nil {
+		t.Fatal(err)
+	}
+	if string(again.Extra["gpu_count"]) != "1" || again.Name != sandbox.Name {
+		t.Errorf("Expected Extra to survive a round trip, got %v", again.Extra)
+	}
+}
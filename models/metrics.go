@@ -1,6 +1,10 @@
 package models
 
-import "time"
+import (
+	"encoding/json"
+	"sort"
+	"time"
+)
 
 // SandboxMetricsResponse represents the response from getting sandbox metrics
 type SandboxMetricsResponse struct {
@@ -9,6 +13,8 @@ type SandboxMetricsResponse struct {
 	Status        string             `json:"status"`
 	UptimeSeconds int64              `json:"uptime_seconds"`
 	Metrics       []MetricsDataPoint `json:"metrics"`
+
+	Extra map[string]json.RawMessage `json:"-"` // Unmodelled fields, see Sandbox.Extra
 }
 
 // MetricsDataPoint represents a single timestamped metrics group
@@ -21,3 +27,24 @@ type MetricsDataPoint struct {
 	MemTotal   int64     `json:"mem_total"`    // requested memory (bytes)
 	MemUsed    int64     `json:"mem_used"`     // used memory (bytes)
 }
+
+// MergeMetrics combines data points in any order into a time-ordered list with one point
+// per timestamp. When several points share a timestamp, the one given last wins, so later
+// responses override earlier ones.
+func MergeMetrics(points ...[]MetricsDataPoint) []MetricsDataPoint {
+	var merged []MetricsDataPoint
+	for _, p := range points {
+		merged = append(merged, p...)
+	}
+	sort.SliceStable(merged, func(i, j int) bool { return merged[i].Timestamp.Before(merged[j].Timestamp) })
+
+	out := merged[:0]
+	for _, p := range merged {
+		if n := len(out); n > 0 && out[n-1].Timestamp.Equal(p.Timestamp) {
+			out[n-1] = p
+			continue
+		}
+		out = append(out, p)
+	}
+	return out
+}
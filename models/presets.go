@@ -0,0 +1,115 @@
+package models
+
+import (
+	"fmt"
+	"sort"
+)
+
+// SizePreset is a named combination of CPU, memory and storage
+type SizePreset struct {
+	Name      string `json:"-"`
+	CPUCount  int    `json:"cpu_count"`
+	MemoryMB  int    `json:"memory_mb"`
+	StorageGB int    `json:"storage_gb"`
+}
+
+// Built-in size presets
+var (
+	SizeSmall  = SizePreset{Name: "small", CPUCount: 1, MemoryMB: 512, StorageGB: 2}
+	SizeMedium = SizePreset{Name: "medium", CPUCount: 2, MemoryMB: 2048, StorageGB: 10}
+	SizeLarge  = SizePreset{Name: "large", CPUCount: 4, MemoryMB: 8192, StorageGB: 20}
+	SizeXLarge = SizePreset{Name: "xlarge", CPUCount: 8, MemoryMB: 16384, StorageGB: 50}
+)
+
+// SizeByName returns the built-in size preset with the given name
+func SizeByName(name string) (SizePreset, bool) {
+	for _, s := range []SizePreset{SizeSmall, SizeMedium, SizeLarge, SizeXLarge} {
+		if s.Name == name {
+			return s, true
+		}
+	}
+	return SizePreset{}, false
+}
+
+// Preset is a named sandbox shape shared through a preset file.
+// Size refers to a size preset and overrides the CPU, memory and storage fields.
+type Preset struct {
+	Size string `json:"size,omitempty"`
+	CreateSandboxRequest
+}
+
+// PresetFile is a collection of size presets and sandbox presets, usually loaded from YAML or JSON with package specfile
+//
+//	sizes:
+//	  gpu-box: {cpu_count: 16, memory_mb: 65536, storage_gb: 100}
+//	presets:
+//	  ci-runner:
+//	    template: base
+//	    size: medium
+//	    timeout: 600
+//	    env_vars: {CI: "true"}
+type PresetFile struct {
+	Sizes   map[string]SizePreset `json:"sizes,omitempty"`
+	Presets map[string]Preset     `json:"presets,omitempty"`
+}
+
+// ParsePresets decodes a JSON preset file; YAML files are read with package specfile
+func ParsePresets(data []byte) (*PresetFile, error) {
+	var f PresetFile
+	if err := decodeStrict(data, &f); err != nil {
+		return nil, err
+	}
+	if err := f.check(); err != nil {
+		return nil, err
+	}
+	return &f, nil
+}
+
+func (f *PresetFile) check() error {
+	for name, p := range f.Presets {
+		if p.Size == "" {
+			continue
+		}
+		if _, ok := f.Size(p.Size); !ok {
+			return fmt.Errorf("preset %q refers to unknown size %q", name, p.Size)
+		}
+	}
+	return nil
+}
+
+// Size returns a size preset defined in the file, falling back to the built-in presets
+func (f *PresetFile) Size(name string) (SizePreset, bool) {
+	if s, ok := f.Sizes[name]; ok {
+		s.Name = name
+		return s, true
+	}
+	return SizeByName(name)
+}
+
+// Names returns the sorted preset names
+func (f *PresetFile) Names() []string {
+	names := make([]string, 0, len(f.Presets))
+	for name := range f.Presets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// NewSandbox starts a builder seeded from the named preset
+func (f *PresetFile) NewSandbox(preset, name string) (*SandboxBuilder, error) {
+	p, ok := f.Presets[preset]
+	if !ok {
+		return nil, fmt.Errorf("unknown preset %q", preset)
+	}
+
+	b := NewSandboxFrom(p.CreateSandboxRequest).Name(name)
+	if p.Size != "" {
+		size, ok := f.Size(p.Size)
+		if !ok {
+			return nil, fmt.Errorf("preset %q refers to unknown size %q", preset, p.Size)
+		}
+		b.Size(size)
+	}
+	return b, nil
+}
@@ -40,9 +40,53 @@ type LocalityRequest struct {
 	Force      bool   `json:"force"`       // Hard constraint: fail if region not available (default: false, best-effort)
 }
 
-// UpdateSandboxRequest represents a request to update a sandbox
+// RedactedValue replaces secrets in the output of Redacted
+const RedactedValue = "<redacted>"
+
+// Redacted returns a copy of r that is safe to print: env var values and object storage
+// credentials are replaced with RedactedValue, everything else is kept
+func (r CreateSandboxRequest) Redacted() CreateSandboxRequest {
+	if r.EnvVars != nil {
+		env := make(map[string]string, len(r.EnvVars))
+		for k := range r.EnvVars {
+			env[k] = RedactedValue
+		}
+		r.EnvVars = env
+	}
+	if r.ObjectStorage != nil {
+		storage := *r.ObjectStorage
+		if storage.AccessKey != "" {
+			storage.AccessKey = RedactedValue
+		}
+		if storage.SecretKey != "" {
+			storage.SecretKey = RedactedValue
+		}
+		r.ObjectStorage = &storage
+	}
+	return r
+}
+
+// UpdateSandboxRequest represents a partial update of a sandbox's mutable attributes, sent via PATCH.
+// Nil fields are left unchanged, fields listed in Clear are reset, and the update mask sent
+// to the server is derived from the fields that are set unless UpdateMask is given explicitly.
+// See update.go for the wire format.
 type UpdateSandboxRequest struct {
-	Timeout int `json:"timeout"` // New timeout in seconds (extends lifetime from started_at) - must be greater than current
+	Name                *string           `json:"name,omitempty"`
+	Description         *string           `json:"description,omitempty"`
+	Timeout             *int              `json:"timeout,omitempty"`  // New timeout in seconds (extends lifetime from started_at) - must be greater than current
+	Metadata            map[string]string `json:"metadata,omitempty"` // Merged into the sandbox metadata unless MetadataMode is UpdateReplace
+	EnvVars             map[string]string `json:"env_vars,omitempty"` // Merged into the sandbox env vars unless EnvVarsMode is UpdateReplace
+	AutoPause           *bool             `json:"auto_pause,omitempty"`
+	AllowInternetAccess *bool             `json:"allow_internet_access,omitempty"`
+	ExpectedUpdatedAt   *time.Time        `json:"expected_updated_at,omitempty"` // Precondition: fail with a conflict if the sandbox changed since
+	UpdateMask          []string          `json:"update_mask,omitempty"`         // Optional: explicit field mask, derived from the fields above when empty
+
+	MetadataMode   MapUpdateMode `json:"-"`
+	RemoveMetadata []string      `json:"-"` // Metadata keys to delete (merge mode only)
+	EnvVarsMode    MapUpdateMode `json:"-"`
+	RemoveEnvVars  []string      `json:"-"` // Env var names to delete (merge mode only)
+	Clear          []string      `json:"-"` // Fields to reset, see ClearableUpdateFields
+	IfMatch        string        `json:"-"` // Precondition: ETag from a previous response, sent as If-Match
 }
 
 // SandboxTimeoutRequest represents a request to set sandbox timeout
@@ -61,21 +105,91 @@ type PauseSandboxRequest struct{}
 // ResumeSandboxRequest represents a request to resume a sandbox (empty struct)
 type ResumeSandboxRequest struct{}
 
+// AddLabelsRequest represents a request to add or overwrite metadata labels
+type AddLabelsRequest struct {
+	Labels map[string]string `json:"labels"`
+}
+
+// SortField names a field List can sort by. It is an alias of string so ListSandboxesOptions.SortBy
+// accepts both the constants below and values the SDK does not know yet.
+type SortField = string
+
+// Sort fields for ListSandboxesOptions.SortBy
+const (
+	SortByCreatedAt SortField = "created_at"
+	SortByUpdatedAt SortField = "updated_at"
+	SortByName      SortField = "name"
+	SortByStatus    SortField = "status"
+	SortByTimeoutAt SortField = "timeout_at"
+	SortByCPUCount  SortField = "cpu_count"
+	SortByMemoryMB  SortField = "memory_mb"
+)
+
+// SortFields lists the sort fields known to the SDK
+var SortFields = []SortField{SortByCreatedAt, SortByUpdatedAt, SortByName, SortByStatus, SortByTimeoutAt, SortByCPUCount, SortByMemoryMB}
+
+// IsKnownSortField reports whether field is one of SortFields
+func IsKnownSortField(field SortField) bool {
+	return containsString(SortFields, field)
+}
+
+// Sort orders accepted in ListSandboxesOptions.SortOrder
+const (
+	SortAsc  = "asc"
+	SortDesc = "desc"
+)
+
 // ListSandboxesOptions represents options for listing sandboxes
 type ListSandboxesOptions struct {
 	ProjectID   string
 	Status      string
 	OwnerUserID string
 	Search      string
-	SortBy      string
+	SortBy      string // One of the SortBy* constants; other values are sent unchanged with a warning
 	SortOrder   string
 	Limit       int
 	Offset      int
+
+	// LabelSelector filters on metadata labels, e.g. "team=ml,env in (ci,dev),!ephemeral".
+	// It is sent to the server and also applied client-side to the returned page.
+	LabelSelector string
+
+	Statuses          []string   // Match any of these statuses, in addition to Status
+	CreatedAfter      *time.Time // Only sandboxes created at or after this time
+	CreatedBefore     *time.Time // Only sandboxes created before this time
+	TimeoutBefore     *time.Time // Only sandboxes whose timeout_at is before this time, e.g. about to expire
+	TemplateID        string
+	TemplateName      string
+	Region            string
+	MinCPUCount       int  // Only sandboxes with at least this many CPUs
+	MinMemoryMB       int  // Only sandboxes with at least this much memory
+	IncludeTerminated bool // Sent as include_terminated=true
 }
 
+// StatusFilter returns Status and Statuses combined, without duplicates
+func (o ListSandboxesOptions) StatusFilter() []string {
+	var statuses []string
+	for _, st := range append([]string{o.Status}, o.Statuses...) {
+		if st != "" && !containsString(statuses, st) {
+			statuses = append(statuses, st)
+		}
+	}
+	return statuses
+}
+
+// TimeFormat selects how times are encoded in query parameters
+type TimeFormat string
+
+// Time formats
+const (
+	TimeFormatRFC3339 TimeFormat = "rfc3339" // Default
+	TimeFormatUnix    TimeFormat = "unix"    // Unix timestamp in seconds
+)
+
 // GetSandboxMetricsOptions represents options for getting sandbox metrics
 type GetSandboxMetricsOptions struct {
-	Start *time.Time
-	End   *time.Time
-	Step  *int
+	Start      *time.Time
+	End        *time.Time
+	Step       *int       // Step in seconds
+	TimeFormat TimeFormat // Encoding of Start and End, defaults to TimeFormatRFC3339
 }
@@ -1,6 +1,9 @@
 package models
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+)
 
 // Sandbox represents a sandbox instance
 type Sandbox struct {
@@ -55,6 +58,14 @@ type Sandbox struct {
 	Owner                     *Owner                 `json:"owner,omitempty"`
 	AccountOwner              *AccountOwner          `json:"account_owner,omitempty"`
 	Resources                 *Resources             `json:"resources,omitempty"`
+
+	// Extra holds fields returned by the API that this SDK version does not model.
+	// They are preserved when the sandbox is encoded again.
+	Extra map[string]json.RawMessage `json:"-"`
+
+	// ETag is the entity tag from the response headers of Get and Update, if the server sent one.
+	// Pass it back through UpdateSandboxRequest.ExpectUnchanged for conditional updates.
+	ETag string `json:"-"`
 }
 
 // Owner represents the sandbox owner
@@ -96,11 +107,15 @@ type SandboxStatus struct {
 	Substatus *string   `json:"substatus,omitempty"`
 	Reason    *string   `json:"reason,omitempty"`
 	UpdatedAt time.Time `json:"updated_at"`
+
+	Extra map[string]json.RawMessage `json:"-"` // Unmodelled fields, see Sandbox.Extra
 }
 
 // SandboxListResponse represents the response from listing sandboxes
 type SandboxListResponse struct {
 	Sandboxes []Sandbox `json:"sandboxes"`
+
+	Extra map[string]json.RawMessage `json:"-"` // Unmodelled fields, see Sandbox.Extra
 }
 
 // DeletionResponse represents the response from deleting a sandbox
@@ -108,10 +123,14 @@ type DeletionResponse struct {
 	SandboxID string `json:"sandbox_id"`
 	Status    string `json:"status"`
 	Note      string `json:"note"`
+
+	Extra map[string]json.RawMessage `json:"-"` // Unmodelled fields, see Sandbox.Extra
 }
 
 // TerminationResponse represents the response from terminating a sandbox
 type TerminationResponse struct {
 	SandboxID string `json:"sandbox_id"`
 	Status    string `json:"status"`
+
+	Extra map[string]json.RawMessage `json:"-"` // Unmodelled fields, see Sandbox.Extra
 }
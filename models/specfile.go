@@ -0,0 +1,18 @@
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// decodeStrict decodes JSON into v, rejecting unknown fields.
+// YAML files are converted to JSON by package specfile first.
+func decodeStrict(data []byte, v interface{}) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return fmt.Errorf("failed to parse json: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,154 @@
+// Package specfile reads and writes the files the models describe, such as preset and spec
+// files and fleet manifests, as JSON or YAML.
+//
+// YAML documents are mapped through the JSON field tags, so the same structs serve both
+// formats. It lives outside models so models depends on the standard library only.
+package specfile
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/scalebox/scalebox-sdk-golang/models"
+	"gopkg.in/yaml.v3"
+)
+
+// Format identifies the encoding of a file
+type Format string
+
+// Supported file formats
+const (
+	FormatJSON Format = "json"
+	FormatYAML Format = "yaml"
+)
+
+// FormatFromPath infers the file format from the file extension, defaulting to YAML
+func FormatFromPath(path string) Format {
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		return FormatJSON
+	}
+	return FormatYAML
+}
+
+// ToJSON converts a YAML document to JSON; JSON is returned unchanged
+func ToJSON(data []byte, format Format) ([]byte, error) {
+	if format != FormatYAML {
+		return data, nil
+	}
+	var doc interface{}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse YAML: %w", err)
+	}
+	converted, err := json.Marshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert YAML: %w", err)
+	}
+	return converted, nil
+}
+
+// Unmarshal decodes JSON or YAML into v, rejecting unknown fields
+func Unmarshal(data []byte, format Format, v interface{}) error {
+	data, err := ToJSON(data, format)
+	if err != nil {
+		return err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", format, err)
+	}
+	return nil
+}
+
+// Marshal encodes v as indented JSON or YAML using its JSON field tags
+func Marshal(v interface{}, format Format) ([]byte, error) {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if format != FormatYAML {
+		return append(data, '\n'), nil
+	}
+
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return nil, err
+	}
+	clearStyle(&node)
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&node); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// LoadFile reads a JSON or YAML file into v, choosing the format by extension
+func LoadFile(path string, v interface{}) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if err := Unmarshal(data, FormatFromPath(path), v); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return nil
+}
+
+// ParsePresets decodes a preset file from data, see models.ParsePresets
+func ParsePresets(data []byte, format Format) (*models.PresetFile, error) {
+	data, err := ToJSON(data, format)
+	if err != nil {
+		return nil, err
+	}
+	return models.ParsePresets(data)
+}
+
+// LoadPresets reads a preset file, choosing YAML or JSON by extension
+func LoadPresets(path string) (*models.PresetFile, error) {
+	return load(path, ParsePresets)
+}
+
+// ParseSandboxSpec decodes a spec file from data, see models.ParseSandboxSpec
+func ParseSandboxSpec(data []byte, format Format) (*models.SandboxSpec, error) {
+	data, err := ToJSON(data, format)
+	if err != nil {
+		return nil, err
+	}
+	return models.ParseSandboxSpec(data)
+}
+
+// LoadSandboxSpec reads a spec file, choosing YAML or JSON by extension
+func LoadSandboxSpec(path string) (*models.SandboxSpec, error) {
+	return load(path, ParseSandboxSpec)
+}
+
+func load[T any](path string, parse func([]byte, Format) (T, error)) (T, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	v, err := parse(data, FormatFromPath(path))
+	if err != nil {
+		return v, fmt.Errorf("%s: %w", path, err)
+	}
+	return v, nil
+}
+
+// clearStyle resets the JSON flow style so the document is written as block YAML
+func clearStyle(n *yaml.Node) {
+	n.Style = 0
+	for _, c := range n.Content {
+		clearStyle(c)
+	}
+}
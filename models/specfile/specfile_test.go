@@ -0,0 +1,110 @@
+package specfile
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/scalebox/scalebox-sdk-golang/models"
+)
+
+func TestLoadPresets(t *testing.T) {
+	yamlDoc := `
+sizes:
+  gpu-box: {cpu_count: 16, memory_mb: 65536, storage_gb: 100}
+presets:
+  ci-runner:
+    template: base
+    size: medium
+    timeout: 600
+    auto_pause: false
+    env_vars:
+      CI: "true"
+  trainer:
+    template: pytorch
+    size: gpu-box
+`
+	jsonDoc := `{"presets": {"ci-runner": {"template": "base", "size": "medium", "timeout": 600, "auto_pause": false, "env_vars": {"CI": "true"}}}}`
+
+	dir := t.TempDir()
+	for file, doc := range map[string]string{"presets.yaml": yamlDoc, "presets.json": jsonDoc} {
+		path := filepath.Join(dir, file)
+		if err := os.WriteFile(path, []byte(doc), 0o600); err != nil {
+			t.Fatal(err)
+		}
+
+		presets, err := LoadPresets(path)
+		if err != nil {
+			t.Fatalf("%s: LoadPresets failed: %v", file, err)
+		}
+		b, err := presets.NewSandbox("ci-runner", "job-42")
+		if err != nil {
+			t.Fatalf("%s: NewSandbox failed: %v", file, err)
+		}
+		req, err := b.Build()
+		if err != nil {
+			t.Fatalf("%s: Build failed: %v", file, err)
+		}
+		if req.Name != "job-42" || req.Template != "base" || req.Timeout != 600 || req.CPUCount != 2 {
+			t.Errorf("%s: unexpected request %+v", file, req)
+		}
+		if req.AutoPause == nil || *req.AutoPause || req.EnvVars["CI"] != "true" {
+			t.Errorf("%s: unexpected flags/env %+v", file, req)
+		}
+	}
+
+	presets, err := ParsePresets([]byte(yamlDoc), FormatYAML)
+	if err != nil {
+		t.Fatalf("ParsePresets failed: %v", err)
+	}
+	if size, ok := presets.Size("gpu-box"); !ok || size.CPUCount != 16 {
+		t.Errorf("Expected custom size gpu-box, got %+v", size)
+	}
+	if _, err := ParsePresets([]byte("presets:\n  x:\n    cpu: 2\n"), FormatYAML); err == nil {
+		t.Error("Expected error for unknown field")
+	}
+}
+
+func TestSandboxSpecRoundTrip(t *testing.T) {
+	body, err := os.ReadFile(filepath.Join("..", "testdata", "responses", "sandbox_get.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var sb models.Sandbox
+	if err := json.Unmarshal(body, &sb); err != nil {
+		t.Fatal(err)
+	}
+	spec := models.NewSandboxSpec(&sb)
+	spec.Spec.ObjectStorage = &models.ObjectStorageConfig{URI: "s3://bucket/data", MountPoint: "/mnt/data", AccessKey: "ak", SecretKey: "sk"}
+
+	for _, format := range []Format{FormatYAML, FormatJSON} {
+		data, err := Marshal(spec, format)
+		if err != nil {
+			t.Fatal(err)
+		}
+		parsed, err := ParseSandboxSpec(data, format)
+		if err != nil {
+			t.Fatalf("%s: %v\n%s", format, err, data)
+		}
+		if !reflect.DeepEqual(parsed, spec) {
+			t.Errorf("%s round trip changed the spec:\n got %+v\nwant %+v", format, parsed, spec)
+		}
+	}
+
+	if _, err := ParseSandboxSpec([]byte("api_version: scalebox/v0\nspec: {name: x}\n"), FormatYAML); err == nil {
+		t.Error("Expected unknown api_version to be rejected")
+	}
+	if _, err := ParseSandboxSpec([]byte("api_version: scalebox/v1\nspec: {nmae: x}\n"), FormatYAML); err == nil {
+		t.Error("Expected unknown spec field to be rejected")
+	}
+}
+
+func TestFormatFromPath(t *testing.T) {
+	for path, want := range map[string]Format{"a.json": FormatJSON, "a.JSON": FormatJSON, "a.yaml": FormatYAML, "a.yml": FormatYAML, "a": FormatYAML} {
+		if got := FormatFromPath(path); got != want {
+			t.Errorf("FormatFromPath(%q) = %s, want %s", path, got, want)
+		}
+	}
+}
@@ -0,0 +1,21 @@
+package models
+
+// Sandbox lifecycle statuses reported by the API
+const (
+	StatusStarting    = "starting"
+	StatusRunning     = "running"
+	StatusPausing     = "pausing"
+	StatusPaused      = "paused"
+	StatusTerminating = "terminating"
+	StatusTerminated  = "terminated"
+	StatusFailed      = "failed"
+)
+
+// IsTerminalStatus reports whether a sandbox in the given status can no longer run
+func IsTerminalStatus(status string) bool {
+	switch status {
+	case StatusTerminated, StatusFailed:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,406 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Update mask field names
+const (
+	UpdateFieldName                = "name"
+	UpdateFieldDescription         = "description"
+	UpdateFieldTimeout             = "timeout"
+	UpdateFieldMetadata            = "metadata"
+	UpdateFieldEnvVars             = "env_vars"
+	UpdateFieldAutoPause           = "auto_pause"
+	UpdateFieldAllowInternetAccess = "allow_internet_access"
+)
+
+// ClearableUpdateFields lists the fields that may be reset through UpdateSandboxRequest.Clear
+var ClearableUpdateFields = []string{
+	UpdateFieldDescription,
+	UpdateFieldMetadata,
+	UpdateFieldEnvVars,
+	UpdateFieldAutoPause,
+}
+
+var updateFields = []string{
+	UpdateFieldName,
+	UpdateFieldDescription,
+	UpdateFieldTimeout,
+	UpdateFieldMetadata,
+	UpdateFieldEnvVars,
+	UpdateFieldAutoPause,
+	UpdateFieldAllowInternetAccess,
+}
+
+// MapUpdateMode selects how a map field is updated
+type MapUpdateMode int
+
+const (
+	// UpdateMerge sets the given keys and deletes the removed ones, keeping all others (default)
+	UpdateMerge MapUpdateMode = iota
+	// UpdateReplace replaces the whole map
+	UpdateReplace
+)
+
+// ExpectUnchanged makes the update conditional on the sandbox not having changed since s was read.
+// It uses s.UpdatedAt and, when the server sent one, s.ETag.
+func (r *UpdateSandboxRequest) ExpectUnchanged(s *Sandbox) {
+	if !s.UpdatedAt.IsZero() {
+		r.ExpectedUpdatedAt = Ptr(s.UpdatedAt)
+	}
+	r.IfMatch = s.ETag
+}
+
+// Mask returns the field mask sent to the server: UpdateMask when set, otherwise one entry
+// per field that is set or cleared. Map entries updated in merge mode appear as
+// "metadata.<key>" and "env_vars.<name>"; replaced or cleared maps appear as "metadata"
+// and "env_vars".
+func (r UpdateSandboxRequest) Mask() []string {
+	if len(r.UpdateMask) > 0 {
+		return append([]string(nil), r.UpdateMask...)
+	}
+
+	var mask []string
+	if r.Name != nil {
+		mask = append(mask, UpdateFieldName)
+	}
+	if r.Description != nil {
+		mask = append(mask, UpdateFieldDescription)
+	}
+	if r.Timeout != nil {
+		mask = append(mask, UpdateFieldTimeout)
+	}
+	mask = append(mask, mapMask(UpdateFieldMetadata, r.Metadata, r.MetadataMode, r.RemoveMetadata)...)
+	mask = append(mask, mapMask(UpdateFieldEnvVars, r.EnvVars, r.EnvVarsMode, r.RemoveEnvVars)...)
+	if r.AutoPause != nil {
+		mask = append(mask, UpdateFieldAutoPause)
+	}
+	if r.AllowInternetAccess != nil {
+		mask = append(mask, UpdateFieldAllowInternetAccess)
+	}
+	for _, field := range r.Clear {
+		if !containsString(mask, field) {
+			mask = append(mask, field)
+		}
+	}
+	return mask
+}
+
+func mapMask(field string, set map[string]string, mode MapUpdateMode, remove []string) []string {
+	if mode == UpdateReplace {
+		if set == nil {
+			return nil
+		}
+		return []string{field}
+	}
+	keys := sortedKeys(set)
+	for _, k := range remove {
+		if _, ok := set[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	mask := make([]string, len(keys))
+	for i, k := range keys {
+		mask[i] = field + "." + k
+	}
+	return mask
+}
+
+// MarshalJSON encodes the PATCH body: the set fields, null for cleared fields, map
+// entries with null for removed keys in merge mode, and the update mask
+func (r UpdateSandboxRequest) MarshalJSON() ([]byte, error) {
+	body := make(map[string]interface{})
+	if r.Name != nil {
+		body[UpdateFieldName] = *r.Name
+	}
+	if r.Description != nil {
+		body[UpdateFieldDescription] = *r.Description
+	}
+	if r.Timeout != nil {
+		body[UpdateFieldTimeout] = *r.Timeout
+	}
+	if m := mapPatch(r.Metadata, r.MetadataMode, r.RemoveMetadata); m != nil {
+		body[UpdateFieldMetadata] = m
+	}
+	if m := mapPatch(r.EnvVars, r.EnvVarsMode, r.RemoveEnvVars); m != nil {
+		body[UpdateFieldEnvVars] = m
+	}
+	if r.AutoPause != nil {
+		body[UpdateFieldAutoPause] = *r.AutoPause
+	}
+	if r.AllowInternetAccess != nil {
+		body[UpdateFieldAllowInternetAccess] = *r.AllowInternetAccess
+	}
+	for _, field := range r.Clear {
+		body[field] = nil
+	}
+	if r.ExpectedUpdatedAt != nil {
+		body["expected_updated_at"] = r.ExpectedUpdatedAt.Format(time.RFC3339Nano)
+	}
+	body["update_mask"] = r.Mask()
+	return json.Marshal(body)
+}
+
+func mapPatch(set map[string]string, mode MapUpdateMode, remove []string) map[string]interface{} {
+	if set == nil && (mode == UpdateReplace || len(remove) == 0) {
+		return nil
+	}
+	m := make(map[string]interface{}, len(set)+len(remove))
+	if mode != UpdateReplace {
+		for _, k := range remove {
+			m[k] = nil
+		}
+	}
+	for k, v := range set {
+		m[k] = v
+	}
+	return m
+}
+
+// UnmarshalJSON decodes a PATCH body produced by MarshalJSON, interpreting it through its update mask
+func (r *UpdateSandboxRequest) UnmarshalJSON(data []byte) error {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	*r = UpdateSandboxRequest{}
+
+	var mask []string
+	if m, ok := raw["update_mask"]; ok {
+		if err := json.Unmarshal(m, &mask); err != nil {
+			return fmt.Errorf("update_mask: %w", err)
+		}
+	}
+	if t, ok := raw["expected_updated_at"]; ok && !isJSONNull(t) {
+		var at time.Time
+		if err := json.Unmarshal(t, &at); err != nil {
+			return fmt.Errorf("expected_updated_at: %w", err)
+		}
+		r.ExpectedUpdatedAt = &at
+	}
+
+	for _, path := range mask {
+		field, key, isKey := strings.Cut(path, ".")
+		value, present := raw[field]
+		cleared := !present || isJSONNull(value)
+
+		var err error
+		switch field {
+		case UpdateFieldName:
+			err = decodeUpdateValue(value, cleared, &r.Name)
+		case UpdateFieldDescription:
+			err = decodeUpdateValue(value, cleared, &r.Description)
+		case UpdateFieldTimeout:
+			err = decodeUpdateValue(value, cleared, &r.Timeout)
+		case UpdateFieldAutoPause:
+			err = decodeUpdateValue(value, cleared, &r.AutoPause)
+		case UpdateFieldAllowInternetAccess:
+			err = decodeUpdateValue(value, cleared, &r.AllowInternetAccess)
+		case UpdateFieldMetadata:
+			err = decodeMapUpdate(value, cleared, key, isKey, &r.Metadata, &r.MetadataMode, &r.RemoveMetadata)
+		case UpdateFieldEnvVars:
+			err = decodeMapUpdate(value, cleared, key, isKey, &r.EnvVars, &r.EnvVarsMode, &r.RemoveEnvVars)
+		default:
+			return fmt.Errorf("update_mask: unknown field %q", path)
+		}
+		if err == errFieldCleared {
+			if !containsString(r.Clear, field) {
+				r.Clear = append(r.Clear, field)
+			}
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	return nil
+}
+
+var errFieldCleared = errors.New("field cleared")
+
+func decodeUpdateValue[T any](value json.RawMessage, cleared bool, dst **T) error {
+	if cleared {
+		return errFieldCleared
+	}
+	var v T
+	if err := json.Unmarshal(value, &v); err != nil {
+		return err
+	}
+	*dst = &v
+	return nil
+}
+
+func decodeMapUpdate(value json.RawMessage, cleared bool, key string, isKey bool, set *map[string]string, mode *MapUpdateMode, remove *[]string) error {
+	if !isKey {
+		if cleared {
+			return errFieldCleared
+		}
+		var m map[string]string
+		if err := json.Unmarshal(value, &m); err != nil {
+			return err
+		}
+		if m == nil {
+			m = map[string]string{}
+		}
+		*set = m
+		*mode = UpdateReplace
+		return nil
+	}
+
+	var entries map[string]*string
+	if !cleared {
+		if err := json.Unmarshal(value, &entries); err != nil {
+			return err
+		}
+	}
+	if v := entries[key]; v != nil {
+		if *set == nil {
+			*set = make(map[string]string)
+		}
+		(*set)[key] = *v
+	} else {
+		*remove = append(*remove, key)
+	}
+	return nil
+}
+
+func isJSONNull(raw json.RawMessage) bool {
+	return strings.TrimSpace(string(raw)) == "null"
+}
+
+// Validate checks the update for problems the server would reject.
+// It returns a *ValidationError listing every invalid field, or nil.
+func (r UpdateSandboxRequest) Validate() error {
+	v := &validator{}
+
+	if len(r.Mask()) == 0 {
+		v.addf("update_mask", "no fields to update")
+	}
+	for _, path := range r.UpdateMask {
+		field, _, _ := strings.Cut(path, ".")
+		if !containsString(updateFields, field) {
+			v.addf("update_mask", "unknown field %q", path)
+		}
+	}
+
+	if r.Name != nil && strings.TrimSpace(*r.Name) == "" {
+		v.addf(UpdateFieldName, "must not be empty")
+	}
+	if r.Timeout != nil && *r.Timeout <= 0 {
+		v.addf(UpdateFieldTimeout, "must be positive, got %d", *r.Timeout)
+	}
+
+	validateMetadata(v, UpdateFieldMetadata, r.Metadata)
+	for _, name := range sortedKeys(r.EnvVars) {
+		if !envVarNamePattern.MatchString(name) {
+			v.addf("env_vars."+name, "invalid environment variable name")
+		}
+	}
+	validateRemovals(v, UpdateFieldMetadata, r.Metadata, r.MetadataMode, r.RemoveMetadata)
+	validateRemovals(v, UpdateFieldEnvVars, r.EnvVars, r.EnvVarsMode, r.RemoveEnvVars)
+
+	set := map[string]bool{
+		UpdateFieldDescription: r.Description != nil,
+		UpdateFieldMetadata:    r.Metadata != nil || len(r.RemoveMetadata) > 0,
+		UpdateFieldEnvVars:     r.EnvVars != nil || len(r.RemoveEnvVars) > 0,
+		UpdateFieldAutoPause:   r.AutoPause != nil,
+	}
+	for _, field := range r.Clear {
+		switch {
+		case !containsString(ClearableUpdateFields, field):
+			v.addf(field, "cannot be cleared")
+		case set[field]:
+			v.addf(field, "cannot be both set and cleared")
+		}
+	}
+
+	return v.err()
+}
+
+func validateRemovals(v *validator, field string, set map[string]string, mode MapUpdateMode, remove []string) {
+	if len(remove) == 0 {
+		return
+	}
+	if mode == UpdateReplace {
+		v.addf(field, "keys can only be removed in merge mode")
+		return
+	}
+	for _, k := range remove {
+		if _, ok := set[k]; ok {
+			v.addf(field+"."+k, "cannot be both set and removed")
+		}
+	}
+}
+
+// ApplyTo applies the update to s the way the server does. It is intended for fakes and previews
+// and does not check preconditions.
+func (r UpdateSandboxRequest) ApplyTo(s *Sandbox) {
+	for _, field := range r.Clear {
+		switch field {
+		case UpdateFieldDescription:
+			s.Description = nil
+		case UpdateFieldMetadata:
+			s.Metadata = nil
+		case UpdateFieldEnvVars:
+			s.EnvVars = nil
+		case UpdateFieldAutoPause:
+			s.AutoPause = false
+		}
+	}
+	if r.Name != nil {
+		s.Name = *r.Name
+	}
+	if r.Description != nil {
+		s.Description = Ptr(*r.Description)
+	}
+	if r.Timeout != nil {
+		s.Timeout = *r.Timeout
+	}
+	s.Metadata = applyMapUpdate(s.Metadata, r.Metadata, r.MetadataMode, r.RemoveMetadata)
+	s.EnvVars = applyMapUpdate(s.EnvVars, r.EnvVars, r.EnvVarsMode, r.RemoveEnvVars)
+	if r.AutoPause != nil {
+		s.AutoPause = *r.AutoPause
+	}
+	if r.AllowInternetAccess != nil {
+		s.AllowInternetAccess = *r.AllowInternetAccess
+	}
+}
+
+func applyMapUpdate(current, set map[string]string, mode MapUpdateMode, remove []string) map[string]string {
+	if mode == UpdateReplace {
+		if set == nil {
+			return current
+		}
+		return cloneStringMap(set)
+	}
+	if len(set) == 0 && len(remove) == 0 {
+		return current
+	}
+	m := cloneStringMap(current)
+	if m == nil {
+		m = make(map[string]string, len(set))
+	}
+	for _, k := range remove {
+		delete(m, k)
+	}
+	for k, v := range set {
+		m[k] = v
+	}
+	return m
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
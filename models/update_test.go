@@ -0,0 +1,131 @@
+package models
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestUpdateMaskAndWireFormat(t *testing.T) {
+	req := UpdateSandboxRequest{
+		Name:           Ptr("renamed"),
+		Metadata:       map[string]string{"team": "ml", "scalebox.pool.state": "idle"},
+		RemoveMetadata: []string{"owner"},
+		EnvVars:        map[string]string{"MODE": "ci"},
+		EnvVarsMode:    UpdateReplace,
+		Clear:          []string{UpdateFieldDescription, UpdateFieldAutoPause},
+	}
+
+	wantMask := []string{
+		"name",
+		"metadata.owner", "metadata.scalebox.pool.state", "metadata.team",
+		"env_vars",
+		"description", "auto_pause",
+	}
+	if got := req.Mask(); !reflect.DeepEqual(got, wantMask) {
+		t.Errorf("Mask() = %v, want %v", got, wantMask)
+	}
+
+	data, err := json.Marshal(req)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	var wire map[string]interface{}
+	json.Unmarshal(data, &wire)
+	if v, ok := wire["description"]; !ok || v != nil {
+		t.Errorf("Expected cleared description to be sent as null, got %v", wire["description"])
+	}
+	if md := wire["metadata"].(map[string]interface{}); md["owner"] != nil || md["team"] != "ml" {
+		t.Errorf("Unexpected metadata patch %v", md)
+	}
+	if _, ok := wire["timeout"]; ok {
+		t.Error("Expected unset timeout to be omitted")
+	}
+
+	// The wire format decodes back to an equivalent request
+	var decoded UpdateSandboxRequest
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if !reflect.DeepEqual(decoded.Mask(), wantMask) {
+		t.Errorf("Round-tripped mask %v, want %v", decoded.Mask(), wantMask)
+	}
+	if *decoded.Name != "renamed" || decoded.EnvVarsMode != UpdateReplace || !reflect.DeepEqual(decoded.RemoveMetadata, []string{"owner"}) {
+		t.Errorf("Unexpected round trip: %+v", decoded)
+	}
+}
+
+func TestUpdateApplyTo(t *testing.T) {
+	sandbox := Sandbox{
+		Name:        "old",
+		Description: Ptr("desc"),
+		AutoPause:   true,
+		Metadata:    map[string]string{"owner": "a", "team": "web"},
+		EnvVars:     map[string]string{"OLD": "1"},
+	}
+	UpdateSandboxRequest{
+		Name:           Ptr("new"),
+		Timeout:        Ptr(600),
+		Metadata:       map[string]string{"team": "ml"},
+		RemoveMetadata: []string{"owner"},
+		EnvVars:        map[string]string{"MODE": "ci"},
+		EnvVarsMode:    UpdateReplace,
+		Clear:          []string{UpdateFieldDescription, UpdateFieldAutoPause},
+	}.ApplyTo(&sandbox)
+
+	if sandbox.Name != "new" || sandbox.Timeout != 600 || sandbox.Description != nil || sandbox.AutoPause {
+		t.Errorf("Unexpected scalar fields: %+v", sandbox)
+	}
+	if !reflect.DeepEqual(sandbox.Metadata, map[string]string{"team": "ml"}) {
+		t.Errorf("Expected merged metadata, got %v", sandbox.Metadata)
+	}
+	if !reflect.DeepEqual(sandbox.EnvVars, map[string]string{"MODE": "ci"}) {
+		t.Errorf("Expected replaced env vars, got %v", sandbox.EnvVars)
+	}
+}
+
+func TestUpdateValidate(t *testing.T) {
+	if err := (UpdateSandboxRequest{Timeout: Ptr(60)}).Validate(); err != nil {
+		t.Errorf("Expected valid update, got %v", err)
+	}
+
+	err := UpdateSandboxRequest{
+		Name:           Ptr(" "),
+		Description:    Ptr("set"),
+		Metadata:       map[string]string{"k": "v"},
+		RemoveMetadata: []string{"k"},
+		EnvVars:        map[string]string{"1BAD": "x"},
+		Clear:          []string{UpdateFieldDescription, UpdateFieldName},
+		UpdateMask:     []string{"bogus"},
+	}.Validate()
+	verr, ok := err.(*ValidationError)
+	if !ok {
+		t.Fatalf("Expected *ValidationError, got %T (%v)", err, err)
+	}
+	for _, field := range []string{"name", "description", "metadata.k", "env_vars.1BAD", "update_mask"} {
+		if !verr.HasField(field) {
+			t.Errorf("Expected error for %s, got %v", field, verr.Errors)
+		}
+	}
+
+	if err := (UpdateSandboxRequest{}).Validate(); err == nil {
+		t.Error("Expected empty update to be rejected")
+	}
+}
+
+func TestExpectUnchanged(t *testing.T) {
+	at := time.Date(2026, 1, 2, 3, 4, 5, 6, time.UTC)
+	var req UpdateSandboxRequest
+	req.ExpectUnchanged(&Sandbox{UpdatedAt: at, ETag: `"abc"`})
+	if req.ExpectedUpdatedAt == nil || !req.ExpectedUpdatedAt.Equal(at) || req.IfMatch != `"abc"` {
+		t.Fatalf("Unexpected preconditions: %v %q", req.ExpectedUpdatedAt, req.IfMatch)
+	}
+
+	data, _ := json.Marshal(UpdateSandboxRequest{Timeout: Ptr(60), ExpectedUpdatedAt: req.ExpectedUpdatedAt})
+	var decoded UpdateSandboxRequest
+	json.Unmarshal(data, &decoded)
+	if decoded.ExpectedUpdatedAt == nil || !decoded.ExpectedUpdatedAt.Equal(at) {
+		t.Errorf("Expected nanosecond precision to survive, got %v", decoded.ExpectedUpdatedAt)
+	}
+}
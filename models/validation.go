@@ -0,0 +1,236 @@
+package models
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Smallest sizes accepted by Validate. A zero CPU, memory or storage value is left to the
+// server default and is not checked. Maximums depend on the account and are left to the server.
+const (
+	MinCPUCount  = 1
+	MinMemoryMB  = 128
+	MinStorageGB = 1
+)
+
+var (
+	envVarNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+	s3BucketPattern   = regexp.MustCompile(`^[a-z0-9][a-z0-9.-]{1,61}[a-z0-9]$`)
+)
+
+// validPortProtocols lists the port protocols accepted in CustomPorts (compared case-insensitively)
+var validPortProtocols = []string{"tcp", "udp", "http", "https", "grpc"}
+
+// FieldError describes a single invalid field
+type FieldError struct {
+	Field   string // JSON path of the field, e.g. "custom_ports[1].port"
+	Message string
+}
+
+func (e FieldError) String() string {
+	return e.Field + ": " + e.Message
+}
+
+// ValidationError lists every problem found in a request
+type ValidationError struct {
+	Errors []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, len(e.Errors))
+	for i, fe := range e.Errors {
+		msgs[i] = fe.String()
+	}
+	return "invalid request: " + strings.Join(msgs, "; ")
+}
+
+// HasField reports whether the given field has a validation error
+func (e *ValidationError) HasField(field string) bool {
+	for _, fe := range e.Errors {
+		if fe.Field == field {
+			return true
+		}
+	}
+	return false
+}
+
+type validator struct {
+	errs []FieldError
+}
+
+func (v *validator) addf(field, format string, args ...interface{}) {
+	v.errs = append(v.errs, FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+func (v *validator) minCheck(field string, value, lo int) {
+	if value != 0 && value < lo {
+		v.addf(field, "must be at least %d, got %d", lo, value)
+	}
+}
+
+func (v *validator) err() error {
+	if len(v.errs) == 0 {
+		return nil
+	}
+	return &ValidationError{Errors: v.errs}
+}
+
+// Validate checks the request for problems the server would reject.
+// It returns a *ValidationError listing every invalid field, or nil.
+func (r CreateSandboxRequest) Validate() error {
+	v := &validator{}
+
+	v.minCheck("cpu_count", r.CPUCount, MinCPUCount)
+	v.minCheck("memory_mb", r.MemoryMB, MinMemoryMB)
+	v.minCheck("storage_gb", r.StorageGB, MinStorageGB)
+	if r.Timeout < 0 {
+		v.addf("timeout", "must not be negative, got %d", r.Timeout)
+	}
+
+	validateMetadata(v, "metadata", r.Metadata)
+
+	for _, name := range sortedKeys(r.EnvVars) {
+		if !envVarNamePattern.MatchString(name) {
+			v.addf("env_vars."+name, "invalid environment variable name")
+		}
+	}
+
+	if r.ObjectStorage != nil {
+		validateObjectStorage(v, r.ObjectStorage)
+	}
+
+	seen := make(map[int32]int, len(r.CustomPorts))
+	for i, p := range r.CustomPorts {
+		field := fmt.Sprintf("custom_ports[%d]", i)
+		if p.Port < 1 || p.Port > 65535 {
+			v.addf(field+".port", "must be between 1 and 65535, got %d", p.Port)
+		} else if j, dup := seen[p.Port]; dup {
+			v.addf(field+".port", "duplicates custom_ports[%d]", j)
+		} else {
+			seen[p.Port] = i
+		}
+		if p.ServicePort != 0 && (p.ServicePort < 1 || p.ServicePort > 65535) {
+			v.addf(field+".service_port", "must be between 1 and 65535, got %d", p.ServicePort)
+		}
+		if p.Protocol != "" && !isValidPortProtocol(p.Protocol) {
+			v.addf(field+".protocol", "must be one of %s, got %q", strings.Join(validPortProtocols, ", "), p.Protocol)
+		}
+	}
+
+	if r.NetProxyCountry != "" && !IsISO3166Alpha2(r.NetProxyCountry) {
+		v.addf("net_proxy_country", "must be an upper-case ISO 3166-1 alpha-2 country code, got %q", r.NetProxyCountry)
+	}
+
+	return v.err()
+}
+
+// Validate checks the list options for problems the server would reject.
+// It returns a *ValidationError listing every invalid option, or nil.
+func (o ListSandboxesOptions) Validate() error {
+	v := &validator{}
+
+	if o.SortOrder != "" && o.SortOrder != SortAsc && o.SortOrder != SortDesc {
+		v.addf("sort_order", "must be %q or %q, got %q", SortAsc, SortDesc, o.SortOrder)
+	}
+	if o.Limit < 0 {
+		v.addf("limit", "must not be negative, got %d", o.Limit)
+	}
+	if o.Offset < 0 {
+		v.addf("offset", "must not be negative, got %d", o.Offset)
+	}
+	for i, st := range o.Statuses {
+		if st == "" {
+			v.addf(fmt.Sprintf("status[%d]", i), "must not be empty")
+		}
+	}
+	if o.CreatedAfter != nil && o.CreatedBefore != nil && !o.CreatedAfter.Before(*o.CreatedBefore) {
+		v.addf("created_before", "must be after created_after")
+	}
+	if o.MinCPUCount < 0 {
+		v.addf("min_cpu_count", "must not be negative, got %d", o.MinCPUCount)
+	}
+	if o.MinMemoryMB < 0 {
+		v.addf("min_memory_mb", "must not be negative, got %d", o.MinMemoryMB)
+	}
+
+	return v.err()
+}
+
+// Validate checks the metrics options for problems the server would reject.
+// It returns a *ValidationError listing every invalid option, or nil.
+func (o GetSandboxMetricsOptions) Validate() error {
+	v := &validator{}
+	if o.Start != nil && o.End != nil && !o.Start.Before(*o.End) {
+		v.addf("end", "must be after start")
+	}
+	if o.Step != nil && *o.Step <= 0 {
+		v.addf("step", "must be positive, got %d", *o.Step)
+	}
+	if o.TimeFormat != "" && o.TimeFormat != TimeFormatRFC3339 && o.TimeFormat != TimeFormatUnix {
+		v.addf("time_format", "must be %q or %q, got %q", TimeFormatRFC3339, TimeFormatUnix, o.TimeFormat)
+	}
+	return v.err()
+}
+
+func validateMetadata(v *validator, field string, metadata map[string]string) {
+	if _, ok := metadata[""]; ok {
+		v.addf(field, "keys must not be empty")
+	}
+}
+
+func validateObjectStorage(v *validator, cfg *ObjectStorageConfig) {
+	const prefix = "s3://"
+	switch {
+	case cfg.URI == "":
+		v.addf("object_storage.uri", "is required")
+	case !strings.HasPrefix(cfg.URI, prefix):
+		v.addf("object_storage.uri", "must have the form s3://bucket/path, got %q", cfg.URI)
+	default:
+		bucket, objectPath, _ := strings.Cut(strings.TrimPrefix(cfg.URI, prefix), "/")
+		if !s3BucketPattern.MatchString(bucket) {
+			v.addf("object_storage.uri", "invalid bucket name %q", bucket)
+		}
+		if objectPath == "" {
+			v.addf("object_storage.uri", "must include an object path after the bucket, got %q", cfg.URI)
+		}
+	}
+
+	if cfg.MountPoint == "" {
+		v.addf("object_storage.mount_point", "is required")
+	} else if !path.IsAbs(cfg.MountPoint) {
+		v.addf("object_storage.mount_point", "must be an absolute path, got %q", cfg.MountPoint)
+	}
+	if cfg.AccessKey == "" {
+		v.addf("object_storage.access_key", "is required")
+	}
+	if cfg.SecretKey == "" {
+		v.addf("object_storage.secret_key", "is required")
+	}
+	if cfg.Endpoint != "" {
+		if u, err := url.Parse(cfg.Endpoint); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			v.addf("object_storage.endpoint", "must be an http(s) URL, got %q", cfg.Endpoint)
+		}
+	}
+}
+
+func isValidPortProtocol(protocol string) bool {
+	for _, p := range validPortProtocols {
+		if strings.EqualFold(p, protocol) {
+			return true
+		}
+	}
+	return false
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
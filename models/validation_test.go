@@ -0,0 +1,184 @@
+package models
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestValidateAcceptsValidRequest(t *testing.T) {
+	req := CreateSandboxRequest{
+		Name:      "ok",
+		Template:  "base",
+		CPUCount:  2,
+		MemoryMB:  512,
+		StorageGB: 10,
+		Metadata:  map[string]string{"team": "ml"},
+		EnvVars:   map[string]string{"PATH_EXTRA": "/opt/bin", "_X1": "y"},
+		ObjectStorage: &ObjectStorageConfig{
+			URI:        "s3://my-bucket/data/set",
+			MountPoint: "/mnt/data",
+			AccessKey:  "ak",
+			SecretKey:  "sk",
+			Endpoint:   "https://s3.example.com",
+		},
+		CustomPorts: []PortConfig{
+			{Port: 8080, Protocol: "HTTP"},
+			{Port: 9000, ServicePort: 19000, Protocol: "tcp"},
+		},
+		NetProxyCountry: "US",
+	}
+	if err := req.Validate(); err != nil {
+		t.Fatalf("Expected valid request, got %v", err)
+	}
+
+	// Zero resources are left to server defaults
+	if err := (CreateSandboxRequest{Name: "defaults"}).Validate(); err != nil {
+		t.Fatalf("Expected zero values to be valid, got %v", err)
+	}
+}
+
+func TestValidateReportsEveryProblem(t *testing.T) {
+	req := CreateSandboxRequest{
+		CPUCount:  -2,
+		MemoryMB:  64,
+		StorageGB: -1,
+		Timeout:   -5,
+		Metadata:  map[string]string{"": "x"},
+		EnvVars:   map[string]string{"1BAD": "x", "BAD-NAME": "y"},
+		ObjectStorage: &ObjectStorageConfig{
+			URI:        "https://bucket/path",
+			MountPoint: "relative/dir",
+		},
+		CustomPorts: []PortConfig{
+			{Port: 8080},
+			{Port: 8080},
+			{Port: 70000, Protocol: "sctp"},
+		},
+		NetProxyCountry: "XX",
+	}
+
+	err := req.Validate()
+	var verr *ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("Expected *ValidationError, got %T (%v)", err, err)
+	}
+
+	expected := []string{
+		"cpu_count",
+		"memory_mb",
+		"storage_gb",
+		"timeout",
+		"metadata",
+		"env_vars.1BAD",
+		"env_vars.BAD-NAME",
+		"object_storage.uri",
+		"object_storage.mount_point",
+		"object_storage.access_key",
+		"object_storage.secret_key",
+		"custom_ports[1].port",
+		"custom_ports[2].port",
+		"custom_ports[2].protocol",
+		"net_proxy_country",
+	}
+	for _, field := range expected {
+		if !verr.HasField(field) {
+			t.Errorf("Expected error for field %s, got %v", field, verr)
+		}
+	}
+	if len(verr.Errors) != len(expected) {
+		t.Errorf("Expected %d errors, got %d: %v", len(expected), len(verr.Errors), verr)
+	}
+}
+
+func TestValidateObjectStorageURI(t *testing.T) {
+	tests := []struct {
+		uri   string
+		valid bool
+	}{
+		{"s3://bucket/path", true},
+		{"s3://my.bucket-1/a/b/c", true},
+		{"s3://bucket", false},
+		{"s3://bucket/", false},
+		{"s3://Bucket/path", false},
+		{"s3:///path", false},
+		{"gs://bucket/path", false},
+	}
+
+	for _, tt := range tests {
+		req := CreateSandboxRequest{ObjectStorage: &ObjectStorageConfig{
+			URI: tt.uri, MountPoint: "/mnt", AccessKey: "ak", SecretKey: "sk",
+		}}
+		err := req.Validate()
+		if (err == nil) != tt.valid {
+			t.Errorf("URI %q: expected valid=%v, got %v", tt.uri, tt.valid, err)
+		}
+	}
+}
+
+func TestValidateListOptions(t *testing.T) {
+	if err := (ListSandboxesOptions{SortBy: SortByMemoryMB, SortOrder: SortDesc, Statuses: []string{"running"}}).Validate(); err != nil {
+		t.Errorf("Expected valid options, got %v", err)
+	}
+
+	after := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	before := after.Add(-time.Hour)
+	opts := ListSandboxesOptions{
+		SortBy:        "size",
+		SortOrder:     "up",
+		Limit:         -1,
+		Statuses:      []string{"running", ""},
+		CreatedAfter:  &after,
+		CreatedBefore: &before,
+		MinMemoryMB:   -512,
+	}
+	var verr *ValidationError
+	if err := opts.Validate(); !errors.As(err, &verr) {
+		t.Fatalf("Expected *ValidationError, got %v", err)
+	}
+	fields := map[string]bool{}
+	for _, fe := range verr.Errors {
+		fields[fe.Field] = true
+	}
+	for _, field := range []string{"sort_order", "limit", "status[1]", "created_before", "min_memory_mb"} {
+		if !fields[field] {
+			t.Errorf("Expected an error for %s, got %v", field, verr)
+		}
+	}
+	// Unknown sort fields are left to the server
+	if fields["sort_by"] {
+		t.Errorf("Expected no error for unknown sort_by, got %v", verr)
+	}
+	if IsKnownSortField("size") || !IsKnownSortField(SortByName) {
+		t.Error("Unexpected IsKnownSortField result")
+	}
+}
+
+func TestValidateMetricsOptions(t *testing.T) {
+	start := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	end := start.Add(time.Hour)
+	if err := (GetSandboxMetricsOptions{Start: &start, End: &end, Step: Ptr(5), TimeFormat: TimeFormatUnix}).Validate(); err != nil {
+		t.Errorf("Expected valid options, got %v", err)
+	}
+
+	var verr *ValidationError
+	err := GetSandboxMetricsOptions{Start: &end, End: &start, Step: Ptr(0), TimeFormat: "iso"}.Validate()
+	if !errors.As(err, &verr) {
+		t.Fatalf("Expected *ValidationError, got %v", err)
+	}
+	for _, field := range []string{"end", "step", "time_format"} {
+		if !verr.HasField(field) {
+			t.Errorf("Expected an error for %s, got %v", field, verr)
+		}
+	}
+}
+
+func TestStatusFilter(t *testing.T) {
+	got := ListSandboxesOptions{Status: "running", Statuses: []string{"paused", "running", ""}}.StatusFilter()
+	if len(got) != 2 || got[0] != "running" || got[1] != "paused" {
+		t.Errorf("Expected [running paused], got %v", got)
+	}
+	if got := (ListSandboxesOptions{}).StatusFilter(); got != nil {
+		t.Errorf("Expected no statuses, got %v", got)
+	}
+}
@@ -0,0 +1,644 @@
+// Package pool keeps pre-created sandboxes warm and hands them out as leases.
+//
+// Pool membership and lease state are recorded in sandbox metadata, so sandboxes
+// left behind by a crashed process can be found and reclaimed by another one.
+package pool
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/scalebox/scalebox-sdk-golang/api/sandboxes"
+	"github.com/scalebox/scalebox-sdk-golang/models"
+)
+
+// Metadata keys used to track pool membership and leases
+const (
+	MetadataPool     = "scalebox.pool"
+	MetadataInstance = "scalebox.pool.instance"
+	MetadataState    = "scalebox.pool.state"
+	MetadataLease    = "scalebox.pool.lease"
+	MetadataExpires  = "scalebox.pool.expires"
+)
+
+// Pool member states stored under MetadataState
+const (
+	StateIdle   = "idle"
+	StateLeased = "leased"
+)
+
+// Pool defaults
+const (
+	DefaultLeaseTTL             = time.Hour
+	DefaultReplenishInterval    = 10 * time.Second
+	DefaultReadyTimeout         = 2 * time.Minute
+	DefaultMaxConcurrentCreates = 4
+)
+
+// Pool errors
+var (
+	ErrClosed          = errors.New("pool: closed")
+	ErrLeaseReleased   = errors.New("pool: lease already released")
+	errSandboxUnusable = errors.New("pool: sandbox is no longer usable")
+)
+
+// RecyclePolicy decides what happens to a sandbox when its lease is released
+type RecyclePolicy int
+
+const (
+	// DestroyOnRelease deletes sandboxes after a single use
+	DestroyOnRelease RecyclePolicy = iota
+	// ReuseOnRelease returns sandboxes to the pool until MaxUses or MaxAge is reached
+	ReuseOnRelease
+)
+
+// Options configures a Pool
+type Options struct {
+	Name                 string                      // Pool name stored in sandbox metadata (required)
+	Spec                 models.CreateSandboxRequest // Spec used to create every pooled sandbox
+	Size                 int                         // Number of warm sandboxes to keep ready
+	MaxSize              int                         // Optional: cap on warm + leased + creating sandboxes
+	PauseIdle            bool                        // Pause warm sandboxes and resume them on Acquire
+	Recycle              RecyclePolicy               // What Release does with the sandbox, defaults to DestroyOnRelease
+	MaxUses              int                         // Optional: destroy after this many leases when reusing
+	MaxAge               time.Duration               // Optional: destroy sandboxes older than this
+	LeaseTTL             time.Duration               // Lifetime of the lease recorded in metadata, defaults to 1 hour
+	ReplenishInterval    time.Duration               // How often Run tops up the pool, defaults to 10 seconds
+	ReadyTimeout         time.Duration               // How long to wait for a sandbox to reach running, defaults to 2 minutes
+	PollInterval         time.Duration               // Status polling interval, defaults to sandboxes.DefaultWaitInterval
+	MaxConcurrentCreates int                         // Parallel creations during replenishment, defaults to 4
+}
+
+// Stats is a snapshot of pool activity
+type Stats struct {
+	Idle      int   // Warm sandboxes ready to lease
+	Leased    int   // Sandboxes currently handed out
+	Creating  int   // Sandboxes being created
+	Created   int64 // Sandboxes created since the pool started
+	Destroyed int64 // Sandboxes destroyed since the pool started
+	Acquired  int64 // Successful Acquire calls
+	Misses    int64 // Acquire calls that had to create a sandbox on demand
+	Failures  int64 // Failed creations, leases and recycles
+}
+
+type member struct {
+	sandboxID string
+	metadata  map[string]string
+	createdAt time.Time
+	expires   time.Time
+	uses      int
+}
+
+// Lease is a sandbox handed out by Acquire. It must be returned with Release or Discard.
+type Lease struct {
+	ID         string
+	Sandbox    *models.Sandbox
+	AcquiredAt time.Time
+
+	pool   *Pool
+	member *member
+}
+
+// Release returns the sandbox to the pool or destroys it, depending on the recycle policy
+func (l *Lease) Release(ctx context.Context) error {
+	return l.pool.release(ctx, l, false)
+}
+
+// Discard destroys the sandbox regardless of the recycle policy
+func (l *Lease) Discard(ctx context.Context) error {
+	return l.pool.release(ctx, l, true)
+}
+
+// Renew extends the lease expiry recorded in the sandbox metadata
+func (l *Lease) Renew(ctx context.Context) error {
+	l.pool.mu.Lock()
+	_, ok := l.pool.leased[l.ID]
+	l.pool.mu.Unlock()
+	if !ok {
+		return ErrLeaseReleased
+	}
+	return l.pool.setState(ctx, l.member, StateLeased, l.ID)
+}
+
+// Pool keeps a number of sandboxes of a single spec warm
+type Pool struct {
+	client   *sandboxes.Client
+	opts     Options
+	instance string
+
+	mu       sync.Mutex
+	idle     []*member
+	leased   map[string]*member
+	creating int
+	renewing int // Idle members taken out of idle while their lease expiry is renewed
+	leasing  int // Members taken out of idle or just created, until lease returns
+	stats    Stats
+	closed   bool
+	notify   chan struct{}
+
+	wake     chan struct{}
+	creators sync.WaitGroup
+}
+
+// New creates a pool. Call Run to keep it replenished in the background.
+func New(c *sandboxes.Client, opts Options) (*Pool, error) {
+	if opts.Name == "" {
+		return nil, errors.New("pool: name is required")
+	}
+	if opts.Size < 0 || opts.MaxSize < 0 {
+		return nil, errors.New("pool: size must not be negative")
+	}
+	if opts.MaxSize > 0 && opts.Size > opts.MaxSize {
+		return nil, fmt.Errorf("pool: size %d exceeds max size %d", opts.Size, opts.MaxSize)
+	}
+	if opts.LeaseTTL <= 0 {
+		opts.LeaseTTL = DefaultLeaseTTL
+	}
+	if opts.ReplenishInterval <= 0 {
+		opts.ReplenishInterval = DefaultReplenishInterval
+	}
+	if opts.ReadyTimeout <= 0 {
+		opts.ReadyTimeout = DefaultReadyTimeout
+	}
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = sandboxes.DefaultWaitInterval
+	}
+	if opts.MaxConcurrentCreates <= 0 {
+		opts.MaxConcurrentCreates = DefaultMaxConcurrentCreates
+	}
+
+	return &Pool{
+		client:   c,
+		opts:     opts,
+		instance: randomID(),
+		leased:   make(map[string]*member),
+		notify:   make(chan struct{}),
+		wake:     make(chan struct{}, 1),
+	}, nil
+}
+
+// Instance returns the ID this pool records in the metadata of its sandboxes
+func (p *Pool) Instance() string {
+	return p.instance
+}
+
+// Stats returns a snapshot of pool activity
+func (p *Pool) Stats() Stats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	s := p.stats
+	s.Idle = len(p.idle)
+	s.Leased = len(p.leased)
+	s.Creating = p.creating
+	return s
+}
+
+// Run replenishes the pool and refreshes idle sandboxes until ctx is done
+func (p *Pool) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.opts.ReplenishInterval)
+	defer ticker.Stop()
+
+	for {
+		p.refreshIdle(ctx)
+		p.replenish(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-p.wake:
+		}
+	}
+}
+
+// Acquire leases a warm sandbox, creating one on demand when the pool is empty.
+// When MaxSize is reached it waits for a sandbox to be released or ctx to be done.
+func (p *Pool) Acquire(ctx context.Context) (*Lease, error) {
+	for {
+		p.mu.Lock()
+		if p.closed {
+			p.mu.Unlock()
+			return nil, ErrClosed
+		}
+
+		if n := len(p.idle); n > 0 {
+			m := p.idle[0]
+			p.idle = p.idle[1:]
+			p.leasing++
+			p.mu.Unlock()
+			p.triggerReplenish()
+
+			lease, err := p.leaseCounted(ctx, m)
+			if err == nil {
+				return lease, nil
+			}
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			continue
+		}
+
+		if p.opts.MaxSize == 0 || p.totalLocked() < p.opts.MaxSize {
+			p.creating++
+			p.stats.Misses++
+			p.mu.Unlock()
+
+			m, err := p.create(ctx, false)
+			p.mu.Lock()
+			p.creating--
+			if err == nil {
+				p.leasing++
+			}
+			p.mu.Unlock()
+			if err != nil {
+				p.countFailure()
+				return nil, err
+			}
+			return p.leaseCounted(ctx, m)
+		}
+
+		ch := p.notify
+		p.mu.Unlock()
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ch:
+		}
+	}
+}
+
+// Reclaim destroys sandboxes of this pool left behind by other instances whose lease has expired.
+// It returns the number of sandboxes destroyed.
+func (p *Pool) Reclaim(ctx context.Context) (int, error) {
+	now := time.Now()
+	list, err := p.client.ListAll(ctx, models.ListSandboxesOptions{LabelSelector: MetadataPool + "=" + p.opts.Name})
+	if err != nil {
+		return 0, err
+	}
+	var stale []string
+	for _, sb := range list {
+		md := sb.Metadata
+		if md[MetadataInstance] == p.instance || models.IsTerminalStatus(sb.Status) {
+			continue
+		}
+		expires, err := time.Parse(time.RFC3339, md[MetadataExpires])
+		if err == nil && expires.After(now) {
+			continue
+		}
+		stale = append(stale, sb.SandboxID)
+	}
+
+	reclaimed := 0
+	var errs []error
+	for _, id := range stale {
+		if _, err := p.client.Delete(ctx, id, nil); err != nil {
+			errs = append(errs, fmt.Errorf("reclaim %s: %w", id, err))
+			continue
+		}
+		reclaimed++
+	}
+	p.mu.Lock()
+	p.stats.Destroyed += int64(reclaimed)
+	p.mu.Unlock()
+	return reclaimed, errors.Join(errs...)
+}
+
+// Close destroys idle sandboxes and stops handing out leases.
+// Sandboxes still leased are destroyed when they are released.
+func (p *Pool) Close(ctx context.Context) error {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return nil
+	}
+	p.closed = true
+	idle := p.idle
+	p.idle = nil
+	p.signalLocked()
+	p.mu.Unlock()
+
+	p.creators.Wait()
+
+	var errs []error
+	for _, m := range idle {
+		if err := p.destroy(ctx, m); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (p *Pool) replenish(ctx context.Context) {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return
+	}
+	need := p.opts.Size - len(p.idle) - p.renewing - p.creating
+	if p.opts.MaxSize > 0 {
+		need = min(need, p.opts.MaxSize-p.totalLocked())
+	}
+	if need <= 0 {
+		p.mu.Unlock()
+		return
+	}
+	p.creating += need
+	p.mu.Unlock()
+
+	sem := make(chan struct{}, p.opts.MaxConcurrentCreates)
+	for i := 0; i < need; i++ {
+		p.creators.Add(1)
+		go func() {
+			defer p.creators.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			m, err := p.create(ctx, p.opts.PauseIdle)
+
+			p.mu.Lock()
+			p.creating--
+			if err != nil {
+				p.stats.Failures++
+				p.mu.Unlock()
+				return
+			}
+			if p.closed {
+				p.mu.Unlock()
+				p.destroy(context.WithoutCancel(ctx), m)
+				return
+			}
+			p.idle = append(p.idle, m)
+			p.signalLocked()
+			p.mu.Unlock()
+		}()
+	}
+}
+
+func (p *Pool) refreshIdle(ctx context.Context) {
+	now := time.Now()
+
+	// Members being renewed leave the idle list so a concurrent Acquire cannot lease them
+	// while their state is rewritten
+	p.mu.Lock()
+	var keep, expired, renew []*member
+	for _, m := range p.idle {
+		switch {
+		case p.opts.MaxAge > 0 && now.Sub(m.createdAt) >= p.opts.MaxAge:
+			expired = append(expired, m)
+		case m.expires.Sub(now) < p.opts.LeaseTTL/2:
+			renew = append(renew, m)
+		default:
+			keep = append(keep, m)
+		}
+	}
+	p.idle = keep
+	p.renewing += len(renew)
+	p.mu.Unlock()
+
+	for _, m := range expired {
+		p.destroy(ctx, m)
+	}
+	for _, m := range renew {
+		if err := p.setState(ctx, m, StateIdle, ""); err != nil {
+			p.countFailure()
+		}
+		p.mu.Lock()
+		p.renewing--
+		if p.closed {
+			p.mu.Unlock()
+			p.destroy(context.WithoutCancel(ctx), m)
+			continue
+		}
+		p.idle = append(p.idle, m)
+		p.signalLocked()
+		p.mu.Unlock()
+	}
+}
+
+func (p *Pool) create(ctx context.Context, pause bool) (*member, error) {
+	m := &member{metadata: make(map[string]string, len(p.opts.Spec.Metadata)+5)}
+	for k, v := range p.opts.Spec.Metadata {
+		m.metadata[k] = v
+	}
+	m.expires = time.Now().Add(p.opts.LeaseTTL)
+	m.metadata[MetadataPool] = p.opts.Name
+	m.metadata[MetadataInstance] = p.instance
+	m.metadata[MetadataState] = StateIdle
+	m.metadata[MetadataExpires] = m.expires.UTC().Format(time.RFC3339)
+
+	req := p.opts.Spec
+	req.Metadata = m.metadata
+	sandbox, err := p.client.Create(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("pool: create sandbox: %w", err)
+	}
+	m.sandboxID = sandbox.SandboxID
+	m.createdAt = sandbox.CreatedAt
+	if m.createdAt.IsZero() {
+		m.createdAt = time.Now()
+	}
+
+	p.mu.Lock()
+	p.stats.Created++
+	p.mu.Unlock()
+
+	if err := p.waitFor(ctx, m.sandboxID, models.StatusRunning); err != nil {
+		p.destroy(context.WithoutCancel(ctx), m)
+		return nil, err
+	}
+	if pause {
+		if _, err := p.client.Pause(ctx, m.sandboxID); err != nil {
+			p.destroy(context.WithoutCancel(ctx), m)
+			return nil, fmt.Errorf("pool: pause sandbox %s: %w", m.sandboxID, err)
+		}
+	}
+	return m, nil
+}
+
+func (p *Pool) lease(ctx context.Context, m *member) (*Lease, error) {
+	sandbox, err := p.client.Get(ctx, m.sandboxID)
+	if err != nil {
+		return nil, err
+	}
+	switch sandbox.Status {
+	case models.StatusRunning:
+	case models.StatusPaused, models.StatusPausing:
+		if sandbox.Status == models.StatusPausing {
+			if err := p.waitFor(ctx, m.sandboxID, models.StatusPaused); err != nil {
+				return nil, err
+			}
+		}
+		if _, err := p.client.Resume(ctx, m.sandboxID); err != nil {
+			return nil, fmt.Errorf("pool: resume sandbox %s: %w", m.sandboxID, err)
+		}
+		if err := p.waitFor(ctx, m.sandboxID, models.StatusRunning); err != nil {
+			return nil, err
+		}
+		if sandbox, err = p.client.Get(ctx, m.sandboxID); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("%w: %s is %s", errSandboxUnusable, m.sandboxID, sandbox.Status)
+	}
+
+	leaseID := randomID()
+	if err := p.setState(ctx, m, StateLeased, leaseID); err != nil {
+		return nil, err
+	}
+
+	lease := &Lease{
+		ID:         leaseID,
+		Sandbox:    sandbox,
+		AcquiredAt: time.Now(),
+		pool:       p,
+		member:     m,
+	}
+	p.mu.Lock()
+	p.leased[leaseID] = m
+	p.stats.Acquired++
+	p.mu.Unlock()
+	return lease, nil
+}
+
+// leaseCounted leases m, which the caller counted in leasing, destroying it on failure.
+// It stops counting m once m is leased or destroyed.
+func (p *Pool) leaseCounted(ctx context.Context, m *member) (*Lease, error) {
+	lease, err := p.lease(ctx, m)
+	if err != nil {
+		p.countFailure()
+		p.destroy(context.WithoutCancel(ctx), m)
+	}
+	p.mu.Lock()
+	p.leasing--
+	p.signalLocked()
+	p.mu.Unlock()
+	return lease, err
+}
+
+func (p *Pool) release(ctx context.Context, l *Lease, discard bool) error {
+	p.mu.Lock()
+	m, ok := p.leased[l.ID]
+	if !ok {
+		p.mu.Unlock()
+		return ErrLeaseReleased
+	}
+	delete(p.leased, l.ID)
+	m.uses++
+	reuse := !discard && !p.closed && p.opts.Recycle == ReuseOnRelease &&
+		(p.opts.MaxUses == 0 || m.uses < p.opts.MaxUses) &&
+		(p.opts.MaxAge == 0 || time.Since(m.createdAt) < p.opts.MaxAge) &&
+		len(p.idle)+p.creating < p.opts.Size
+	p.signalLocked()
+	p.mu.Unlock()
+
+	if !reuse {
+		return p.destroy(ctx, m)
+	}
+
+	if err := p.setState(ctx, m, StateIdle, ""); err != nil {
+		p.countFailure()
+		return p.destroy(ctx, m)
+	}
+	if p.opts.PauseIdle {
+		if _, err := p.client.Pause(ctx, m.sandboxID); err != nil {
+			p.countFailure()
+			return p.destroy(ctx, m)
+		}
+	}
+
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return p.destroy(ctx, m)
+	}
+	p.idle = append(p.idle, m)
+	p.signalLocked()
+	p.mu.Unlock()
+	return nil
+}
+
+func (p *Pool) setState(ctx context.Context, m *member, state, leaseID string) error {
+	expires := time.Now().Add(p.opts.LeaseTTL)
+
+	// Set only the pool's own labels so metadata set by others is preserved
+	set := map[string]string{
+		MetadataState:   state,
+		MetadataExpires: expires.UTC().Format(time.RFC3339),
+	}
+	if leaseID != "" {
+		set[MetadataLease] = leaseID
+	}
+	if _, err := p.client.AddLabels(ctx, m.sandboxID, set); err != nil {
+		return fmt.Errorf("pool: update metadata of %s: %w", m.sandboxID, err)
+	}
+	if leaseID == "" {
+		if _, err := p.client.RemoveLabels(ctx, m.sandboxID, MetadataLease); err != nil {
+			return fmt.Errorf("pool: update metadata of %s: %w", m.sandboxID, err)
+		}
+	}
+
+	p.mu.Lock()
+	for k, v := range set {
+		m.metadata[k] = v
+	}
+	if leaseID == "" {
+		delete(m.metadata, MetadataLease)
+	}
+	m.expires = expires
+	p.mu.Unlock()
+	return nil
+}
+
+func (p *Pool) destroy(ctx context.Context, m *member) error {
+	if _, err := p.client.Delete(ctx, m.sandboxID, nil); err != nil {
+		p.countFailure()
+		return fmt.Errorf("pool: delete sandbox %s: %w", m.sandboxID, err)
+	}
+	p.mu.Lock()
+	p.stats.Destroyed++
+	p.mu.Unlock()
+	return nil
+}
+
+func (p *Pool) waitFor(ctx context.Context, sandboxID, status string) error {
+	ctx, cancel := context.WithTimeout(ctx, p.opts.ReadyTimeout)
+	defer cancel()
+	if _, err := p.client.WaitForStatus(ctx, sandboxID, status, p.opts.PollInterval); err != nil {
+		return fmt.Errorf("pool: wait for %s to be %s: %w", sandboxID, status, err)
+	}
+	return nil
+}
+
+func (p *Pool) countFailure() {
+	p.mu.Lock()
+	p.stats.Failures++
+	p.mu.Unlock()
+}
+
+func (p *Pool) triggerReplenish() {
+	select {
+	case p.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (p *Pool) totalLocked() int {
+	return len(p.idle) + p.renewing + p.leasing + len(p.leased) + p.creating
+}
+
+func (p *Pool) signalLocked() {
+	close(p.notify)
+	p.notify = make(chan struct{})
+}
+
+func randomID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("%x", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(b)
+}
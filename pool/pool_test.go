@@ -0,0 +1,377 @@
+package pool
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/scalebox/scalebox-sdk-golang/api/sandboxes"
+	"github.com/scalebox/scalebox-sdk-golang/client"
+	"github.com/scalebox/scalebox-sdk-golang/models"
+)
+
+// fakeBackend is a minimal in-memory sandboxes API
+type fakeBackend struct {
+	mu        sync.Mutex
+	next      int
+	sandboxes map[string]*models.Sandbox
+	deleted   []string
+	peak      int      // Most sandboxes held at once
+	listed    []string // Label selectors of list requests
+
+	// Label requests for holdID signal held and wait for release; set before use
+	holdID   string
+	held     chan struct{}
+	released chan struct{}
+}
+
+func newFakeBackend() *fakeBackend {
+	return &fakeBackend{sandboxes: make(map[string]*models.Sandbox)}
+}
+
+func (b *fakeBackend) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if b.holdID != "" && r.URL.Path == "/v1/sandboxes/"+b.holdID+"/labels" && r.Method == "POST" {
+		b.held <- struct{}{}
+		<-b.released
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/v1/sandboxes"), "/")
+	w.Header().Set("Content-Type", "application/json")
+
+	if len(parts) == 1 {
+		switch r.Method {
+		case "POST":
+			var req models.CreateSandboxRequest
+			json.NewDecoder(r.Body).Decode(&req)
+			b.next++
+			sb := &models.Sandbox{
+				SandboxID: fmt.Sprintf("sbx-%d", b.next),
+				Name:      req.Name,
+				Status:    models.StatusRunning,
+				Metadata:  req.Metadata,
+				CreatedAt: time.Now(),
+			}
+			b.sandboxes[sb.SandboxID] = sb
+			b.peak = max(b.peak, len(b.sandboxes))
+			json.NewEncoder(w).Encode(sb)
+		case "GET":
+			b.listed = append(b.listed, r.URL.Query().Get("label_selector"))
+			list := models.SandboxListResponse{Sandboxes: []models.Sandbox{}}
+			if r.URL.Query().Get("offset") == "" {
+				for _, sb := range b.sandboxes {
+					list.Sandboxes = append(list.Sandboxes, *sb)
+				}
+			}
+			json.NewEncoder(w).Encode(list)
+		}
+		return
+	}
+
+	sb, ok := b.sandboxes[parts[1]]
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"message": "not found"})
+		return
+	}
+	action := ""
+	if len(parts) > 2 {
+		action = parts[2]
+	}
+
+	switch {
+	case r.Method == "GET" && action == "status":
+		json.NewEncoder(w).Encode(models.SandboxStatus{SandboxID: sb.SandboxID, Status: sb.Status})
+		return
+	case action == "labels" && r.Method == "POST":
+		var req models.AddLabelsRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		if sb.Metadata == nil {
+			sb.Metadata = make(map[string]string)
+		}
+		for k, v := range req.Labels {
+			sb.Metadata[k] = v
+		}
+	case action == "labels" && r.Method == "DELETE":
+		for _, k := range strings.Split(r.URL.Query().Get("keys"), ",") {
+			delete(sb.Metadata, k)
+		}
+	case r.Method == "DELETE":
+		delete(b.sandboxes, sb.SandboxID)
+		b.deleted = append(b.deleted, sb.SandboxID)
+		json.NewEncoder(w).Encode(models.DeletionResponse{SandboxID: sb.SandboxID, Status: "deletion_in_progress"})
+		return
+	case action == "pause":
+		sb.Status = models.StatusPaused
+	case action == "resume":
+		sb.Status = models.StatusRunning
+	}
+	json.NewEncoder(w).Encode(sb)
+}
+
+func (b *fakeBackend) sandbox(id string) (models.Sandbox, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	sb, ok := b.sandboxes[id]
+	if !ok {
+		return models.Sandbox{}, false
+	}
+	return *sb, true
+}
+
+func (b *fakeBackend) count() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.sandboxes)
+}
+
+func newTestPool(t *testing.T, backend *fakeBackend, opts Options) *Pool {
+	t.Helper()
+	server := httptest.NewServer(backend)
+	t.Cleanup(server.Close)
+
+	opts.Name = "test-pool"
+	opts.Spec = models.CreateSandboxRequest{Name: "pooled", Template: "base", Metadata: map[string]string{"team": "ml"}}
+	opts.PollInterval = 5 * time.Millisecond
+	opts.ReplenishInterval = 10 * time.Millisecond
+
+	p, err := New(sandboxes.NewClient(client.NewClient(server.URL, "test-api-key")), opts)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	return p
+}
+
+func waitUntil(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("Timed out waiting for condition")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestPoolReplenishesAndLeases(t *testing.T) {
+	backend := newFakeBackend()
+	p := newTestPool(t, backend, Options{Size: 2, PauseIdle: true})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go p.Run(ctx)
+
+	waitUntil(t, func() bool { return p.Stats().Idle == 2 })
+
+	lease, err := p.Acquire(ctx)
+	if err != nil {
+		t.Fatalf("Acquire failed: %v", err)
+	}
+	if lease.Sandbox.Status != models.StatusRunning {
+		t.Errorf("Expected leased sandbox to be resumed, got status %s", lease.Sandbox.Status)
+	}
+
+	sb, _ := backend.sandbox(lease.Sandbox.SandboxID)
+	if sb.Metadata[MetadataState] != StateLeased || sb.Metadata[MetadataLease] != lease.ID {
+		t.Errorf("Expected lease recorded in metadata, got %v", sb.Metadata)
+	}
+	if sb.Metadata[MetadataPool] != "test-pool" || sb.Metadata["team"] != "ml" {
+		t.Errorf("Expected pool and spec metadata, got %v", sb.Metadata)
+	}
+
+	// The pool tops itself back up to two idle sandboxes
+	waitUntil(t, func() bool { return p.Stats().Idle == 2 })
+
+	if err := lease.Release(ctx); err != nil {
+		t.Fatalf("Release failed: %v", err)
+	}
+	if _, ok := backend.sandbox(lease.Sandbox.SandboxID); ok {
+		t.Error("Expected sandbox to be destroyed on release")
+	}
+	if err := lease.Release(ctx); err != ErrLeaseReleased {
+		t.Errorf("Expected ErrLeaseReleased on second release, got %v", err)
+	}
+
+	stats := p.Stats()
+	if stats.Acquired != 1 || stats.Misses != 0 || stats.Leased != 0 {
+		t.Errorf("Unexpected stats: %+v", stats)
+	}
+
+	cancel()
+	if err := p.Close(context.Background()); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if n := backend.count(); n != 0 {
+		t.Errorf("Expected all sandboxes destroyed after Close, %d left", n)
+	}
+}
+
+func TestPoolReusesUntilMaxUses(t *testing.T) {
+	backend := newFakeBackend()
+	p := newTestPool(t, backend, Options{Size: 1, Recycle: ReuseOnRelease, MaxUses: 2})
+	ctx := context.Background()
+
+	first, err := p.Acquire(ctx)
+	if err != nil {
+		t.Fatalf("Acquire failed: %v", err)
+	}
+	if err := first.Release(ctx); err != nil {
+		t.Fatalf("Release failed: %v", err)
+	}
+	sb, ok := backend.sandbox(first.Sandbox.SandboxID)
+	if !ok || sb.Metadata[MetadataState] != StateIdle {
+		t.Fatalf("Expected sandbox returned to pool as idle, got %v", sb.Metadata)
+	}
+
+	second, err := p.Acquire(ctx)
+	if err != nil {
+		t.Fatalf("Acquire failed: %v", err)
+	}
+	if second.Sandbox.SandboxID != first.Sandbox.SandboxID {
+		t.Errorf("Expected recycled sandbox %s, got %s", first.Sandbox.SandboxID, second.Sandbox.SandboxID)
+	}
+	if err := second.Release(ctx); err != nil {
+		t.Fatalf("Release failed: %v", err)
+	}
+	if _, ok := backend.sandbox(first.Sandbox.SandboxID); ok {
+		t.Error("Expected sandbox destroyed after MaxUses leases")
+	}
+
+	if stats := p.Stats(); stats.Misses != 1 {
+		t.Errorf("Expected 1 miss, got %d", stats.Misses)
+	}
+}
+
+func TestPoolAcquireWaitsAtMaxSize(t *testing.T) {
+	backend := newFakeBackend()
+	p := newTestPool(t, backend, Options{MaxSize: 1})
+
+	lease, err := p.Acquire(context.Background())
+	if err != nil {
+		t.Fatalf("Acquire failed: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := p.Acquire(ctx); err != context.DeadlineExceeded {
+		t.Errorf("Expected DeadlineExceeded at max size, got %v", err)
+	}
+
+	if err := lease.Discard(context.Background()); err != nil {
+		t.Fatalf("Discard failed: %v", err)
+	}
+	if _, err := p.Acquire(context.Background()); err != nil {
+		t.Errorf("Expected Acquire to succeed after discard, got %v", err)
+	}
+}
+
+func TestPoolConcurrentAcquireRespectsMaxSize(t *testing.T) {
+	backend := newFakeBackend()
+	p := newTestPool(t, backend, Options{Size: 2, MaxSize: 2, PauseIdle: true})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go p.Run(ctx)
+	waitUntil(t, func() bool { return p.Stats().Idle == 2 })
+
+	// Members being resumed and labelled for a lease count, so neither the replenisher nor
+	// the other callers create past MaxSize
+	var wg sync.WaitGroup
+	for i := 0; i < 6; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			acquireCtx, cancel := context.WithTimeout(ctx, 200*time.Millisecond)
+			defer cancel()
+			p.Acquire(acquireCtx)
+		}()
+	}
+	wg.Wait()
+	backend.mu.Lock()
+	defer backend.mu.Unlock()
+	if backend.peak > 2 {
+		t.Errorf("Backend held %d sandboxes at once, want at most 2", backend.peak)
+	}
+}
+
+func TestPoolReclaimsExpiredSandboxes(t *testing.T) {
+	backend := newFakeBackend()
+	expired := time.Now().Add(-time.Minute).UTC().Format(time.RFC3339)
+	live := time.Now().Add(time.Hour).UTC().Format(time.RFC3339)
+	backend.sandboxes["sbx-crashed"] = &models.Sandbox{
+		SandboxID: "sbx-crashed",
+		Status:    models.StatusPaused,
+		Metadata:  map[string]string{MetadataPool: "test-pool", MetadataInstance: "dead", MetadataExpires: expired},
+	}
+	backend.sandboxes["sbx-live"] = &models.Sandbox{
+		SandboxID: "sbx-live",
+		Status:    models.StatusRunning,
+		Metadata:  map[string]string{MetadataPool: "test-pool", MetadataInstance: "other", MetadataExpires: live},
+	}
+	backend.sandboxes["sbx-unrelated"] = &models.Sandbox{SandboxID: "sbx-unrelated", Status: models.StatusRunning}
+
+	p := newTestPool(t, backend, Options{})
+	n, err := p.Reclaim(context.Background())
+	if err != nil {
+		t.Fatalf("Reclaim failed: %v", err)
+	}
+	if n != 1 {
+		t.Errorf("Expected 1 reclaimed sandbox, got %d", n)
+	}
+	if _, ok := backend.sandbox("sbx-crashed"); ok {
+		t.Error("Expected expired sandbox to be reclaimed")
+	}
+	if _, ok := backend.sandbox("sbx-live"); !ok {
+		t.Error("Expected sandbox with live lease to be kept")
+	}
+	if len(backend.listed) != 1 || backend.listed[0] != MetadataPool+"=test-pool" {
+		t.Errorf("Expected one list filtered by the pool label, got %q", backend.listed)
+	}
+}
+
+func TestPoolAcquireSkipsRenewingSandboxes(t *testing.T) {
+	backend := newFakeBackend()
+	p := newTestPool(t, backend, Options{Size: 1, Recycle: ReuseOnRelease})
+	ctx := context.Background()
+
+	p.replenish(ctx)
+	waitUntil(t, func() bool { return p.Stats().Idle == 1 })
+	p.mu.Lock()
+	renewing := p.idle[0]
+	renewing.expires = time.Now()
+	p.mu.Unlock()
+
+	backend.held, backend.released = make(chan struct{}), make(chan struct{})
+	backend.holdID = renewing.sandboxID
+	done := make(chan struct{})
+	go func() {
+		p.refreshIdle(ctx)
+		close(done)
+	}()
+	<-backend.held
+
+	// The sandbox being renewed cannot be leased, so Acquire creates another
+	lease, err := p.Acquire(ctx)
+	if err != nil {
+		t.Fatalf("Acquire failed: %v", err)
+	}
+	if lease.Sandbox.SandboxID == renewing.sandboxID {
+		t.Fatal("Leased a sandbox while its idle state was being renewed")
+	}
+	close(backend.released)
+	<-done
+
+	if stats := p.Stats(); stats.Idle != 1 || stats.Leased != 1 {
+		t.Errorf("Unexpected stats after renewal: %+v", stats)
+	}
+	sb, _ := backend.sandbox(renewing.sandboxID)
+	if sb.Metadata[MetadataState] != StateIdle {
+		t.Errorf("Expected the renewed sandbox to stay idle, got %v", sb.Metadata)
+	}
+}
@@ -0,0 +1,220 @@
+// Package reaper deletes orphaned sandboxes, such as those left behind by crashed CI jobs.
+//
+// Run lists the sandboxes matching Criteria, skips protected ones, deletes at most
+// MaxDeletions of them (oldest first) and returns a Report of what was deleted and why.
+// With DryRun set nothing is deleted and the report shows what would have been.
+package reaper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/scalebox/scalebox-sdk-golang/api/sandboxes"
+	"github.com/scalebox/scalebox-sdk-golang/labels"
+	"github.com/scalebox/scalebox-sdk-golang/models"
+)
+
+// Reaper defaults
+const (
+	DefaultProtectionLabel = "scalebox.protected"
+	DefaultMaxDeletions    = 10
+)
+
+// Criteria selects the sandboxes to reap. Every condition that is set must hold.
+// Selector or NamePrefix is required so a run is always scoped.
+type Criteria struct {
+	Selector   string   // Label selector on metadata, e.g. "ci-job,owner=ci"
+	NamePrefix string   // Sandbox name prefix, e.g. "ci-"
+	Statuses   []string // Only sandboxes in one of these statuses; defaults to every live status
+
+	OlderThan                   time.Duration // Created at least this long ago
+	PausedLongerThan            time.Duration // Paused for at least this long
+	MaxPersistenceDaysRemaining *int          // Paused state is kept for at most this many more days
+}
+
+// Options configures Run
+type Options struct {
+	Criteria
+
+	// DryRun reports what would be deleted without deleting anything
+	DryRun bool
+	// ProtectionLabel marks sandboxes that are never reaped, whatever its value other than "false".
+	// Defaults to DefaultProtectionLabel.
+	ProtectionLabel string
+	// MaxDeletions caps deletions per run, defaults to DefaultMaxDeletions.
+	// Matching sandboxes beyond the cap are reported as OutcomeLimited.
+	MaxDeletions int
+	// Now returns the current time, defaults to time.Now
+	Now func() time.Time
+}
+
+// Validate checks that the options are scoped and consistent
+func (o Options) Validate() error {
+	var errs []models.FieldError
+	if o.Selector == "" && o.NamePrefix == "" {
+		errs = append(errs, models.FieldError{Field: "selector", Message: "selector or name prefix is required"})
+	}
+	if o.Selector != "" {
+		if _, err := labels.Parse(o.Selector); err != nil {
+			errs = append(errs, models.FieldError{Field: "selector", Message: err.Error()})
+		}
+	}
+	if o.OlderThan < 0 || o.PausedLongerThan < 0 {
+		errs = append(errs, models.FieldError{Field: "older_than", Message: "durations must not be negative"})
+	}
+	if o.MaxPersistenceDaysRemaining != nil && *o.MaxPersistenceDaysRemaining < 0 {
+		errs = append(errs, models.FieldError{Field: "max_persistence_days_remaining", Message: "must not be negative"})
+	}
+	if o.MaxDeletions < 0 {
+		errs = append(errs, models.FieldError{Field: "max_deletions", Message: "must not be negative"})
+	}
+	if len(errs) > 0 {
+		return &models.ValidationError{Errors: errs}
+	}
+	return nil
+}
+
+// Run finds the sandboxes matching the criteria and deletes them, unless DryRun is set.
+// Failed deletions are recorded in the report and returned joined as the error.
+func Run(ctx context.Context, c *sandboxes.Client, opts Options) (*Report, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	now := time.Now
+	if opts.Now != nil {
+		now = opts.Now
+	}
+	protection := opts.ProtectionLabel
+	if protection == "" {
+		protection = DefaultProtectionLabel
+	}
+	limit := opts.MaxDeletions
+	if limit == 0 {
+		limit = DefaultMaxDeletions
+	}
+
+	report := &Report{StartedAt: now(), DryRun: opts.DryRun, MaxDeletions: limit, Entries: []Entry{}}
+	list, err := c.ListAll(ctx, models.ListSandboxesOptions{
+		LabelSelector: opts.Selector,
+		Search:        opts.NamePrefix,
+		Statuses:      opts.Statuses,
+	})
+	if err != nil {
+		return nil, err
+	}
+	report.Scanned = len(list)
+
+	var matched []models.Sandbox
+	reasons := make(map[string][]string)
+	for _, sb := range list {
+		if why, ok := opts.Criteria.match(&sb, report.StartedAt); ok {
+			matched = append(matched, sb)
+			reasons[sb.SandboxID] = why
+		}
+	}
+	sort.SliceStable(matched, func(i, j int) bool { return matched[i].CreatedAt.Before(matched[j].CreatedAt) })
+
+	var errs []error
+	deletions := 0
+	for _, sb := range matched {
+		entry := Entry{SandboxID: sb.SandboxID, Name: sb.Name, Status: sb.Status, CreatedAt: sb.CreatedAt, Reasons: reasons[sb.SandboxID]}
+		switch {
+		case isProtected(sb.Metadata, protection):
+			entry.Outcome = OutcomeProtected
+		case deletions >= limit:
+			entry.Outcome = OutcomeLimited
+			report.LimitReached = true
+		case opts.DryRun:
+			entry.Outcome = OutcomeWouldDelete
+			deletions++
+		default:
+			deletions++
+			if _, err := c.Delete(ctx, sb.SandboxID, nil); err != nil {
+				entry.Outcome = OutcomeFailed
+				entry.Error = err.Error()
+				errs = append(errs, fmt.Errorf("delete %s: %w", sb.SandboxID, err))
+			} else {
+				entry.Outcome = OutcomeDeleted
+			}
+		}
+		report.Entries = append(report.Entries, entry)
+	}
+	return report, errors.Join(errs...)
+}
+
+// match reports whether sb meets every condition, and the reasons it does
+func (c Criteria) match(sb *models.Sandbox, now time.Time) ([]string, bool) {
+	if models.IsTerminalStatus(sb.Status) || sb.Status == models.StatusTerminating {
+		return nil, false
+	}
+	var reasons []string
+	if c.Selector != "" {
+		// The list is already filtered; evaluate again in case the server ignored the selector
+		selector, _ := labels.Parse(c.Selector)
+		if !selector.Matches(sb.Metadata) {
+			return nil, false
+		}
+		reasons = append(reasons, fmt.Sprintf("labels match %q", c.Selector))
+	}
+	if c.NamePrefix != "" {
+		if !strings.HasPrefix(sb.Name, c.NamePrefix) {
+			return nil, false
+		}
+		reasons = append(reasons, fmt.Sprintf("name has prefix %q", c.NamePrefix))
+	}
+	if len(c.Statuses) > 0 {
+		found := false
+		for _, st := range c.Statuses {
+			found = found || st == sb.Status
+		}
+		if !found {
+			return nil, false
+		}
+		reasons = append(reasons, "status "+sb.Status)
+	}
+	if c.OlderThan > 0 {
+		age := now.Sub(sb.CreatedAt)
+		if sb.CreatedAt.IsZero() || age < c.OlderThan {
+			return nil, false
+		}
+		reasons = append(reasons, fmt.Sprintf("created %s ago (older than %s)", age.Round(time.Second), c.OlderThan))
+	}
+	if c.PausedLongerThan > 0 {
+		if sb.Status != models.StatusPaused || sb.PausedAt == nil {
+			return nil, false
+		}
+		paused := now.Sub(*sb.PausedAt)
+		if paused < c.PausedLongerThan {
+			return nil, false
+		}
+		reasons = append(reasons, fmt.Sprintf("paused for %s (longer than %s)", paused.Round(time.Second), c.PausedLongerThan))
+	}
+	if c.MaxPersistenceDaysRemaining != nil {
+		days, ok := persistenceDays(sb, now)
+		if !ok || days > *c.MaxPersistenceDaysRemaining {
+			return nil, false
+		}
+		reasons = append(reasons, fmt.Sprintf("%d persistence days remaining (at most %d)", days, *c.MaxPersistenceDaysRemaining))
+	}
+	return reasons, true
+}
+
+// persistenceDays returns the whole days of persistence left, if the sandbox reports them
+func persistenceDays(sb *models.Sandbox, now time.Time) (int, bool) {
+	if sb.PersistenceDaysRemaining != nil {
+		return *sb.PersistenceDaysRemaining, true
+	}
+	if sb.PersistenceExpiresAt != nil {
+		return int(sb.PersistenceRemaining(now) / (24 * time.Hour)), true
+	}
+	return 0, false
+}
+
+func isProtected(metadata map[string]string, label string) bool {
+	value, ok := metadata[label]
+	return ok && value != "false"
+}
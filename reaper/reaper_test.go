@@ -0,0 +1,154 @@
+package reaper
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/scalebox/scalebox-sdk-golang/api/sandboxes"
+	"github.com/scalebox/scalebox-sdk-golang/client"
+	"github.com/scalebox/scalebox-sdk-golang/models"
+)
+
+var now = time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+// reapServer is a fake API listing fixed sandboxes and recording deletions
+type reapServer struct {
+	mu        sync.Mutex
+	sandboxes []models.Sandbox
+	deleted   []string
+	failures  map[string]bool
+}
+
+func (s *reapServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	w.Header().Set("Content-Type", "application/json")
+	switch r.Method {
+	case "GET":
+		json.NewEncoder(w).Encode(models.SandboxListResponse{Sandboxes: s.sandboxes})
+	case "DELETE":
+		id := strings.TrimPrefix(r.URL.Path, "/v1/sandboxes/")
+		if s.failures[id] {
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(map[string]string{"error": "gone"})
+			return
+		}
+		s.deleted = append(s.deleted, id)
+		json.NewEncoder(w).Encode(models.DeletionResponse{SandboxID: id, Status: "deleted"})
+	}
+}
+
+func ciSandbox(id, status string, age time.Duration) models.Sandbox {
+	return models.Sandbox{
+		SandboxID: id,
+		Name:      "ci-" + id,
+		Status:    status,
+		CreatedAt: now.Add(-age),
+		Metadata:  map[string]string{"owner": "ci"},
+	}
+}
+
+func TestRun(t *testing.T) {
+	pausedAt := now.Add(-30 * time.Hour)
+	recentPause := now.Add(-time.Hour)
+	days := 1
+	fake := &reapServer{sandboxes: []models.Sandbox{
+		ciSandbox("old-running", models.StatusRunning, 5*time.Hour),
+		ciSandbox("young", models.StatusRunning, 10*time.Minute),
+		ciSandbox("old-paused", models.StatusPaused, 48*time.Hour),
+		ciSandbox("keep", models.StatusRunning, 6*time.Hour),
+		ciSandbox("dead", models.StatusTerminated, 9*time.Hour),
+		{SandboxID: "human", Name: "dev-box", Status: models.StatusRunning, CreatedAt: now.Add(-99 * time.Hour), Metadata: map[string]string{"owner": "alice"}},
+	}}
+	fake.sandboxes[2].PausedAt = &pausedAt
+	fake.sandboxes[2].PersistenceDaysRemaining = &days
+	fake.sandboxes[3].Metadata[DefaultProtectionLabel] = "true"
+	server := httptest.NewServer(fake)
+	defer server.Close()
+	c := sandboxes.NewClient(client.NewClient(server.URL, "test-api-key"))
+	ctx := context.Background()
+	clock := func() time.Time { return now }
+
+	// Dry run: nothing is deleted, the oldest match is listed first
+	report, err := Run(ctx, c, Options{Criteria: Criteria{Selector: "owner=ci", OlderThan: 2 * time.Hour}, DryRun: true, Now: clock})
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if len(fake.deleted) != 0 {
+		t.Fatalf("Dry run deleted %v", fake.deleted)
+	}
+	var got []string
+	for _, e := range report.Entries {
+		got = append(got, e.SandboxID+":"+string(e.Outcome))
+	}
+	if want := "old-paused:would_delete,keep:protected,old-running:would_delete"; strings.Join(got, ",") != want {
+		t.Errorf("Entries = %v, want %s", got, want)
+	}
+	if report.Scanned != 5 || !strings.Contains(report.Entries[0].Reasons[1], "older than 2h0m0s") {
+		t.Errorf("Unexpected report %+v", report)
+	}
+
+	// Paused-too-long and persistence thresholds, limited to one deletion
+	fake.sandboxes[0].PausedAt = &recentPause
+	report, err = Run(ctx, c, Options{
+		Criteria:     Criteria{NamePrefix: "ci-", PausedLongerThan: 24 * time.Hour, MaxPersistenceDaysRemaining: &days},
+		MaxDeletions: 1,
+		Now:          clock,
+	})
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if len(fake.deleted) != 1 || fake.deleted[0] != "old-paused" || report.Count(OutcomeDeleted) != 1 {
+		t.Errorf("Expected only old-paused to be deleted, got %v", fake.deleted)
+	}
+
+	// The limit leaves the rest for later, and failures are reported
+	fake.deleted = nil
+	fake.failures = map[string]bool{"old-running": true}
+	report, err = Run(ctx, c, Options{Criteria: Criteria{Selector: "owner", Statuses: []string{models.StatusRunning}}, MaxDeletions: 2, Now: clock})
+	if err == nil || !strings.Contains(err.Error(), "delete old-running") {
+		t.Errorf("Expected the failed deletion to be returned, got %v", err)
+	}
+	if !report.LimitReached || report.Count(OutcomeLimited) != 1 || report.Count(OutcomeFailed) != 1 || report.Count(OutcomeDeleted) != 1 {
+		t.Errorf("Unexpected outcomes %+v", report.Entries)
+	}
+
+	var text, js bytes.Buffer
+	if err := report.WriteText(&text); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(text.String(), "1 deleted, 0 would delete, 1 protected, 1 over limit, 1 failed") || !strings.Contains(text.String(), "warning: more than 2") {
+		t.Errorf("Unexpected text report:\n%s", text.String())
+	}
+	if err := report.WriteJSON(&js); err != nil {
+		t.Fatal(err)
+	}
+	var decoded Report
+	if err := json.Unmarshal(js.Bytes(), &decoded); err != nil || len(decoded.Entries) != 4 {
+		t.Errorf("JSON report does not round trip: %v\n%s", err, js.String())
+	}
+}
+
+func TestOptionsValidate(t *testing.T) {
+	negative := -1
+	err := Options{Criteria: Criteria{Selector: "a in", MaxPersistenceDaysRemaining: &negative}, MaxDeletions: -1}.Validate()
+	verr, ok := err.(*models.ValidationError)
+	if !ok {
+		t.Fatalf("Expected *models.ValidationError, got %v", err)
+	}
+	for _, field := range []string{"selector", "max_persistence_days_remaining", "max_deletions"} {
+		if !verr.HasField(field) {
+			t.Errorf("Expected an error for %s, got %v", field, verr)
+		}
+	}
+	if err := (Options{Criteria: Criteria{OlderThan: time.Hour}}).Validate(); err == nil {
+		t.Error("Expected unscoped options to be rejected")
+	}
+}
@@ -0,0 +1,83 @@
+package reaper
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// Outcome is what a run did with a matching sandbox
+type Outcome string
+
+// Reaper outcomes
+const (
+	OutcomeDeleted     Outcome = "deleted"
+	OutcomeWouldDelete Outcome = "would_delete" // Dry run
+	OutcomeProtected   Outcome = "protected"    // Carries the protection label
+	OutcomeLimited     Outcome = "limited"      // Over MaxDeletions for this run
+	OutcomeFailed      Outcome = "failed"
+)
+
+// Report describes a reaper run
+type Report struct {
+	StartedAt    time.Time `json:"started_at"`
+	DryRun       bool      `json:"dry_run"`
+	MaxDeletions int       `json:"max_deletions"`
+	Scanned      int       `json:"scanned"`       // Sandboxes returned by List
+	LimitReached bool      `json:"limit_reached"` // Some matching sandboxes were left for a later run
+	Entries      []Entry   `json:"entries"`       // Matching sandboxes, oldest first
+}
+
+// Entry is a sandbox that matched the criteria
+type Entry struct {
+	SandboxID string    `json:"sandbox_id"`
+	Name      string    `json:"name"`
+	Status    string    `json:"status"`
+	CreatedAt time.Time `json:"created_at"`
+	Reasons   []string  `json:"reasons"`
+	Outcome   Outcome   `json:"outcome"`
+	Error     string    `json:"error,omitempty"`
+}
+
+// Count returns the number of entries with the given outcome
+func (r *Report) Count(outcome Outcome) int {
+	n := 0
+	for _, e := range r.Entries {
+		if e.Outcome == outcome {
+			n++
+		}
+	}
+	return n
+}
+
+// WriteJSON writes the report as indented JSON, for machine consumption
+func (r *Report) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+// WriteText writes the report for humans
+func (r *Report) WriteText(w io.Writer) error {
+	var b strings.Builder
+	mode := ""
+	if r.DryRun {
+		mode = " (dry run)"
+	}
+	fmt.Fprintf(&b, "Reaper run at %s%s: scanned %d, matched %d, %d deleted, %d would delete, %d protected, %d over limit, %d failed\n",
+		r.StartedAt.Format(time.RFC3339), mode, r.Scanned, len(r.Entries),
+		r.Count(OutcomeDeleted), r.Count(OutcomeWouldDelete), r.Count(OutcomeProtected), r.Count(OutcomeLimited), r.Count(OutcomeFailed))
+	for _, e := range r.Entries {
+		fmt.Fprintf(&b, "  %-12s %s %s [%s]: %s\n", e.Outcome, e.SandboxID, e.Name, e.Status, strings.Join(e.Reasons, "; "))
+		if e.Error != "" {
+			fmt.Fprintf(&b, "    error: %s\n", e.Error)
+		}
+	}
+	if r.LimitReached {
+		fmt.Fprintf(&b, "warning: more than %d sandboxes matched; the rest are left for a later run\n", r.MaxDeletions)
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -0,0 +1,128 @@
+package recommend
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/scalebox/scalebox-sdk-golang/api/sandboxes"
+	"github.com/scalebox/scalebox-sdk-golang/client"
+	"github.com/scalebox/scalebox-sdk-golang/metrics"
+	"github.com/scalebox/scalebox-sdk-golang/models"
+)
+
+// ForSandbox recommends a size for one sandbox from its metrics over the window
+func ForSandbox(ctx context.Context, c *sandboxes.Client, sandboxID string, opts Options) (*Recommendation, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	opts = opts.withDefaults()
+	sb, err := c.Get(ctx, sandboxID)
+	if err != nil {
+		return nil, err
+	}
+	h, err := history(ctx, c, sb, opts)
+	if err != nil {
+		return nil, err
+	}
+	return Analyze(sandboxID, []History{h}, opts)
+}
+
+// ForSandboxes recommends one size for the sandboxes listed with list, e.g. all sandboxes of
+// a template (TemplateName) or label selector (LabelSelector). Their metrics are pooled, so
+// the recommendation fits the group as a whole. Sandboxes deleted meanwhile are skipped.
+func ForSandboxes(ctx context.Context, c *sandboxes.Client, list models.ListSandboxesOptions, opts Options) (*Recommendation, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	opts = opts.withDefaults()
+	found, err := c.ListAll(ctx, list)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	histories := make([]*History, len(found))
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		firstErr error
+	)
+	sem := make(chan struct{}, opts.Concurrency)
+	for i := range found {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			if ctx.Err() != nil {
+				return
+			}
+			h, err := history(ctx, c, &found[i], opts)
+			switch {
+			case client.IsNotFound(err):
+			case err != nil:
+				mu.Lock()
+				if firstErr == nil {
+					firstErr = fmt.Errorf("sandbox %s: %w", found[i].SandboxID, err)
+					cancel()
+				}
+				mu.Unlock()
+			default:
+				histories[i] = &h
+			}
+		}(i)
+	}
+	wg.Wait()
+	if firstErr != nil {
+		return nil, firstErr
+	}
+
+	kept := make([]History, 0, len(histories))
+	for _, h := range histories {
+		if h != nil {
+			kept = append(kept, *h)
+		}
+	}
+	return Analyze(describe(list), kept, opts)
+}
+
+// history fetches the metrics of sb over the window, or since it was created if that is later
+func history(ctx context.Context, c *sandboxes.Client, sb *models.Sandbox, opts Options) (History, error) {
+	end := opts.Now()
+	start := end.Add(-opts.Window)
+	if sb.CreatedAt.After(start) {
+		start = sb.CreatedAt
+	}
+	h := History{Sandbox: *sb}
+	if !start.Before(end) {
+		return h, nil
+	}
+	resp, err := c.GetMetricsRange(ctx, sb.SandboxID, start, end, sandboxes.MetricsRangeOptions{MaxPoints: opts.MaxPoints})
+	if err != nil {
+		return h, err
+	}
+	h.Series = metrics.FromResponse(resp)
+	return h, nil
+}
+
+// describe names the sandboxes selected by list, for Recommendation.Target
+func describe(list models.ListSandboxesOptions) string {
+	var parts []string
+	for _, kv := range [][2]string{
+		{"template", list.TemplateName},
+		{"template_id", list.TemplateID},
+		{"project", list.ProjectID},
+		{"selector", list.LabelSelector},
+	} {
+		if kv[1] != "" {
+			parts = append(parts, kv[0]+"="+kv[1])
+		}
+	}
+	if len(parts) == 0 {
+		return "all sandboxes"
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,125 @@
+package recommend
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/scalebox/scalebox-sdk-golang/api/sandboxes"
+	"github.com/scalebox/scalebox-sdk-golang/client"
+	"github.com/scalebox/scalebox-sdk-golang/metrics"
+	"github.com/scalebox/scalebox-sdk-golang/models"
+)
+
+// historyServer is a fake API serving fixed sandboxes and their metrics between start and end
+type historyServer struct {
+	mu        sync.Mutex
+	sandboxes []models.Sandbox
+	series    map[string]metrics.Series
+	fail      string // Sandbox whose metrics requests fail
+}
+
+func (s *historyServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	w.Header().Set("Content-Type", "application/json")
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/v1/sandboxes"), "/")
+	switch {
+	case len(parts) < 2:
+		json.NewEncoder(w).Encode(models.SandboxListResponse{Sandboxes: s.sandboxes})
+	case len(parts) == 2:
+		for _, sb := range s.sandboxes {
+			if sb.SandboxID == parts[1] {
+				json.NewEncoder(w).Encode(sb)
+				return
+			}
+		}
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"error": "not found"})
+	default:
+		id := parts[1]
+		if id == s.fail {
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(map[string]string{"error": "boom"})
+			return
+		}
+		series, ok := s.series[id]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(map[string]string{"error": "not found"})
+			return
+		}
+		start, _ := time.Parse(time.RFC3339, r.URL.Query().Get("start"))
+		end, _ := time.Parse(time.RFC3339, r.URL.Query().Get("end"))
+		json.NewEncoder(w).Encode(models.SandboxMetricsResponse{SandboxID: id, Status: models.StatusRunning, Metrics: series.Between(start, end.Add(time.Second))})
+	}
+}
+
+func TestForSandbox(t *testing.T) {
+	week := hourly(7*24*time.Hour, 4, constant(20), constant(700), constant(2))
+	fake := &historyServer{
+		sandboxes: []models.Sandbox{sandbox("sbx-1", 4, 4096, 20)},
+		series:    map[string]metrics.Series{"sbx-1": week},
+	}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+	c := sandboxes.NewClient(client.NewClient(server.URL, "test-api-key"))
+	clock := func() time.Time { return now }
+
+	rec, err := ForSandbox(context.Background(), c, "sbx-1", Options{Now: clock})
+	if err != nil {
+		t.Fatalf("ForSandbox failed: %v", err)
+	}
+	if rec.Target != "sbx-1" || rec.Points != len(week) || rec.ConfidenceLevel() != "high" {
+		t.Errorf("Unexpected recommendation %+v", rec)
+	}
+	if want := (Resources{CPUCount: 1, MemoryMB: 1024, StorageGB: 3}); rec.Recommended != want {
+		t.Errorf("Recommended = %v, want %v", rec.Recommended, want)
+	}
+
+	if _, err := ForSandbox(context.Background(), c, "sbx-missing", Options{Now: clock}); !client.IsNotFound(err) {
+		t.Errorf("Expected not found, got %v", err)
+	}
+}
+
+func TestForSandboxes(t *testing.T) {
+	fake := &historyServer{
+		sandboxes: []models.Sandbox{sandbox("a", 2, 2048, 10), sandbox("b", 2, 2048, 10), sandbox("gone", 2, 2048, 10), sandbox("young", 2, 2048, 10)},
+		series: map[string]metrics.Series{
+			"a":     hourly(7*24*time.Hour, 2, constant(50), constant(600), constant(1)),
+			"b":     hourly(7*24*time.Hour, 2, constant(10), constant(300), constant(1)),
+			"young": hourly(2*time.Hour, 2, constant(10), constant(300), constant(1)),
+		},
+	}
+	// gone was deleted after being listed, and young has only been around for two hours
+	fake.sandboxes[3].CreatedAt = now.Add(-2 * time.Hour)
+	server := httptest.NewServer(fake)
+	defer server.Close()
+	c := sandboxes.NewClient(client.NewClient(server.URL, "test-api-key"))
+	clock := func() time.Time { return now }
+
+	rec, err := ForSandboxes(context.Background(), c, models.ListSandboxesOptions{TemplateName: "base", LabelSelector: "team=ml"}, Options{Now: clock, Concurrency: 2})
+	if err != nil {
+		t.Fatalf("ForSandboxes failed: %v", err)
+	}
+	if rec.Target != "template=base selector=team=ml" || rec.Sandboxes != 3 || rec.Observed != 338*time.Hour {
+		t.Errorf("Unexpected recommendation %+v", rec)
+	}
+	if want := (Resources{CPUCount: 2, MemoryMB: 768, StorageGB: 2}); rec.Recommended != want {
+		t.Errorf("Recommended = %v, want %v", rec.Recommended, want)
+	}
+	if rec.Savings.MemoryMB != 3*(2048-768) {
+		t.Errorf("Savings = %v", rec.Savings)
+	}
+
+	// Any other failure fails the recommendation
+	fake.fail = "b"
+	if _, err := ForSandboxes(context.Background(), c, models.ListSandboxesOptions{}, Options{Now: clock}); err == nil || !strings.Contains(err.Error(), "sandbox b") {
+		t.Errorf("Expected the failure of b, got %v", err)
+	}
+}
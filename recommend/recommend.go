@@ -0,0 +1,304 @@
+// Package recommend suggests sandbox sizes from metrics history.
+//
+// Analyze sizes CPU and memory for a percentile of the observed usage and storage for the
+// peak, adds the headroom of a Policy and rounds to sizes the API accepts. Recommendations
+// carry a confidence that grows with the history observed; below MinConfidence resources are
+// only ever raised. ForSandbox and ForSandboxes fetch the history of one sandbox or of every
+// sandbox of a template or label selector, and Recommendation.Request is the sandbox's create
+// request patched with the recommended sizes, ready for the next run.
+package recommend
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/scalebox/scalebox-sdk-golang/metrics"
+	"github.com/scalebox/scalebox-sdk-golang/models"
+)
+
+// Recommendation defaults
+const (
+	DefaultWindow        = 7 * 24 * time.Hour
+	DefaultMinConfidence = 0.4
+	MemoryStepMB         = 256 // Memory is recommended in multiples of this
+	minPoints            = 30  // Fewer points than this lower the confidence
+)
+
+// Policy decides how much of the observed usage to provision for.
+// Storage is always sized for the peak, since running out of disk is not recoverable.
+type Policy struct {
+	Name             string  `json:"name"`
+	CPUPercentile    float64 `json:"cpu_percentile"`    // Percentile of CPU cores in use to size for
+	MemoryPercentile float64 `json:"memory_percentile"` // Percentile of memory use to size for
+	Headroom         float64 `json:"headroom"`          // Fraction added on top, e.g. 0.25 for 25%
+}
+
+// Built-in policies
+var (
+	Conservative = Policy{Name: "conservative", CPUPercentile: 99, MemoryPercentile: 100, Headroom: 0.5}
+	Balanced     = Policy{Name: "balanced", CPUPercentile: 95, MemoryPercentile: 99, Headroom: 0.25}
+	Aggressive   = Policy{Name: "aggressive", CPUPercentile: 90, MemoryPercentile: 95, Headroom: 0.1}
+)
+
+// Policies returns the built-in policies, most generous first
+func Policies() []Policy {
+	return []Policy{Conservative, Balanced, Aggressive}
+}
+
+// ParsePolicy returns the built-in policy with the given name
+func ParsePolicy(name string) (Policy, error) {
+	names := make([]string, 0, 3)
+	for _, p := range Policies() {
+		if p.Name == name {
+			return p, nil
+		}
+		names = append(names, p.Name)
+	}
+	return Policy{}, fmt.Errorf("unknown policy %q, want %s", name, strings.Join(names, ", "))
+}
+
+// Validate checks that the percentiles and headroom are in range
+func (p Policy) Validate() error {
+	var errs []models.FieldError
+	if p.CPUPercentile <= 0 || p.CPUPercentile > 100 {
+		errs = append(errs, models.FieldError{Field: "cpu_percentile", Message: "must be greater than 0 and at most 100"})
+	}
+	if p.MemoryPercentile <= 0 || p.MemoryPercentile > 100 {
+		errs = append(errs, models.FieldError{Field: "memory_percentile", Message: "must be greater than 0 and at most 100"})
+	}
+	if p.Headroom < 0 {
+		errs = append(errs, models.FieldError{Field: "headroom", Message: "must not be negative"})
+	}
+	if len(errs) > 0 {
+		return &models.ValidationError{Errors: errs}
+	}
+	return nil
+}
+
+// Options configures Analyze, ForSandbox and ForSandboxes
+type Options struct {
+	// Policy defaults to Balanced
+	Policy *Policy
+	// Window is the history to analyse and the history needed for full confidence,
+	// defaults to DefaultWindow
+	Window time.Duration
+	// MinConfidence is the confidence below which resources are raised but never reduced,
+	// defaults to DefaultMinConfidence
+	MinConfidence float64
+	// MaxPoints caps the points fetched per sandbox, see sandboxes.MetricsRangeOptions
+	MaxPoints int
+	// Concurrency is how many sandboxes ForSandboxes fetches at once, defaults to 4
+	Concurrency int
+	// Now returns the current time, defaults to time.Now
+	Now func() time.Time
+}
+
+// Validate checks that the options are consistent
+func (o Options) Validate() error {
+	if o.Policy != nil {
+		if err := o.Policy.Validate(); err != nil {
+			return err
+		}
+	}
+	var errs []models.FieldError
+	if o.Window < 0 {
+		errs = append(errs, models.FieldError{Field: "window", Message: "must not be negative"})
+	}
+	if o.MinConfidence < 0 || o.MinConfidence > 1 {
+		errs = append(errs, models.FieldError{Field: "min_confidence", Message: "must be between 0 and 1"})
+	}
+	if o.MaxPoints < 0 || o.Concurrency < 0 {
+		errs = append(errs, models.FieldError{Field: "max_points", Message: "limits must not be negative"})
+	}
+	if len(errs) > 0 {
+		return &models.ValidationError{Errors: errs}
+	}
+	return nil
+}
+
+func (o Options) withDefaults() Options {
+	if o.Policy == nil {
+		p := Balanced
+		o.Policy = &p
+	}
+	if o.Window == 0 {
+		o.Window = DefaultWindow
+	}
+	if o.MinConfidence == 0 {
+		o.MinConfidence = DefaultMinConfidence
+	}
+	if o.Concurrency == 0 {
+		o.Concurrency = 4
+	}
+	if o.Now == nil {
+		o.Now = time.Now
+	}
+	return o
+}
+
+// Resources is a sandbox size
+type Resources struct {
+	CPUCount  int `json:"cpu_count"`
+	MemoryMB  int `json:"memory_mb"`
+	StorageGB int `json:"storage_gb"`
+}
+
+func (r Resources) String() string {
+	return fmt.Sprintf("%d vCPU, %d MB memory, %d GB storage", r.CPUCount, r.MemoryMB, r.StorageGB)
+}
+
+// Usage is the usage a recommendation is sized for, before headroom
+type Usage struct {
+	CPUCores  float64 `json:"cpu_cores"`
+	MemoryMB  float64 `json:"memory_mb"`
+	StorageGB float64 `json:"storage_gb"`
+}
+
+// History is the metrics history of one sandbox
+type History struct {
+	Sandbox models.Sandbox
+	Series  metrics.Series
+}
+
+// Recommendation is a suggested size for a sandbox or a group of sandboxes
+type Recommendation struct {
+	Target      string        `json:"target"` // Sandbox ID or the selection analysed
+	Policy      Policy        `json:"policy"`
+	Sandboxes   int           `json:"sandboxes"`
+	Points      int           `json:"points"`
+	Observed    time.Duration `json:"observed"`   // History covered by the metrics, summed over sandboxes, excluding gaps
+	Confidence  float64       `json:"confidence"` // 0-1, see ConfidenceLevel
+	Usage       Usage         `json:"usage"`
+	Current     Resources     `json:"current"` // Largest size currently provisioned
+	Recommended Resources     `json:"recommended"`
+	// Savings is current minus recommended resources, summed over the sandboxes analysed;
+	// negative values are resources the sandboxes are short of
+	Savings Resources `json:"savings"`
+	// Capped is set when low confidence kept resources from being reduced
+	Capped bool `json:"capped,omitempty"`
+	// Request creates a sandbox like the most recently created one analysed, with the recommended
+	// size. SDK-managed metadata is left out and the name has no backend suffix; env var values
+	// are kept, and redacted only when the recommendation is encoded as JSON.
+	Request *models.CreateSandboxRequest `json:"request,omitempty"`
+}
+
+// ConfidenceLevel returns "high", "medium" or "low"
+func (r *Recommendation) ConfidenceLevel() string {
+	switch {
+	case r.Confidence >= 0.8:
+		return "high"
+	case r.Confidence >= 0.4:
+		return "medium"
+	}
+	return "low"
+}
+
+// Patch returns a copy of req with the recommended resources
+func (r *Recommendation) Patch(req models.CreateSandboxRequest) models.CreateSandboxRequest {
+	req.CPUCount = r.Recommended.CPUCount
+	req.MemoryMB = r.Recommended.MemoryMB
+	req.StorageGB = r.Recommended.StorageGB
+	return req
+}
+
+// Analyze recommends one size for the sandboxes of histories
+func Analyze(target string, histories []History, opts Options) (*Recommendation, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	opts = opts.withDefaults()
+	if len(histories) == 0 {
+		return nil, fmt.Errorf("recommend %s: no sandboxes to analyse", target)
+	}
+	policy := *opts.Policy
+	rec := &Recommendation{Target: target, Policy: policy, Sandboxes: len(histories)}
+
+	var cpu, mem []float64
+	var disk float64
+	var latest *models.Sandbox
+	for i := range histories {
+		h := &histories[i]
+		sb := &h.Sandbox
+		rec.Current.CPUCount = max(rec.Current.CPUCount, sb.CPUCount)
+		rec.Current.MemoryMB = max(rec.Current.MemoryMB, sb.MemoryMB)
+		rec.Current.StorageGB = max(rec.Current.StorageGB, sb.StorageGB)
+		if latest == nil || sb.CreatedAt.After(latest.CreatedAt) {
+			latest = sb
+		}
+		rec.Points += len(h.Series)
+		rec.Observed += observed(h.Series)
+		for _, p := range h.Series {
+			cores := p.CPUCount
+			if cores == 0 {
+				cores = sb.CPUCount
+			}
+			cpu = append(cpu, p.CPUUsedPct/100*float64(cores))
+			mem = append(mem, float64(p.MemUsed)/(1<<20))
+			disk = math.Max(disk, float64(p.DiskUsed)/(1<<30))
+		}
+	}
+	if rec.Points == 0 {
+		return nil, fmt.Errorf("recommend %s: no metrics in the last %s", target, opts.Window)
+	}
+	sort.Float64s(cpu)
+	sort.Float64s(mem)
+	rec.Usage = Usage{
+		CPUCores:  metrics.Percentile(cpu, policy.CPUPercentile),
+		MemoryMB:  metrics.Percentile(mem, policy.MemoryPercentile),
+		StorageGB: disk,
+	}
+	rec.Confidence = math.Min(1, rec.Observed.Seconds()/opts.Window.Seconds()) * math.Min(1, float64(rec.Points)/minPoints)
+
+	scale := 1 + policy.Headroom
+	rec.Recommended = Resources{
+		CPUCount:  max(int(math.Ceil(rec.Usage.CPUCores*scale-1e-9)), models.MinCPUCount),
+		MemoryMB:  max(roundUp(rec.Usage.MemoryMB*scale, MemoryStepMB), models.MinMemoryMB),
+		StorageGB: max(int(math.Ceil(rec.Usage.StorageGB*scale-1e-9)), models.MinStorageGB),
+	}
+	if rec.Confidence < opts.MinConfidence {
+		// Too little history to shrink safely; keep what is provisioned where that is more
+		capped := Resources{
+			CPUCount:  max(rec.Recommended.CPUCount, rec.Current.CPUCount),
+			MemoryMB:  max(rec.Recommended.MemoryMB, rec.Current.MemoryMB),
+			StorageGB: max(rec.Recommended.StorageGB, rec.Current.StorageGB),
+		}
+		rec.Capped = capped != rec.Recommended
+		rec.Recommended = capped
+	}
+	for i := range histories {
+		sb := &histories[i].Sandbox
+		rec.Savings.CPUCount += sb.CPUCount - rec.Recommended.CPUCount
+		rec.Savings.MemoryMB += sb.MemoryMB - rec.Recommended.MemoryMB
+		rec.Savings.StorageGB += sb.StorageGB - rec.Recommended.StorageGB
+	}
+	req := rec.Patch(latest.ToCreateRequest())
+	rec.Request = &req
+	return rec, nil
+}
+
+// observed returns the time the series covers, leaving out gaps of more than three
+// times its median sampling interval
+func observed(s metrics.Series) time.Duration {
+	if len(s) < 2 {
+		return 0
+	}
+	intervals := make([]float64, 0, len(s)-1)
+	for i := 1; i < len(s); i++ {
+		intervals = append(intervals, float64(s[i].Timestamp.Sub(s[i-1].Timestamp)))
+	}
+	sort.Float64s(intervals)
+	median := time.Duration(metrics.Percentile(intervals, 50))
+
+	total := s.End().Sub(s.Start())
+	for _, g := range s.Gaps(3 * median) {
+		total -= g.Duration()
+	}
+	return total
+}
+
+func roundUp(v float64, step int) int {
+	return int(math.Ceil(v/float64(step)-1e-9)) * step
+}
@@ -0,0 +1,190 @@
+package recommend
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/scalebox/scalebox-sdk-golang/metrics"
+	"github.com/scalebox/scalebox-sdk-golang/models"
+)
+
+var now = time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+// hourly returns a point every hour over the last span; cpu is the percentage of cpuCount
+// in use and memory and disk are in MB and GB
+func hourly(span time.Duration, cpuCount int, cpu, memMB, diskGB func(i int) float64) metrics.Series {
+	var s metrics.Series
+	for i := 0; time.Duration(i)*time.Hour <= span; i++ {
+		s = append(s, models.MetricsDataPoint{
+			Timestamp:  now.Add(-span + time.Duration(i)*time.Hour),
+			CPUCount:   cpuCount,
+			CPUUsedPct: cpu(i),
+			MemTotal:   8 << 30,
+			MemUsed:    int64(memMB(i) * (1 << 20)),
+			DiskTotal:  50 << 30,
+			DiskUsed:   int64(diskGB(i) * (1 << 30)),
+		})
+	}
+	return s
+}
+
+func constant(v float64) func(int) float64 { return func(int) float64 { return v } }
+
+func sandbox(id string, cpu, memMB, storageGB int) models.Sandbox {
+	return models.Sandbox{
+		SandboxID:  id,
+		Name:       "worker-x7k2",
+		TemplateID: "tpl-base",
+		CPUCount:   cpu,
+		MemoryMB:   memMB,
+		StorageGB:  storageGB,
+		CreatedAt:  now.Add(-30 * 24 * time.Hour),
+		Metadata:   map[string]string{"team": "ml", "scalebox.pool": "workers"},
+		EnvVars:    map[string]string{"API_TOKEN": "tok-123"},
+	}
+}
+
+func TestAnalyze(t *testing.T) {
+	// A week at 25% of 4 CPUs with rare bursts, about 1 GB of memory and 4 GB of disk
+	series := hourly(7*24*time.Hour, 4, func(i int) float64 {
+		if i%50 == 0 {
+			return 100
+		}
+		return 25
+	}, func(i int) float64 { return 1000 + float64(i%10) }, constant(4))
+	rec, err := Analyze("sbx-1", []History{{Sandbox: sandbox("sbx-1", 4, 8192, 50), Series: series}}, Options{})
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+	if rec.Policy.Name != "balanced" || rec.ConfidenceLevel() != "high" || rec.Capped {
+		t.Errorf("Unexpected recommendation %+v", rec)
+	}
+	// p95 of 1 core plus 25% rounds up to 2; p99 memory of ~1009 MB plus 25% rounds up to 1280 MB
+	want := Resources{CPUCount: 2, MemoryMB: 1280, StorageGB: 5}
+	if rec.Recommended != want {
+		t.Errorf("Recommended = %v, want %v", rec.Recommended, want)
+	}
+	if rec.Savings != (Resources{CPUCount: 2, MemoryMB: 8192 - 1280, StorageGB: 45}) {
+		t.Errorf("Savings = %v", rec.Savings)
+	}
+	if rec.Request == nil || rec.Request.Template != "tpl-base" || rec.Request.CPUCount != 2 || rec.Request.Metadata["team"] != "ml" {
+		t.Errorf("Unexpected patched request %+v", rec.Request)
+	}
+	// The request is ready for the next run: no SDK labels or backend name suffix, env vars kept
+	if rec.Request.Name != "worker" || rec.Request.EnvVars["API_TOKEN"] != "tok-123" || rec.Request.Metadata["scalebox.pool"] != "" {
+		t.Errorf("Expected a reusable request, got %+v", rec.Request)
+	}
+
+	// A conservative policy sizes for the bursts, which with headroom need more than is provisioned
+	rec, err = Analyze("sbx-1", []History{{Sandbox: sandbox("sbx-1", 4, 8192, 50), Series: series}}, Options{Policy: &Conservative})
+	if err != nil || rec.Recommended.CPUCount != 6 || rec.Recommended.MemoryMB != 1536 || rec.Recommended.StorageGB != 6 {
+		t.Errorf("Conservative recommendation = %v, %v", rec.Recommended, err)
+	}
+
+	var text, js bytes.Buffer
+	if err := rec.WriteText(&text); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"conservative policy", "high confidence (100%)", "cpu", "-2 vCPU (-50%)", "6656 MB (81%)"} {
+		if !strings.Contains(text.String(), want) {
+			t.Errorf("Text report lacks %q:\n%s", want, text.String())
+		}
+	}
+	if err := rec.WriteJSON(&js); err != nil {
+		t.Fatal(err)
+	}
+	var decoded Recommendation
+	if err := json.Unmarshal(js.Bytes(), &decoded); err != nil || decoded.Recommended != rec.Recommended || decoded.Request.MemoryMB != 1536 {
+		t.Errorf("JSON report does not round trip: %v\n%s", err, js.String())
+	}
+	if strings.Contains(js.String(), "tok-123") || rec.Request.EnvVars["API_TOKEN"] != "tok-123" {
+		t.Errorf("Expected env var values redacted in the JSON report only:\n%s", js.String())
+	}
+}
+
+func TestAnalyzeLowConfidence(t *testing.T) {
+	// A day of history is too little to shrink, but enough to grow an undersized sandbox
+	series := hourly(24*time.Hour, 2, constant(90), constant(3000), constant(1))
+	rec, err := Analyze("sbx-1", []History{{Sandbox: sandbox("sbx-1", 2, 8192, 10), Series: series}}, Options{})
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+	if rec.ConfidenceLevel() != "low" || !rec.Capped {
+		t.Errorf("Expected a capped low-confidence recommendation, got %+v", rec)
+	}
+	want := Resources{CPUCount: 3, MemoryMB: 8192, StorageGB: 10}
+	if rec.Recommended != want || rec.Savings.CPUCount != -1 {
+		t.Errorf("Recommended = %v, savings %v, want %v", rec.Recommended, rec.Savings, want)
+	}
+	var text bytes.Buffer
+	rec.WriteText(&text)
+	if !strings.Contains(text.String(), "only increases are recommended") {
+		t.Errorf("Text report lacks the capping note:\n%s", text.String())
+	}
+
+	// Several sandboxes pool their history
+	histories := []History{
+		{Sandbox: sandbox("a", 2, 8192, 10), Series: series},
+		{Sandbox: sandbox("b", 2, 4096, 10), Series: hourly(24*time.Hour, 2, constant(10), constant(500), constant(1))},
+		{Sandbox: sandbox("c", 2, 4096, 10), Series: hourly(5*24*time.Hour, 2, constant(10), constant(500), constant(1))},
+	}
+	rec, err = Analyze("team=ml", histories, Options{})
+	if err != nil || rec.Sandboxes != 3 || rec.Observed != 7*24*time.Hour || rec.ConfidenceLevel() != "high" {
+		t.Errorf("Unexpected pooled recommendation %+v, %v", rec, err)
+	}
+	if rec.Current != (Resources{CPUCount: 2, MemoryMB: 8192, StorageGB: 10}) {
+		t.Errorf("Current = %v", rec.Current)
+	}
+
+	if _, err := Analyze("none", nil, Options{}); err == nil {
+		t.Error("Expected an error without sandboxes")
+	}
+	if _, err := Analyze("empty", []History{{Sandbox: sandbox("x", 1, 512, 1)}}, Options{}); err == nil {
+		t.Error("Expected an error without metrics")
+	}
+}
+
+func TestObserved(t *testing.T) {
+	series := hourly(10*time.Hour, 1, constant(0), constant(0), constant(0))
+	// Drop four points, leaving a five hour gap
+	series = append(series[:3:3], series[7:]...)
+	if got := observed(series); got != 5*time.Hour {
+		t.Errorf("observed = %s, want 5h with the gap left out", got)
+	}
+	if got := observed(series[:1]); got != 0 {
+		t.Errorf("observed of one point = %s", got)
+	}
+}
+
+func TestPolicies(t *testing.T) {
+	for _, p := range Policies() {
+		got, err := ParsePolicy(p.Name)
+		if err != nil || got != p {
+			t.Errorf("ParsePolicy(%q) = %v, %v", p.Name, got, err)
+		}
+		if err := p.Validate(); err != nil {
+			t.Errorf("%s: %v", p.Name, err)
+		}
+	}
+	if _, err := ParsePolicy("yolo"); err == nil || !strings.Contains(err.Error(), "conservative, balanced, aggressive") {
+		t.Errorf("Expected an unknown policy error, got %v", err)
+	}
+
+	bad := Policy{CPUPercentile: 0, MemoryPercentile: 101, Headroom: -1}
+	err := Options{Policy: &bad, Window: -time.Hour}.Validate()
+	verr, ok := err.(*models.ValidationError)
+	if !ok {
+		t.Fatalf("Expected *models.ValidationError, got %v", err)
+	}
+	for _, field := range []string{"cpu_percentile", "memory_percentile", "headroom"} {
+		if !verr.HasField(field) {
+			t.Errorf("Expected an error for %s, got %v", field, verr)
+		}
+	}
+	if err := (Options{MinConfidence: 2}).Validate(); err == nil {
+		t.Error("Expected a confidence above 1 to be rejected")
+	}
+}
@@ -0,0 +1,65 @@
+package recommend
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// MarshalJSON encodes the recommendation with env var values and credentials in Request
+// redacted, so reports can be printed and stored safely
+func (r Recommendation) MarshalJSON() ([]byte, error) {
+	type recommendation Recommendation
+	out := recommendation(r)
+	if r.Request != nil {
+		redacted := r.Request.Redacted()
+		out.Request = &redacted
+	}
+	return json.Marshal(out)
+}
+
+// WriteJSON writes the recommendation as indented JSON, for machine consumption
+func (r *Recommendation) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+// WriteText writes the recommendation and its projected savings for humans
+func (r *Recommendation) WriteText(w io.Writer) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Recommendation for %s (%s policy): %d sandboxes, %d points over %s, %s confidence (%.0f%%)\n",
+		r.Target, r.Policy.Name, r.Sandboxes, r.Points, r.Observed.Round(time.Second), r.ConfidenceLevel(), r.Confidence*100)
+	fmt.Fprintf(&b, "  %-8s %10s %10s %12s %14s\n", "resource", "sized for", "current", "recommended", "savings")
+	rows := []struct {
+		name                 string
+		usage                string
+		current, recommended int
+		savings              int
+		unit                 string
+	}{
+		{"cpu", fmt.Sprintf("%.2f", r.Usage.CPUCores), r.Current.CPUCount, r.Recommended.CPUCount, r.Savings.CPUCount, "vCPU"},
+		{"memory", fmt.Sprintf("%.0f", r.Usage.MemoryMB), r.Current.MemoryMB, r.Recommended.MemoryMB, r.Savings.MemoryMB, "MB"},
+		{"storage", fmt.Sprintf("%.1f", r.Usage.StorageGB), r.Current.StorageGB, r.Recommended.StorageGB, r.Savings.StorageGB, "GB"},
+	}
+	for _, row := range rows {
+		// The sandboxes have provisioned the savings plus the recommended size of each
+		fmt.Fprintf(&b, "  %-8s %10s %10d %12d %9d %s%s\n", row.name, row.usage, row.current, row.recommended, row.savings, row.unit,
+			percentOf(row.savings+row.recommended*r.Sandboxes, row.savings))
+	}
+	if r.Capped {
+		fmt.Fprintln(&b, "note: confidence is too low to reduce resources; only increases are recommended")
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// percentOf formats part as a percentage of total, e.g. " (50%)"
+func percentOf(total, part int) string {
+	if total <= 0 {
+		return ""
+	}
+	return fmt.Sprintf(" (%.0f%%)", float64(part)/float64(total)*100)
+}
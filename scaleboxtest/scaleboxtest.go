@@ -0,0 +1,200 @@
+// Package scaleboxtest provides helpers for tests that create real sandboxes.
+//
+// NewClient reads SCALEBOX_BASE_URL and SCALEBOX_API_KEY and skips the test when either is unset,
+// so integration tests run only where they are configured. NewSandbox creates a sandbox, waits
+// for it to run and deletes it when the test finishes, even if the test fails or calls Fatal.
+// Every sandbox is labelled with the test name and a run ID, so sandboxes leaked by a killed
+// test process can be found later, e.g. with "scalebox reap --selector scalebox.test-run=<id>".
+//
+// Pointing SCALEBOX_BASE_URL at an httptest server lets the same helpers drive unit tests.
+package scaleboxtest
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/scalebox/scalebox-sdk-golang/api/sandboxes"
+	"github.com/scalebox/scalebox-sdk-golang/client"
+	"github.com/scalebox/scalebox-sdk-golang/labels"
+	"github.com/scalebox/scalebox-sdk-golang/models"
+)
+
+// Environment variables read by the helpers
+const (
+	EnvBaseURL     = "SCALEBOX_BASE_URL"
+	EnvAPIKey      = "SCALEBOX_API_KEY"
+	EnvRunID       = "SCALEBOX_TEST_RUN_ID"       // Optional: run ID shared by a CI job, generated when unset
+	EnvWaitTimeout = "SCALEBOX_TEST_WAIT_TIMEOUT" // Optional: how long NewSandbox waits for running, e.g. "5m"
+)
+
+// Metadata labels set on every sandbox created by NewSandbox
+const (
+	LabelTest  = "scalebox.test"     // Test name
+	LabelRunID = "scalebox.test-run" // Run ID
+)
+
+// Helper defaults
+const (
+	DefaultWaitTimeout    = 2 * time.Minute
+	DefaultCleanupTimeout = time.Minute
+)
+
+var (
+	runIDOnce sync.Once
+	runID     string
+)
+
+// RunID identifies this test process. It is read from SCALEBOX_TEST_RUN_ID when set,
+// otherwise generated once from the current time and random bytes.
+func RunID() string {
+	runIDOnce.Do(func() {
+		if runID = os.Getenv(EnvRunID); runID != "" {
+			return
+		}
+		b := make([]byte, 3)
+		rand.Read(b)
+		runID = time.Now().UTC().Format("20060102-150405") + "-" + hex.EncodeToString(b)
+	})
+	return runID
+}
+
+// NewClient returns a sandboxes client configured from SCALEBOX_BASE_URL and SCALEBOX_API_KEY,
+// and skips the test when either is unset
+func NewClient(t testing.TB) *sandboxes.Client {
+	t.Helper()
+	baseURL := os.Getenv(EnvBaseURL)
+	apiKey := os.Getenv(EnvAPIKey)
+	if baseURL == "" || apiKey == "" {
+		t.Skipf("skipping: %s and %s must be set", EnvBaseURL, EnvAPIKey)
+	}
+	return sandboxes.NewClient(client.NewClient(baseURL, apiKey))
+}
+
+// NewSandbox creates a sandbox with a client from NewClient; see NewSandboxWith
+func NewSandbox(t testing.TB, req models.CreateSandboxRequest) *models.Sandbox {
+	t.Helper()
+	return NewSandboxWith(t, NewClient(t), req)
+}
+
+// NewSandboxWith creates a sandbox labelled with the test name and run ID, registers its
+// deletion with t.Cleanup and waits until it is running. It fails the test if any step fails.
+func NewSandboxWith(t testing.TB, c *sandboxes.Client, req models.CreateSandboxRequest) *models.Sandbox {
+	t.Helper()
+	metadata := make(map[string]string, len(req.Metadata)+2)
+	for k, v := range req.Metadata {
+		metadata[k] = v
+	}
+	metadata[LabelTest] = labelValue(t.Name())
+	metadata[LabelRunID] = labelValue(RunID())
+	req.Metadata = metadata
+	if req.Name == "" {
+		req.Name = sandboxName(t.Name())
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), waitTimeout(t))
+	defer cancel()
+	sandbox, err := c.Create(ctx, req)
+	if err != nil {
+		t.Fatalf("create sandbox: %v", err)
+	}
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), DefaultCleanupTimeout)
+		defer cancel()
+		if _, err := c.Delete(ctx, sandbox.SandboxID, nil); err != nil && !client.IsNotFound(err) {
+			t.Errorf("delete sandbox %s: %v (find leaked sandboxes with %s=%s)", sandbox.SandboxID, err, LabelRunID, RunID())
+		}
+	})
+
+	if _, err := c.WaitForStatus(ctx, sandbox.SandboxID, models.StatusRunning, 0); err != nil {
+		t.Fatalf("wait for sandbox %s to run: %v", sandbox.SandboxID, err)
+	}
+	running, err := c.Get(ctx, sandbox.SandboxID)
+	if err != nil {
+		t.Fatalf("get sandbox %s: %v", sandbox.SandboxID, err)
+	}
+	return running
+}
+
+// RequireStatus fails the test unless the sandbox is in one of the given statuses
+func RequireStatus(t testing.TB, c *sandboxes.Client, sandboxID string, statuses ...string) *models.SandboxStatus {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultCleanupTimeout)
+	defer cancel()
+	current, err := c.GetStatus(ctx, sandboxID)
+	if err != nil {
+		t.Fatalf("get status of sandbox %s: %v", sandboxID, err)
+	}
+	for _, status := range statuses {
+		if current.Status == status {
+			return current
+		}
+	}
+	t.Fatalf("sandbox %s has status %q, want %s", sandboxID, current.Status, strings.Join(statuses, " or "))
+	return nil
+}
+
+// RequireEventualStatus polls until the sandbox reaches status, failing the test on timeout
+// or if the sandbox enters another terminal status first
+func RequireEventualStatus(t testing.TB, c *sandboxes.Client, sandboxID, status string, timeout time.Duration) *models.SandboxStatus {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	current, err := c.WaitForStatus(ctx, sandboxID, status, 0)
+	if err != nil {
+		t.Fatalf("wait for sandbox %s to be %s: %v", sandboxID, status, err)
+	}
+	return current
+}
+
+func waitTimeout(t testing.TB) time.Duration {
+	value := os.Getenv(EnvWaitTimeout)
+	if value == "" {
+		return DefaultWaitTimeout
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		t.Fatalf("invalid %s %q: want a positive duration such as 5m", EnvWaitTimeout, value)
+	}
+	return d
+}
+
+// labelValue replaces characters that selectors do not accept, e.g. the spaces in subtest names
+func labelValue(s string) string {
+	b := []byte(s)
+	for i := range b {
+		if labels.ValidateValue(string(b[i])) != nil {
+			b[i] = '_'
+		}
+	}
+	if len(b) > labels.MaxValueLength {
+		b = b[:labels.MaxValueLength]
+	}
+	return string(b)
+}
+
+// sandboxName derives a readable sandbox name from a test name
+func sandboxName(test string) string {
+	const maxLength = 48
+	var b strings.Builder
+	b.WriteString("test-")
+	dash := true
+	for _, r := range strings.ToLower(test) {
+		if r >= 'a' && r <= 'z' || r >= '0' && r <= '9' {
+			b.WriteRune(r)
+			dash = false
+		} else if !dash {
+			b.WriteByte('-')
+			dash = true
+		}
+		if b.Len() >= maxLength {
+			break
+		}
+	}
+	return strings.TrimSuffix(b.String(), "-")
+}
@@ -0,0 +1,120 @@
+package scaleboxtest
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/scalebox/scalebox-sdk-golang/labels"
+	"github.com/scalebox/scalebox-sdk-golang/models"
+)
+
+// fakeAPI keeps created sandboxes in memory; they are running as soon as they exist
+type fakeAPI struct {
+	mu        sync.Mutex
+	sandboxes map[string]models.Sandbox
+	deleted   []string
+}
+
+func (f *fakeAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	w.Header().Set("Content-Type", "application/json")
+	id := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/v1/sandboxes/"), "/status")
+
+	switch {
+	case r.Method == "POST" && r.URL.Path == "/v1/sandboxes":
+		var req models.CreateSandboxRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		sb := models.Sandbox{SandboxID: "sbx-" + req.Name, Name: req.Name, Status: models.StatusRunning, Metadata: req.Metadata}
+		f.sandboxes[sb.SandboxID] = sb
+		json.NewEncoder(w).Encode(sb)
+	case r.Method == "DELETE":
+		if _, ok := f.sandboxes[id]; !ok {
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(map[string]string{"error": "not found"})
+			return
+		}
+		delete(f.sandboxes, id)
+		f.deleted = append(f.deleted, id)
+		json.NewEncoder(w).Encode(models.DeletionResponse{SandboxID: id})
+	default:
+		sb, ok := f.sandboxes[id]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(map[string]string{"error": "not found"})
+			return
+		}
+		if strings.HasSuffix(r.URL.Path, "/status") {
+			json.NewEncoder(w).Encode(models.SandboxStatus{SandboxID: id, Status: sb.Status})
+			return
+		}
+		json.NewEncoder(w).Encode(sb)
+	}
+}
+
+func TestNewSandbox(t *testing.T) {
+	fake := &fakeAPI{sandboxes: make(map[string]models.Sandbox)}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+	t.Setenv(EnvBaseURL, server.URL)
+	t.Setenv(EnvAPIKey, "test-api-key")
+
+	var created *models.Sandbox
+	t.Run("creates a running sandbox", func(t *testing.T) {
+		created = NewSandbox(t, models.CreateSandboxRequest{Metadata: map[string]string{"team": "sdk"}})
+		if created.Name != "test-testnewsandbox-creates-a-running-sandbox" {
+			t.Errorf("Unexpected name %q", created.Name)
+		}
+		selector, err := labels.Parse(LabelRunID + "=" + RunID() + "," + LabelTest + "=" + created.Metadata[LabelTest] + ",team=sdk")
+		if err != nil {
+			t.Fatalf("Labels do not form a valid selector: %v", err)
+		}
+		if !selector.Matches(created.Metadata) || created.Metadata[LabelTest] != "TestNewSandbox/creates_a_running_sandbox" {
+			t.Errorf("Unexpected metadata %v", created.Metadata)
+		}
+		RequireStatus(t, NewClient(t), created.SandboxID, models.StatusPaused, models.StatusRunning)
+		RequireEventualStatus(t, NewClient(t), created.SandboxID, models.StatusRunning, DefaultWaitTimeout)
+		if len(fake.deleted) != 0 {
+			t.Error("Expected the sandbox to live until the test ends")
+		}
+	})
+	if len(fake.deleted) != 1 || fake.deleted[0] != created.SandboxID {
+		t.Errorf("Expected the sandbox to be deleted on cleanup, got %v", fake.deleted)
+	}
+
+	// Sandboxes deleted by the test itself do not fail cleanup
+	t.Run("deleted by the test", func(t *testing.T) {
+		c := NewClient(t)
+		sb := NewSandboxWith(t, c, models.CreateSandboxRequest{Name: "explicit"})
+		if _, err := c.Delete(context.Background(), sb.SandboxID, nil); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	reached := false
+	t.Run("skips when unconfigured", func(t *testing.T) {
+		t.Setenv(EnvAPIKey, "")
+		NewClient(t)
+		reached = true
+	})
+	if reached {
+		t.Error("Expected NewClient to skip without an API key")
+	}
+}
+
+func TestRunID(t *testing.T) {
+	if RunID() == "" || RunID() != RunID() {
+		t.Errorf("Expected a stable run ID, got %q", RunID())
+	}
+	if err := labels.ValidateValue(labelValue("TestX/sub test#1")); err != nil {
+		t.Errorf("Expected a valid label value: %v", err)
+	}
+	if name := sandboxName("TestVeryLongName/with many subtests and words in it"); len(name) > 48 || strings.HasSuffix(name, "-") {
+		t.Errorf("Unexpected sandbox name %q", name)
+	}
+}
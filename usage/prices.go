@@ -0,0 +1,66 @@
+package usage
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/scalebox/scalebox-sdk-golang/models"
+)
+
+// PriceSheet prices sandbox resources. Zero prices are free, so a zero sheet reports usage only.
+type PriceSheet struct {
+	Currency     string  `json:"currency,omitempty"` // Shown next to costs, e.g. "USD"
+	VCPUHour     float64 `json:"vcpu_hour"`          // Per vCPU per running hour
+	MemoryGBHour float64 `json:"memory_gb_hour"`     // Per GB of memory per running hour
+	StorageGBDay float64 `json:"storage_gb_day"`     // Per GB of storage per day, running or paused
+}
+
+// Validate checks that no price is negative
+func (p PriceSheet) Validate() error {
+	var errs []models.FieldError
+	for _, price := range []struct {
+		field string
+		value float64
+	}{{"vcpu_hour", p.VCPUHour}, {"memory_gb_hour", p.MemoryGBHour}, {"storage_gb_day", p.StorageGBDay}} {
+		if price.value < 0 {
+			errs = append(errs, models.FieldError{Field: price.field, Message: fmt.Sprintf("must not be negative, got %g", price.value)})
+		}
+	}
+	if len(errs) > 0 {
+		return &models.ValidationError{Errors: errs}
+	}
+	return nil
+}
+
+// Cost prices the given usage
+func (p PriceSheet) Cost(vcpuHours, memoryGBHours, storageGBDays float64) float64 {
+	return vcpuHours*p.VCPUHour + memoryGBHours*p.MemoryGBHour + storageGBDays*p.StorageGBDay
+}
+
+// ReadPriceSheet decodes a JSON price sheet such as
+//
+//	{"currency": "USD", "vcpu_hour": 0.04, "memory_gb_hour": 0.005, "storage_gb_day": 0.003}
+func ReadPriceSheet(r io.Reader) (PriceSheet, error) {
+	var p PriceSheet
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&p); err != nil {
+		return PriceSheet{}, fmt.Errorf("price sheet: %w", err)
+	}
+	if err := p.Validate(); err != nil {
+		return PriceSheet{}, fmt.Errorf("price sheet: %w", err)
+	}
+	return p, nil
+}
+
+// LoadPriceSheet reads a JSON price sheet from a file, see ReadPriceSheet
+func LoadPriceSheet(path string) (PriceSheet, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return PriceSheet{}, err
+	}
+	defer f.Close()
+	return ReadPriceSheet(f)
+}
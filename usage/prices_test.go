@@ -0,0 +1,44 @@
+package usage
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestReadPriceSheet(t *testing.T) {
+	p, err := ReadPriceSheet(strings.NewReader(`{"currency": "EUR", "vcpu_hour": 0.04, "memory_gb_hour": 0.005, "storage_gb_day": 0.003}`))
+	if err != nil {
+		t.Fatalf("ReadPriceSheet failed: %v", err)
+	}
+	if p.Currency != "EUR" || p.VCPUHour != 0.04 || p.MemoryGBHour != 0.005 || p.StorageGBDay != 0.003 {
+		t.Errorf("Unexpected price sheet %+v", p)
+	}
+	if got := p.Cost(10, 100, 1000); !near(got, 0.4+0.5+3) {
+		t.Errorf("Cost = %f", got)
+	}
+
+	for _, bad := range []string{
+		`{"vcpu_hours": 0.04}`,
+		`{"vcpu_hour": -0.04}`,
+		`{"vcpu_hour": "cheap"}`,
+	} {
+		if _, err := ReadPriceSheet(strings.NewReader(bad)); err == nil || !strings.HasPrefix(err.Error(), "price sheet: ") {
+			t.Errorf("Expected %s to be rejected, got %v", bad, err)
+		}
+	}
+}
+
+func TestLoadPriceSheet(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "prices.json")
+	if err := os.WriteFile(path, []byte(`{"vcpu_hour": 1}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if p, err := LoadPriceSheet(path); err != nil || p.VCPUHour != 1 {
+		t.Errorf("LoadPriceSheet = %+v, %v", p, err)
+	}
+	if _, err := LoadPriceSheet(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Error("Expected a missing file to fail")
+	}
+}
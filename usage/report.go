@@ -0,0 +1,101 @@
+package usage
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/scalebox/scalebox-sdk-golang/models"
+)
+
+// Report is the usage of sandboxes during a window, by group
+type Report struct {
+	From    time.Time  `json:"from"`
+	To      time.Time  `json:"to"`
+	GroupBy string     `json:"group_by"`
+	Prices  PriceSheet `json:"prices"`
+	Rows    []Row      `json:"rows"` // Most expensive first
+	Total   Row        `json:"total"`
+}
+
+// Row is the usage of one group
+type Row struct {
+	Group         string  `json:"group"`
+	Sandboxes     int     `json:"sandboxes"`
+	RunningHours  float64 `json:"running_hours"`
+	PausedHours   float64 `json:"paused_hours"`
+	VCPUHours     float64 `json:"vcpu_hours"`
+	MemoryGBHours float64 `json:"memory_gb_hours"`
+	StorageGBDays float64 `json:"storage_gb_days"` // Storage is kept while running and paused
+	Cost          float64 `json:"cost"`
+}
+
+// add adds share of the lifetime usage of sb to the row
+func (r *Row) add(sb *models.Sandbox, share float64, now time.Time, prices PriceSheet) {
+	running := sb.RunningDuration(now).Hours() * share
+	paused := sb.PausedDuration(now).Hours() * share
+	cpu, memoryMB, storageGB := Resources(sb)
+	vcpu := float64(cpu) * running
+	memory := float64(memoryMB) / 1024 * running
+	storage := float64(storageGB) * (running + paused) / 24
+
+	r.Sandboxes++
+	r.RunningHours += running
+	r.PausedHours += paused
+	r.VCPUHours += vcpu
+	r.MemoryGBHours += memory
+	r.StorageGBDays += storage
+	r.Cost += prices.Cost(vcpu, memory, storage)
+}
+
+var csvHeader = []string{"group", "sandboxes", "running_hours", "paused_hours", "vcpu_hours", "memory_gb_hours", "storage_gb_days", "cost"}
+
+func (r *Row) fields() []string {
+	f := func(v float64) string { return strconv.FormatFloat(v, 'f', 2, 64) }
+	return []string{r.Group, strconv.Itoa(r.Sandboxes), f(r.RunningHours), f(r.PausedHours), f(r.VCPUHours), f(r.MemoryGBHours), f(r.StorageGBDays), f(r.Cost)}
+}
+
+// WriteJSON writes the report as indented JSON, for machine consumption
+func (r *Report) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+// WriteCSV writes a header, a record per group and a final total record, for spreadsheets
+func (r *Report) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	cw.Write(csvHeader)
+	for i := range r.Rows {
+		cw.Write(r.Rows[i].fields())
+	}
+	cw.Write(r.Total.fields())
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteTable writes the report as an aligned table for humans
+func (r *Report) WriteTable(w io.Writer) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Usage from %s to %s by %s\n", r.From.Format(time.RFC3339), r.To.Format(time.RFC3339), r.GroupBy)
+	tw := tabwriter.NewWriter(&b, 0, 0, 2, ' ', tabwriter.AlignRight)
+	header := append([]string(nil), csvHeader...)
+	if r.Prices.Currency != "" {
+		header[len(header)-1] = "cost (" + r.Prices.Currency + ")"
+	}
+	fmt.Fprintln(tw, strings.Join(header, "\t")+"\t")
+	for i := range r.Rows {
+		fmt.Fprintln(tw, strings.Join(r.Rows[i].fields(), "\t")+"\t")
+	}
+	fmt.Fprintln(tw, strings.Join(r.Total.fields(), "\t")+"\t")
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
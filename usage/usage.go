@@ -0,0 +1,216 @@
+// Package usage reports sandbox time, resources and cost over a time window.
+//
+// Aggregate groups sandboxes by project, owner, template or a metadata label and sums their
+// running and paused time, vCPU-hours, memory GB-hours and storage GB-days, priced with a
+// PriceSheet. Sandboxes only report lifetime totals, so the usage of a sandbox that straddles
+// an edge of the window is prorated by the share of its lifetime inside the window.
+// Collect lists the sandboxes, terminated ones included, and aggregates them.
+package usage
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/scalebox/scalebox-sdk-golang/api/sandboxes"
+	"github.com/scalebox/scalebox-sdk-golang/labels"
+	"github.com/scalebox/scalebox-sdk-golang/models"
+)
+
+// Built-in groupings; a metadata label is grouped on with "label:<key>"
+const (
+	GroupByProject  = "project"
+	GroupByOwner    = "owner"
+	GroupByTemplate = "template"
+	labelPrefix     = "label:"
+)
+
+// NoGroup is the group of sandboxes without a value for the grouping, e.g. without the label
+const NoGroup = "(none)"
+
+// Options configures Aggregate and Collect
+type Options struct {
+	From, To time.Time // Window of the report; To defaults to now
+	// GroupBy is GroupByProject, GroupByOwner, GroupByTemplate or "label:<key>", defaults to GroupByProject
+	GroupBy string
+	Prices  PriceSheet
+	// List narrows the sandboxes Collect lists, e.g. by LabelSelector or ProjectID.
+	// Terminated sandboxes are always included.
+	List models.ListSandboxesOptions
+	// Now returns the current time, defaults to time.Now
+	Now func() time.Time
+}
+
+// Validate checks the window, grouping and prices
+func (o Options) Validate() error {
+	var errs []models.FieldError
+	if o.From.IsZero() {
+		errs = append(errs, models.FieldError{Field: "from", Message: "is required"})
+	}
+	if !o.To.IsZero() && !o.To.After(o.From) {
+		errs = append(errs, models.FieldError{Field: "to", Message: "must be after from"})
+	}
+	switch {
+	case o.GroupBy == "", o.GroupBy == GroupByProject, o.GroupBy == GroupByOwner, o.GroupBy == GroupByTemplate:
+	case strings.HasPrefix(o.GroupBy, labelPrefix):
+		if err := labels.ValidateKey(strings.TrimPrefix(o.GroupBy, labelPrefix)); err != nil {
+			errs = append(errs, models.FieldError{Field: "group_by", Message: err.Error()})
+		}
+	default:
+		errs = append(errs, models.FieldError{Field: "group_by", Message: fmt.Sprintf("must be project, owner, template or label:<key>, got %q", o.GroupBy)})
+	}
+	if err := o.Prices.Validate(); err != nil {
+		errs = append(errs, err.(*models.ValidationError).Errors...)
+	}
+	if len(errs) > 0 {
+		return &models.ValidationError{Errors: errs}
+	}
+	return nil
+}
+
+func (o Options) withDefaults() Options {
+	if o.Now == nil {
+		o.Now = time.Now
+	}
+	if o.To.IsZero() {
+		o.To = o.Now()
+	}
+	if o.GroupBy == "" {
+		o.GroupBy = GroupByProject
+	}
+	return o
+}
+
+// Collect lists the sandboxes that existed during the window and aggregates their usage
+func Collect(ctx context.Context, c *sandboxes.Client, opts Options) (*Report, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	opts = opts.withDefaults()
+	list := opts.List
+	list.IncludeTerminated = true
+	if list.CreatedBefore == nil || list.CreatedBefore.After(opts.To) {
+		list.CreatedBefore = &opts.To
+	}
+	found, err := c.ListAll(ctx, list)
+	if err != nil {
+		return nil, err
+	}
+	return Aggregate(found, opts)
+}
+
+// Aggregate sums the usage of the sandboxes during the window by group.
+// Sandboxes that did not exist during the window are left out.
+func Aggregate(list []models.Sandbox, opts Options) (*Report, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	opts = opts.withDefaults()
+	now := opts.Now()
+	report := &Report{From: opts.From, To: opts.To, GroupBy: opts.GroupBy, Prices: opts.Prices, Rows: []Row{}}
+	rows := make(map[string]*Row)
+	for i := range list {
+		sb := &list[i]
+		share := windowShare(sb, opts.From, opts.To, now)
+		if share <= 0 {
+			continue
+		}
+		group := groupOf(sb, opts.GroupBy)
+		row := rows[group]
+		if row == nil {
+			row = &Row{Group: group}
+			rows[group] = row
+		}
+		row.add(sb, share, now, opts.Prices)
+		report.Total.add(sb, share, now, opts.Prices)
+	}
+	for _, row := range rows {
+		report.Rows = append(report.Rows, *row)
+	}
+	// Most expensive first, then by the time used
+	sort.Slice(report.Rows, func(i, j int) bool {
+		a, b := report.Rows[i], report.Rows[j]
+		if a.Cost != b.Cost {
+			return a.Cost > b.Cost
+		}
+		if a.VCPUHours != b.VCPUHours {
+			return a.VCPUHours > b.VCPUHours
+		}
+		return a.Group < b.Group
+	})
+	report.Total.Group = "total"
+	return report, nil
+}
+
+// windowShare returns the share of the sandbox's lifetime that falls inside [from, to)
+func windowShare(sb *models.Sandbox, from, to, now time.Time) float64 {
+	start, end := sb.CreatedAt, now
+	if sb.EndedAt != nil {
+		end = *sb.EndedAt
+	}
+	if !end.After(start) {
+		// No measurable lifetime; count the sandbox where it was created
+		if !start.Before(from) && start.Before(to) {
+			return 1
+		}
+		return 0
+	}
+	lo, hi := maxTime(start, from), minTime(end, to)
+	if !hi.After(lo) {
+		return 0
+	}
+	return hi.Sub(lo).Seconds() / end.Sub(start).Seconds()
+}
+
+// groupOf returns the group of a sandbox
+func groupOf(sb *models.Sandbox, groupBy string) string {
+	var v string
+	switch groupBy {
+	case GroupByProject:
+		v = sb.ProjectID
+		if sb.ProjectName != nil && *sb.ProjectName != "" {
+			v = *sb.ProjectName
+		}
+	case GroupByOwner:
+		v = sb.OwnerUserID
+		if sb.Owner != nil && sb.Owner.Username != "" {
+			v = sb.Owner.Username
+		}
+	case GroupByTemplate:
+		v = sb.TemplateID
+		if sb.TemplateName != nil && *sb.TemplateName != "" {
+			v = *sb.TemplateName
+		}
+	default:
+		v = sb.Metadata[strings.TrimPrefix(groupBy, labelPrefix)]
+	}
+	if v == "" {
+		return NoGroup
+	}
+	return v
+}
+
+// Resources returns the CPU count, memory in MB and storage in GB of a sandbox.
+// Resources, when reported, takes precedence over CPUCount, MemoryMB and StorageGB.
+func Resources(sb *models.Sandbox) (cpu, memoryMB, storageGB int) {
+	if r := sb.Resources; r != nil {
+		return r.CPU, r.Memory, r.Storage
+	}
+	return sb.CPUCount, sb.MemoryMB, sb.StorageGB
+}
+
+func minTime(a, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+	return b
+}
+
+func maxTime(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,196 @@
+package usage
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/scalebox/scalebox-sdk-golang/api/sandboxes"
+	"github.com/scalebox/scalebox-sdk-golang/client"
+	"github.com/scalebox/scalebox-sdk-golang/models"
+)
+
+var (
+	may  = time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	june = time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	now  = june.Add(12 * time.Hour)
+)
+
+var prices = PriceSheet{Currency: "USD", VCPUHour: 0.04, MemoryGBHour: 0.01, StorageGBDay: 0.1}
+
+// ended returns a terminated sandbox that lived from created for lifetime, running for the given hours
+func ended(id, project string, created time.Time, lifetime time.Duration, runningHours, pausedHours int) models.Sandbox {
+	end := created.Add(lifetime)
+	return models.Sandbox{
+		SandboxID:           id,
+		ProjectID:           project,
+		OwnerUserID:         "u-" + project,
+		TemplateID:          "tpl-base",
+		Status:              models.StatusTerminated,
+		CPUCount:            2,
+		MemoryMB:            4096,
+		StorageGB:           10,
+		CreatedAt:           created,
+		EndedAt:             &end,
+		TotalRunningSeconds: runningHours * 3600,
+		TotalPausedSeconds:  pausedHours * 3600,
+		Metadata:            map[string]string{"team": project},
+	}
+}
+
+func fleet() []models.Sandbox {
+	ml := "Machine Learning"
+	actual := int64(10 * 3600)
+	started := now.Add(-6 * time.Hour)
+	sbs := []models.Sandbox{
+		ended("a", "prj-ml", may.Add(24*time.Hour), 24*time.Hour, 20, 4),
+		ended("b", "prj-ml", may.Add(48*time.Hour), 10*time.Hour, 10, 0),
+		ended("c", "prj-web", may.Add(72*time.Hour), 5*time.Hour, 5, 0),
+		// Half of this sandbox's life was in April
+		ended("d", "prj-web", may.Add(-10*time.Hour), 20*time.Hour, 20, 0),
+		// Entirely before the window
+		ended("e", "prj-web", may.Add(-72*time.Hour), time.Hour, 1, 0),
+		// Still running; it reports its exact running time
+		{SandboxID: "f", ProjectID: "prj-ml", OwnerUserID: "u-9", Status: models.StatusRunning, CreatedAt: june.Add(-4 * time.Hour), StartedAt: &started, ActualTotalRunningSeconds: &actual,
+			Resources: &models.Resources{CPU: 1, Memory: 1024, Storage: 5}},
+	}
+	sbs[0].ProjectName = &ml
+	sbs[1].ProjectName = &ml
+	sbs[1].Owner = &models.Owner{Username: "alice"}
+	return sbs
+}
+
+func near(a, b float64) bool { return math.Abs(a-b) < 1e-6 }
+
+func TestAggregate(t *testing.T) {
+	report, err := Aggregate(fleet(), Options{From: may, To: june, Prices: prices, Now: func() time.Time { return now }})
+	if err != nil {
+		t.Fatalf("Aggregate failed: %v", err)
+	}
+	if report.GroupBy != GroupByProject || len(report.Rows) != 3 {
+		t.Fatalf("Unexpected report %+v", report)
+	}
+	// a and b share the project name; f only has the project ID
+	ml, web := report.Rows[0], report.Rows[1]
+	if ml.Group != "Machine Learning" || ml.Sandboxes != 2 || !near(ml.RunningHours, 30) || !near(ml.PausedHours, 4) {
+		t.Errorf("Unexpected row %+v", ml)
+	}
+	if !near(ml.VCPUHours, 60) || !near(ml.MemoryGBHours, 120) || !near(ml.StorageGBDays, 340.0/24) {
+		t.Errorf("Unexpected resource usage %+v", ml)
+	}
+	if want := 60*0.04 + 120*0.01 + 340.0/24*0.1; !near(ml.Cost, want) {
+		t.Errorf("Cost = %f, want %f", ml.Cost, want)
+	}
+	// c, and half of d
+	if web.Group != "prj-web" || web.Sandboxes != 2 || !near(web.RunningHours, 15) {
+		t.Errorf("Unexpected row %+v", web)
+	}
+	// f lived 16 hours, of which 4 were in May, and uses Resources
+	f := report.Rows[2]
+	if f.Group != "prj-ml" || !near(f.RunningHours, 2.5) || !near(f.VCPUHours, 2.5) || !near(f.MemoryGBHours, 2.5) {
+		t.Errorf("Unexpected row %+v", f)
+	}
+	if report.Total.Group != "total" || report.Total.Sandboxes != 5 || !near(report.Total.RunningHours, 47.5) {
+		t.Errorf("Unexpected total %+v", report.Total)
+	}
+
+	for groupBy, want := range map[string]string{
+		GroupByOwner:    "alice,u-9,u-prj-ml,u-prj-web",
+		GroupByTemplate: "(none),tpl-base",
+		"label:team":    "(none),prj-ml,prj-web",
+	} {
+		report, err := Aggregate(fleet(), Options{From: may, To: june, GroupBy: groupBy, Now: func() time.Time { return now }})
+		if err != nil {
+			t.Fatalf("Aggregate by %s failed: %v", groupBy, err)
+		}
+		var groups []string
+		for _, row := range report.Rows {
+			groups = append(groups, row.Group)
+		}
+		// Without prices, rows are ordered by vCPU-hours; compare as a set
+		sort.Strings(groups)
+		if got := strings.Join(groups, ","); got != want {
+			t.Errorf("Groups by %s = %s, want %s", groupBy, got, want)
+		}
+	}
+}
+
+func TestReportOutputs(t *testing.T) {
+	report, err := Aggregate(fleet(), Options{From: may, To: june, Prices: prices, Now: func() time.Time { return now }})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var table, csvOut, js bytes.Buffer
+	if err := report.WriteTable(&table); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(table.String(), "by project") || !strings.Contains(table.String(), "cost (USD)") || !strings.Contains(table.String(), "Machine Learning") {
+		t.Errorf("Unexpected table:\n%s", table.String())
+	}
+
+	if err := report.WriteCSV(&csvOut); err != nil {
+		t.Fatal(err)
+	}
+	records, err := csv.NewReader(&csvOut).ReadAll()
+	if err != nil || len(records) != 5 || records[0][0] != "group" || records[4][0] != "total" || records[1][2] != "30.00" {
+		t.Errorf("Unexpected CSV %v, %v", records, err)
+	}
+
+	if err := report.WriteJSON(&js); err != nil {
+		t.Fatal(err)
+	}
+	var decoded Report
+	if err := json.Unmarshal(js.Bytes(), &decoded); err != nil || len(decoded.Rows) != 3 || decoded.Prices.Currency != "USD" {
+		t.Errorf("JSON report does not round trip: %v\n%s", err, js.String())
+	}
+}
+
+func TestCollect(t *testing.T) {
+	var query string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.RawQuery
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(models.SandboxListResponse{Sandboxes: fleet()})
+	}))
+	defer server.Close()
+	c := sandboxes.NewClient(client.NewClient(server.URL, "test-api-key"))
+
+	report, err := Collect(context.Background(), c, Options{From: may, To: june, List: models.ListSandboxesOptions{ProjectID: "prj-ml"}, Now: func() time.Time { return now }})
+	if err != nil {
+		t.Fatalf("Collect failed: %v", err)
+	}
+	if report.Total.Sandboxes != 5 {
+		t.Errorf("Unexpected total %+v", report.Total)
+	}
+	if !strings.Contains(query, "project_id=prj-ml") || !strings.Contains(query, "include_terminated=true") {
+		t.Errorf("Unexpected list query %s", query)
+	}
+}
+
+func TestOptionsValidate(t *testing.T) {
+	err := Options{To: may, GroupBy: "colour", Prices: PriceSheet{VCPUHour: -1}}.Validate()
+	verr, ok := err.(*models.ValidationError)
+	if !ok {
+		t.Fatalf("Expected *models.ValidationError, got %v", err)
+	}
+	for _, field := range []string{"from", "group_by", "vcpu_hour"} {
+		if !verr.HasField(field) {
+			t.Errorf("Expected an error for %s, got %v", field, verr)
+		}
+	}
+	if err := (Options{From: june, To: may}).Validate(); err == nil {
+		t.Error("Expected an empty window to be rejected")
+	}
+	if err := (Options{From: may, GroupBy: "label:bad key"}).Validate(); err == nil {
+		t.Error("Expected an invalid label key to be rejected")
+	}
+}